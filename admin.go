@@ -0,0 +1,224 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AdminConfig is used to configure the admin server
+type AdminConfig struct {
+	MetricsOptions []MetricsOption
+	ProbeOptions   []ProbeOption
+	Pprof          bool
+	ErrorHandler   func(err error)
+	ServerGroup    *ServerGroup
+	BindAddress    string
+}
+
+// AdminOption allows to override admin config
+type AdminOption func(*AdminConfig)
+
+// WithAdminMetricsOptions configures the /metrics endpoint of the admin server, for example with WithMetricsBasicAuth;
+// the basic auth credentials and bearer tokens protect the /debug endpoints as well
+func WithAdminMetricsOptions(opts ...MetricsOption) AdminOption {
+	return func(c *AdminConfig) {
+		c.MetricsOptions = append(c.MetricsOptions, opts...)
+	}
+}
+
+// WithAdminProbeOptions configures the probe endpoints of the admin server, for example with WithKubernetesProbePaths
+func WithAdminProbeOptions(opts ...ProbeOption) AdminOption {
+	return func(c *AdminConfig) {
+		c.ProbeOptions = append(c.ProbeOptions, opts...)
+	}
+}
+
+// WithAdminPprof sets whether InitAdminServer serves the /debug/pprof endpoints
+// default is false
+func WithAdminPprof(enabled bool) AdminOption {
+	return func(c *AdminConfig) {
+		c.Pprof = enabled
+	}
+}
+
+// WithAdminErrorHandler passes errors binding or serving the admin listener to handler, for example to pick another
+// port, instead of exiting the application
+func WithAdminErrorHandler(handler func(err error)) AdminOption {
+	return func(c *AdminConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithAdminBindAddress binds the admin listener to a specific address, like 127.0.0.1 or ::1, instead of all interfaces
+// default is envvar ESTAFETTE_ADMIN_BIND_ADDRESS or all interfaces if not set
+func WithAdminBindAddress(address string) AdminOption {
+	return func(c *AdminConfig) {
+		c.BindAddress = address
+	}
+}
+
+// WithAdminServerGroup runs the admin server as part of group, which returns its errors from Wait instead of exiting
+// the application
+func WithAdminServerGroup(group *ServerGroup) AdminOption {
+	return func(c *AdminConfig) {
+		c.ServerGroup = group
+	}
+}
+
+// AdminServer serves operational endpoints, like probes, metrics, pprof and the log level, registered on it before it
+// gets started once, on a single port
+type AdminServer struct {
+	*http.Server
+
+	config  AdminConfig
+	mux     *http.ServeMux
+	started int32
+}
+
+// NewAdminServer returns an AdminServer for port serving /info and /debug/env; register any other endpoints before
+// calling Start
+// server := NewAdminServer(9101)
+// server.RegisterProbes()
+// server.RegisterMetrics()
+// err := server.Start()
+func NewAdminServer(port int, opts ...AdminOption) *AdminServer {
+	// default
+	config := AdminConfig{
+		BindAddress: os.Getenv("ESTAFETTE_ADMIN_BIND_ADDRESS"),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	server := &AdminServer{
+		config: config,
+		mux:    http.NewServeMux(),
+	}
+	server.Server = &http.Server{
+		Addr:              bindAddress(config.BindAddress, port),
+		Handler:           server.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	server.mux.HandleFunc("/info", InfoHandler)
+	server.Handle("/debug/env", http.HandlerFunc(EnvHandler))
+
+	return server
+}
+
+// RegisterProbes serves /liveness, /readiness and /startup, or the paths set with WithLivenessPath and
+// WithReadinessPath, combined with the options passed with WithAdminProbeOptions
+func (s *AdminServer) RegisterProbes(opts ...ProbeOption) {
+	config := newProbeConfig(append(append([]ProbeOption{}, s.config.ProbeOptions...), opts...)...)
+
+	config.handleLiveness(s.mux)
+	config.handleReadiness(s.mux)
+	s.mux.HandleFunc("/startup", startupHandler)
+}
+
+// RegisterMetrics serves /metrics, or the path set with WithMetricsPath, combined with the options passed with
+// WithAdminMetricsOptions
+func (s *AdminServer) RegisterMetrics(opts ...MetricsOption) {
+	InitMetricsWithMux(s.mux, append(append([]MetricsOption{}, s.config.MetricsOptions...), opts...)...)
+}
+
+// RegisterPprof serves the /debug/pprof endpoints
+func (s *AdminServer) RegisterPprof() {
+	s.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	s.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	s.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	s.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	s.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+}
+
+// RegisterLogLevel serves /debug/loglevel for reading and changing the global log level with LogLevelHandler
+func (s *AdminServer) RegisterLogLevel() {
+	s.Handle("/debug/loglevel", http.HandlerFunc(LogLevelHandler))
+}
+
+// Handle registers a custom ops handler for pattern; handlers under /debug/ require the credentials set with
+// WithAdminMetricsOptions, if any
+func (s *AdminServer) Handle(pattern string, handler http.Handler) {
+	if strings.HasPrefix(pattern, "/debug/") {
+		handler = newMetricsConfig(s.config.MetricsOptions...).authenticate(handler)
+	}
+	s.mux.Handle(pattern, handler)
+}
+
+// Start binds the port and serves the registered endpoints in the background; it returns an error if binding fails,
+// errors serving afterwards are passed to the handler set with WithAdminErrorHandler or logged
+func (s *AdminServer) Start() error {
+	if !atomic.CompareAndSwapInt32(&s.started, 0, 1) {
+		return errors.New("Admin server has already been started")
+	}
+
+	listener, err := listen(s.Server, "")
+	if err != nil {
+		if s.config.ServerGroup != nil {
+			s.config.ServerGroup.fail("admin", err)
+		}
+		return err
+	}
+
+	log.Debug().
+		Str("port", s.Addr).
+		Msg("Serving admin endpoints...")
+
+	onError := func(err error) {
+		if s.config.ErrorHandler != nil {
+			s.config.ErrorHandler(err)
+		} else {
+			log.Error().Err(err).Msg("Serving admin endpoints failed")
+		}
+		if s.config.ServerGroup != nil {
+			s.config.ServerGroup.fail("admin", err)
+		}
+	}
+
+	if s.config.ServerGroup != nil {
+		s.config.ServerGroup.serve(s.Server, listener, onError)
+		return nil
+	}
+
+	serveInBackground(s.Server, listener, onError)
+
+	return nil
+}
+
+// Stop shuts the server down, waiting for open requests until the context expires
+func (s *AdminServer) Stop(ctx context.Context) error {
+	return s.Shutdown(ctx)
+}
+
+// InitAdminServer serves /metrics, /liveness, /readiness and /startup, and /debug/pprof if enabled with WithAdminPprof,
+// from one mux on a single port, instead of a listener for metrics and one for the probes; shut down the returned server
+// to release the port
+// server := InitAdminServer(9101)
+func InitAdminServer(port int, opts ...AdminOption) *http.Server {
+	server := NewAdminServer(port, opts...)
+	server.RegisterMetrics()
+	server.RegisterProbes()
+	if server.config.Pprof {
+		server.RegisterPprof()
+	}
+
+	// start admin server
+	log.Debug().
+		Str("port", server.Addr).
+		Bool("pprof", server.config.Pprof).
+		Msg("Serving /metrics, /liveness and /readiness endpoints...")
+
+	listenAndServeInBackground(server.Server, "", "admin", server.config.ErrorHandler, server.config.ServerGroup)
+
+	return server.Server
+}
@@ -0,0 +1,182 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitAdminServer(t *testing.T) {
+	t.Run("ServesMetricsProbesAndPprofOnOnePort", func(t *testing.T) {
+		port := freeTestPort(t)
+
+		// act
+		server := InitAdminServer(port, WithAdminPprof(true))
+		defer ShutdownHTTPServer(server)()
+
+		assert.Eventually(t, func() bool {
+			response, err := http.Get(fmt.Sprintf("http://localhost:%v/liveness", port))
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+		for _, path := range []string{"/metrics", "/liveness", "/readiness", "/debug/pprof/"} {
+			response, err := http.Get(fmt.Sprintf("http://localhost:%v%v", port, path))
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.Equal(t, http.StatusOK, response.StatusCode, path)
+			}
+		}
+	})
+
+	t.Run("DoesNotServePprofByDefault", func(t *testing.T) {
+		port := freeTestPort(t)
+
+		// act
+		server := InitAdminServer(port)
+		defer ShutdownHTTPServer(server)()
+
+		var response *http.Response
+		assert.Eventually(t, func() bool {
+			var err error
+			response, err = http.Get(fmt.Sprintf("http://localhost:%v/debug/pprof/", port))
+			return err == nil
+		}, 2*time.Second, 10*time.Millisecond)
+		response.Body.Close()
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func TestAdminServer(t *testing.T) {
+	t.Run("ServesOnlyRegisteredEndpoints", func(t *testing.T) {
+		port := freeTestPort(t)
+		server := NewAdminServer(port)
+		server.RegisterProbes()
+		server.Handle("/debug/custom", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// act
+		err := server.Start()
+		defer ShutdownHTTPServer(server.Server)()
+
+		assert.Nil(t, err)
+		for path, expectedStatus := range map[string]int{"/liveness": http.StatusOK, "/debug/custom": http.StatusOK, "/info": http.StatusOK, "/metrics": http.StatusNotFound, "/debug/pprof/": http.StatusNotFound} {
+			response, err := http.Get(fmt.Sprintf("http://localhost:%v%v", port, path))
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.Equal(t, expectedStatus, response.StatusCode, path)
+			}
+		}
+	})
+
+	t.Run("RequiresMetricsCredentialsForDebugEndpoints", func(t *testing.T) {
+		port := freeTestPort(t)
+		server := NewAdminServer(port, WithAdminMetricsOptions(WithMetricsBearerToken("abc")))
+		server.RegisterPprof()
+		server.RegisterLogLevel()
+		server.Handle("/debug/custom", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// act
+		err := server.Start()
+		defer ShutdownHTTPServer(server.Server)()
+
+		assert.Nil(t, err)
+		for _, path := range []string{"/debug/env", "/debug/pprof/", "/debug/loglevel", "/debug/custom"} {
+			response, err := http.Get(fmt.Sprintf("http://localhost:%v%v", port, path))
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.Equal(t, http.StatusUnauthorized, response.StatusCode, path)
+			}
+			request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%v%v", port, path), nil)
+			request.Header.Set("Authorization", "Bearer abc")
+			response, err = http.DefaultClient.Do(request)
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.NotEqual(t, http.StatusUnauthorized, response.StatusCode, path)
+			}
+		}
+		response, err := http.Get(fmt.Sprintf("http://localhost:%v/info", port))
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+
+	t.Run("ReturnsBindError", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		server := NewAdminServer(listener.Addr().(*net.TCPAddr).Port)
+
+		// act
+		err = server.Start()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfStartedTwice", func(t *testing.T) {
+		server := NewAdminServer(freeTestPort(t))
+		assert.Nil(t, server.Start())
+		defer ShutdownHTTPServer(server.Server)()
+
+		// act
+		err := server.Start()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReleasesPortOnStop", func(t *testing.T) {
+		port := freeTestPort(t)
+		server := NewAdminServer(port)
+		assert.Nil(t, server.Start())
+		assert.Eventually(t, func() bool {
+			response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/info", port))
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return response.StatusCode == http.StatusOK
+		}, time.Second, 10*time.Millisecond)
+
+		// act
+		err := server.Stop(context.Background())
+
+		assert.Nil(t, err)
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if assert.Nil(t, err) {
+			listener.Close()
+		}
+	})
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	t.Run("ChangesGlobalLevel", func(t *testing.T) {
+		defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+		// act
+		recorder := httptest.NewRecorder()
+		LogLevelHandler(recorder, httptest.NewRequest(http.MethodPut, "/debug/loglevel?level=warn", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "warn\n", recorder.Body.String())
+		assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+	})
+
+	t.Run("RejectsInvalidLevel", func(t *testing.T) {
+		defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+
+		// act
+		recorder := httptest.NewRecorder()
+		LogLevelHandler(recorder, httptest.NewRequest(http.MethodPut, "/debug/loglevel?level=loud", nil))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,180 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Component is a part of the application, like an http server or a queue consumer, that an App starts and stops
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// ComponentFuncs turns a start and a stop func into a Component; either can be nil
+type ComponentFuncs struct {
+	StartFunc func(ctx context.Context) error
+	StopFunc  func(ctx context.Context) error
+}
+
+// Start calls StartFunc if set
+func (c ComponentFuncs) Start(ctx context.Context) error {
+	if c.StartFunc == nil {
+		return nil
+	}
+	return c.StartFunc(ctx)
+}
+
+// Stop calls StopFunc if set
+func (c ComponentFuncs) Stop(ctx context.Context) error {
+	if c.StopFunc == nil {
+		return nil
+	}
+	return c.StopFunc(ctx)
+}
+
+// AppConfig is used to configure an App
+type AppConfig struct {
+	StopTimeout time.Duration
+	Shutdown    ShutdownConfig
+}
+
+// AppOption allows to override app config
+type AppOption func(*AppConfig)
+
+// WithStopTimeout sets the maximum duration for stopping all components once shutdown starts
+// default is 30s
+func WithStopTimeout(timeout time.Duration) AppOption {
+	return func(c *AppConfig) {
+		c.StopTimeout = timeout
+	}
+}
+
+// WithAppShutdown applies the shutdown options, like WithShutdownSignals, to the signals the app stops on
+// default is SIGTERM and SIGINT
+func WithAppShutdown(opts ...ShutdownOption) AppOption {
+	return func(c *AppConfig) {
+		c.Shutdown = newShutdownConfig(opts...)
+	}
+}
+
+type appComponent struct {
+	name      string
+	component Component
+}
+
+// App starts registered components in order of registration, waits for SIGTERM or SIGINT, or the signals set with
+// WithAppShutdown, and stops them in reverse order, instead of wiring waitgroups, channels and init functions by hand in every main
+type App struct {
+	config AppConfig
+
+	mutex      sync.Mutex
+	components []appComponent
+	running    int32
+}
+
+// NewApp returns an App without components
+// app := foundation.NewApp()
+// app.Register("api", foundation.NewHTTPServer(foundation.WithHandler(router)))
+// err := app.Run(context.Background())
+func NewApp(opts ...AppOption) *App {
+	// default
+	config := AppConfig{
+		StopTimeout: 30 * time.Second,
+		Shutdown:    newShutdownConfig(),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &App{
+		config: config,
+	}
+}
+
+// Register adds a named component, which gets started after the components registered before it and stopped before
+// them
+func (a *App) Register(name string, component Component) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.components = append(a.components, appComponent{name: name, component: component})
+}
+
+// Run starts all components and marks the application as started, then blocks until one of the shutdown signals is
+// received or ctx is done and stops the components in reverse order; if a component fails to start, the components started
+// before it are stopped and the error is returned
+func (a *App) Run(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&a.running, 0, 1) {
+		return fmt.Errorf("App is already running")
+	}
+	defer atomic.StoreInt32(&a.running, 0)
+
+	a.mutex.Lock()
+	components := append([]appComponent{}, a.components...)
+	a.mutex.Unlock()
+
+	// listen before starting, so a signal received while starting isn't lost
+	signals := make(chan os.Signal, 1)
+	if len(a.config.Shutdown.Signals) > 0 {
+		signal.Notify(signals, a.config.Shutdown.Signals...)
+	}
+	defer signal.Stop(signals)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for i, c := range components {
+		log.Debug().Str("component", c.name).Msgf("Starting %v...", c.name)
+		if err := c.component.Start(ctx); err != nil {
+			startErr := fmt.Errorf("Starting %v failed: %w", c.name, err)
+			return AppendErr(startErr, a.stop(components[:i]))
+		}
+	}
+	MarkStarted()
+
+	select {
+	case signalReceived := <-signals:
+		log.Info().Msgf("Received signal %v. Stopping components...", signalReceived)
+		PublishLifecycleEvent(EventShutdownSignalReceived, map[string]string{"signal": signalReceived.String()})
+	case <-ctx.Done():
+		log.Info().Msg("Context is done. Stopping components...")
+	}
+
+	if atomic.LoadInt32(&readinessServed) == 1 {
+		SetReady(false)
+	}
+
+	err := a.stop(components)
+
+	log.Info().Msg("Shutting down...")
+	PublishLifecycleEvent(EventShutdownFinished, nil)
+	FlushLogs()
+
+	return err
+}
+
+// stop stops the components in reverse order within the stop timeout, returning the errors of all that failed
+func (a *App) stop(components []appComponent) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.StopTimeout)
+	defer cancel()
+
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		log.Debug().Str("component", c.name).Msgf("Stopping %v...", c.name)
+		if stopErr := c.component.Stop(ctx); stopErr != nil {
+			err = AppendErr(err, fmt.Errorf("Stopping %v failed: %w", c.name, stopErr))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingComponent records its start and stop calls in order
+func recordingComponent(name string, calls *[]string, mutex *sync.Mutex, startErr error) Component {
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*calls = append(*calls, call)
+	}
+
+	return ComponentFuncs{
+		StartFunc: func(ctx context.Context) error {
+			record("start " + name)
+			return startErr
+		},
+		StopFunc: func(ctx context.Context) error {
+			record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestApp(t *testing.T) {
+	t.Run("StopsComponentsInReverseOrderOnceContextIsDone", func(t *testing.T) {
+		var calls []string
+		var mutex sync.Mutex
+		app := NewApp()
+		app.Register("database", recordingComponent("database", &calls, &mutex, nil))
+		app.Register("api", recordingComponent("api", &calls, &mutex, nil))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// act
+		err := app.Run(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"start database", "start api", "stop api", "stop database"}, calls)
+	})
+
+	t.Run("StopsStartedComponentsIfOneFailsToStart", func(t *testing.T) {
+		var calls []string
+		var mutex sync.Mutex
+		app := NewApp()
+		app.Register("database", recordingComponent("database", &calls, &mutex, nil))
+		app.Register("api", recordingComponent("api", &calls, &mutex, errors.New("Port in use")))
+		app.Register("consumer", recordingComponent("consumer", &calls, &mutex, nil))
+
+		// act
+		err := app.Run(context.Background())
+
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "Starting api failed: Port in use")
+		}
+		assert.Equal(t, []string{"start database", "start api", "stop database"}, calls)
+	})
+
+	t.Run("StopsComponentsOnSignal", func(t *testing.T) {
+		defer SetReady(true)
+		var calls []string
+		var mutex sync.Mutex
+		app := NewApp()
+		started := make(chan struct{})
+		app.Register("api", recordingComponent("api", &calls, &mutex, nil))
+		app.Register("signal", ComponentFuncs{StartFunc: func(ctx context.Context) error {
+			close(started)
+			return nil
+		}})
+		done := make(chan error)
+
+		// act
+		go func() {
+			done <- app.Run(context.Background())
+		}()
+		<-started
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"start api", "stop api"}, calls)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "App didn't stop on SIGTERM")
+		}
+	})
+
+	t.Run("StopsComponentsOnSignalSetWithAppShutdown", func(t *testing.T) {
+		defer SetReady(true)
+		var calls []string
+		var mutex sync.Mutex
+		app := NewApp(WithAppShutdown(WithShutdownSignals(syscall.SIGUSR2)))
+		started := make(chan struct{})
+		app.Register("api", recordingComponent("api", &calls, &mutex, nil))
+		app.Register("signal", ComponentFuncs{StartFunc: func(ctx context.Context) error {
+			close(started)
+			return nil
+		}})
+		done := make(chan error)
+
+		// act
+		go func() {
+			done <- app.Run(context.Background())
+		}()
+		<-started
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"start api", "stop api"}, calls)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "App didn't stop on SIGUSR2")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package foundation
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// WithAsyncLogging writes log events to the output from a background goroutine, buffering up to bufferSize events, so
+// logging doesn't wait for a slow stdout; logging blocks once the buffer is full. Call FlushLogs before exiting, which
+// HandleGracefulShutdown does as well, to make sure buffered events aren't lost
+func WithAsyncLogging(bufferSize int) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.AsyncBufferSize = bufferSize
+	}
+}
+
+// FlushLogs waits until all buffered log events have been written to the output, published to kafka if WithKafka is
+// used and exported to the OpenTelemetry collector if InitLoggingOTLP is used
+func FlushLogs() {
+	globalLoggerOutputMutex.Lock()
+	writer := globalLoggerOutput.async
+	kafkaExporter := globalLoggerOutput.kafka
+	globalLoggerOutputMutex.Unlock()
+
+	if writer != nil {
+		writer.flush()
+	}
+
+	if kafkaExporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = kafkaExporter.flush(ctx)
+	}
+
+	otlpExporterMutex.Lock()
+	exporter := otlpExporter
+	otlpExporterMutex.Unlock()
+
+	if exporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = exporter.flush(ctx)
+	}
+}
+
+type asyncLogEntry struct {
+	level zerolog.Level
+	p     []byte
+
+	// flushed is closed once all entries queued before this one have been written
+	flushed chan struct{}
+}
+
+// asyncWriter queues log events and writes them to the underlying writer from a single goroutine; fatal and panic events
+// are written right away after flushing the queue, since the process is about to exit
+type asyncWriter struct {
+	writer  io.Writer
+	entries chan asyncLogEntry
+	doneC   chan struct{}
+
+	mutex   sync.RWMutex
+	stopped bool
+}
+
+func newAsyncWriter(writer io.Writer, bufferSize int) *asyncWriter {
+	aw := &asyncWriter{
+		writer:  writer,
+		entries: make(chan asyncLogEntry, bufferSize),
+		doneC:   make(chan struct{}),
+	}
+
+	go aw.run()
+
+	return aw
+}
+
+func (aw *asyncWriter) run() {
+	defer close(aw.doneC)
+
+	for entry := range aw.entries {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		writeLevel(aw.writer, entry.level, entry.p)
+	}
+}
+
+func (aw *asyncWriter) Write(p []byte) (n int, err error) {
+	return aw.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (aw *asyncWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if level == zerolog.FatalLevel || level == zerolog.PanicLevel {
+		aw.flush()
+		return writeLevel(aw.writer, level, p)
+	}
+
+	aw.mutex.RLock()
+	defer aw.mutex.RUnlock()
+
+	if aw.stopped {
+		return writeLevel(aw.writer, level, p)
+	}
+
+	// zerolog reuses the buffer once write returns
+	entry := asyncLogEntry{level: level, p: make([]byte, len(p))}
+	copy(entry.p, p)
+	aw.entries <- entry
+
+	return len(p), nil
+}
+
+// flush waits until all queued entries have been written
+func (aw *asyncWriter) flush() {
+	aw.mutex.RLock()
+	if aw.stopped {
+		aw.mutex.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	aw.entries <- asyncLogEntry{flushed: flushed}
+	aw.mutex.RUnlock()
+
+	<-flushed
+}
+
+// stop writes all queued entries and ends the goroutine; later writes go to the underlying writer directly
+func (aw *asyncWriter) stop() {
+	aw.mutex.Lock()
+	if aw.stopped {
+		aw.mutex.Unlock()
+		return
+	}
+	aw.stopped = true
+	close(aw.entries)
+	aw.mutex.Unlock()
+
+	<-aw.doneC
+}
+
+// writeLevel passes the level along if the writer is a zerolog.LevelWriter
+func writeLevel(writer io.Writer, level zerolog.Level, p []byte) (n int, err error) {
+	if levelWriter, ok := writer.(zerolog.LevelWriter); ok {
+		return levelWriter.WriteLevel(level, p)
+	}
+	return writer.Write(p)
+}
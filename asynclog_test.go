@@ -0,0 +1,93 @@
+package foundation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockedBuffer is a bytes.Buffer that can be written from the async writer goroutine while the test reads it
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (lb *lockedBuffer) Write(p []byte) (n int, err error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buffer.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	t.Run("WritesAllEventsInOrderOnFlush", func(t *testing.T) {
+		var buffer lockedBuffer
+		writer := newAsyncWriter(&buffer, 2)
+		defer writer.stop()
+		logger := zerolog.New(writer)
+
+		// act
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			logger.Info().Msg(msg)
+		}
+		writer.flush()
+
+		assert.Equal(t, `{"level":"info","message":"a"}
+{"level":"info","message":"b"}
+{"level":"info","message":"c"}
+{"level":"info","message":"d"}
+`, buffer.String())
+	})
+
+	t.Run("WritesPanicEventsSynchronouslyAfterQueuedEvents", func(t *testing.T) {
+		var buffer lockedBuffer
+		writer := newAsyncWriter(&buffer, 10)
+		defer writer.stop()
+
+		// act
+		writer.WriteLevel(zerolog.InfoLevel, []byte("queued\n"))
+		writer.WriteLevel(zerolog.PanicLevel, []byte("panic\n"))
+
+		assert.Equal(t, "queued\npanic\n", buffer.String())
+	})
+
+	t.Run("WritesDirectlyAfterStop", func(t *testing.T) {
+		var buffer lockedBuffer
+		writer := newAsyncWriter(&buffer, 10)
+		writer.Write([]byte("queued\n"))
+
+		// act
+		writer.stop()
+		writer.Write([]byte("direct\n"))
+
+		assert.Equal(t, "queued\ndirect\n", buffer.String())
+	})
+}
+
+func TestFlushLogs(t *testing.T) {
+	t.Run("WritesBufferedEventsToLogFile", func(t *testing.T) {
+		restoreLogger(t)
+		t.Cleanup(func() { setGlobalLoggerOutput(loggerOutput{}, "") })
+		path := filepath.Join(t.TempDir(), "app.log")
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogFile(path, 10, 3, 7), WithAsyncLogging(100))
+		log.Info().Msg("buffered")
+
+		// act
+		FlushLogs()
+
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), `"message":"buffered"`)
+	})
+}
@@ -0,0 +1,232 @@
+package foundation
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AuditEvent records who did what to which resource
+type AuditEvent struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	Details  map[string]interface{}
+}
+
+// AuditConfig configures where audit events are written to and the key their hashes are calculated with
+type AuditConfig struct {
+	Writer io.Writer
+	Key    []byte
+}
+
+// AuditOption allows to override audit config
+type AuditOption func(*AuditConfig)
+
+// WithAuditWriter writes audit events to writer instead of stdout, like a file only the application can append to
+func WithAuditWriter(writer io.Writer) AuditOption {
+	return func(c *AuditConfig) {
+		c.Writer = writer
+	}
+}
+
+// WithAuditKey sets the secret key the hash of every audit entry is calculated with, so entries can't be changed and
+// their hashes recalculated without it; keep it out of reach of whoever can write the audit log
+// default is envvar ESTAFETTE_AUDIT_KEY
+func WithAuditKey(key []byte) AuditOption {
+	return func(c *AuditConfig) {
+		c.Key = key
+	}
+}
+
+// AuditLogger writes audit events as json lines, regardless of the log level; each entry contains the hash of the
+// previous one, keyed with the audit key, so removing or changing entries breaks the chain, which VerifyAuditLog detects
+type AuditLogger struct {
+	mutex        sync.Mutex
+	writer       io.Writer
+	key          []byte
+	sequence     uint64
+	previousHash string
+}
+
+var (
+	auditLoggerMutex sync.Mutex
+	auditLogger      *AuditLogger
+)
+
+// InitAuditLogging configures the audit logger returned by Audit; without it audit events are written to stdout
+func InitAuditLogging(opts ...AuditOption) {
+	auditLoggerMutex.Lock()
+	defer auditLoggerMutex.Unlock()
+
+	auditLogger = NewAuditLogger(opts...)
+}
+
+// Audit returns the audit logger for writing audit events, independent of the application log level; it requires the
+// key set with envvar ESTAFETTE_AUDIT_KEY, or with WithAuditKey passed to InitAuditLogging, without which its Log returns
+// an error for every event and nothing is written
+// foundation.Audit().Log(foundation.AuditEvent{Actor: user, Action: "delete", Resource: "pipeline/" + id, Outcome: "success"})
+func Audit() *AuditLogger {
+	auditLoggerMutex.Lock()
+	defer auditLoggerMutex.Unlock()
+
+	if auditLogger == nil {
+		auditLogger = NewAuditLogger()
+	}
+
+	return auditLogger
+}
+
+// NewAuditLogger returns an audit logger with its own hash chain; it logs an error if no key is set, since it can't write
+// any audit events without it
+func NewAuditLogger(opts ...AuditOption) *AuditLogger {
+	// default
+	config := AuditConfig{
+		Writer: os.Stdout,
+		Key:    []byte(os.Getenv("ESTAFETTE_AUDIT_KEY")),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if len(config.Key) == 0 {
+		log.Error().Msg("Audit events can't be written without a key; set envvar ESTAFETTE_AUDIT_KEY or pass WithAuditKey")
+	}
+
+	return &AuditLogger{
+		writer: config.Writer,
+		key:    config.Key,
+	}
+}
+
+// auditEntry is the json line written for an audit event; the hash is the hmac of the entry without the hash
+type auditEntry struct {
+	Type         string                 `json:"type"`
+	Time         string                 `json:"time"`
+	App          string                 `json:"app,omitempty"`
+	Sequence     uint64                 `json:"sequence"`
+	Actor        string                 `json:"actor"`
+	Action       string                 `json:"action"`
+	Resource     string                 `json:"resource"`
+	Outcome      string                 `json:"outcome,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	PreviousHash string                 `json:"previousHash"`
+	Hash         string                 `json:"hash,omitempty"`
+}
+
+// Log writes the audit event; errors are returned rather than logged, since losing audit events usually has to fail
+// the action being audited
+func (a *AuditLogger) Log(event AuditEvent) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.key) == 0 {
+		return errAuditKeyMissing
+	}
+
+	entry := auditEntry{
+		Type:         "audit",
+		Time:         currentClock().Now().UTC().Format(time.RFC3339Nano),
+		App:          getApplicationInfo().App,
+		Sequence:     a.sequence + 1,
+		Actor:        event.Actor,
+		Action:       event.Action,
+		Resource:     event.Resource,
+		Outcome:      event.Outcome,
+		Details:      event.Details,
+		PreviousHash: a.previousHash,
+	}
+
+	hash, err := entry.hash(a.key)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err = a.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	a.sequence = entry.Sequence
+	a.previousHash = entry.Hash
+
+	return nil
+}
+
+var errAuditKeyMissing = errors.New("Audit logging requires a key, set it with WithAuditKey or envvar ESTAFETTE_AUDIT_KEY")
+
+func (e auditEntry) hash(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyAuditLog reads the audit events written by a single audit logger and returns an error if an entry has been
+// changed, removed or inserted, or its hash wasn't calculated with key; every audit logger starts a new chain, so write
+// the events of every process to its own audit log. Removing entries from the end isn't detected, compare the last
+// sequence with the one expected
+func VerifyAuditLog(reader io.Reader, key []byte) error {
+	if len(key) == 0 {
+		return errAuditKeyMissing
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	previousHash := ""
+	var sequence uint64
+	for scanner.Scan() {
+		var entry auditEntry
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("Audit entry %v can't be parsed: %w", sequence+1, err)
+		}
+
+		if entry.Sequence != sequence+1 {
+			return fmt.Errorf("Audit entry %v follows entry %v", entry.Sequence, sequence)
+		}
+		if entry.PreviousHash != previousHash {
+			return fmt.Errorf("Audit entry %v doesn't follow the previous entry's hash", entry.Sequence)
+		}
+
+		hash, err := entry.hash(key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+			return fmt.Errorf("Audit entry %v has been changed", entry.Sequence)
+		}
+
+		sequence = entry.Sequence
+		previousHash = entry.Hash
+	}
+
+	return scanner.Err()
+}
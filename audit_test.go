@@ -0,0 +1,127 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogger(t *testing.T) {
+	t.Run("WritesEventsRegardlessOfLogLevel", func(t *testing.T) {
+		restoreLogger(t)
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+		clock := NewManualClock(time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC))
+		SetClock(clock)
+		defer SetClock(nil)
+		var buffer bytes.Buffer
+		logger := NewAuditLogger(WithAuditWriter(&buffer), WithAuditKey([]byte("secret")))
+
+		// act
+		err := logger.Log(AuditEvent{Actor: "jane", Action: "delete", Resource: "pipeline/123", Outcome: "success", Details: map[string]interface{}{"reason": "cleanup"}})
+
+		assert.Nil(t, err)
+		var entry map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry)) {
+			assert.Equal(t, "audit", entry["type"])
+			assert.Equal(t, "2020-09-13T12:26:40Z", entry["time"])
+			assert.Equal(t, float64(1), entry["sequence"])
+			assert.Equal(t, "jane", entry["actor"])
+			assert.Equal(t, "delete", entry["action"])
+			assert.Equal(t, "pipeline/123", entry["resource"])
+			assert.Equal(t, "success", entry["outcome"])
+			assert.Equal(t, map[string]interface{}{"reason": "cleanup"}, entry["details"])
+			assert.Equal(t, "", entry["previousHash"])
+			assert.Len(t, entry["hash"], 64)
+		}
+	})
+
+	t.Run("ReturnsErrorWithoutKey", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_AUDIT_KEY", "")
+		captureLogs(t)
+		var buffer bytes.Buffer
+		logger := NewAuditLogger(WithAuditWriter(&buffer))
+
+		// act
+		err := logger.Log(AuditEvent{Actor: "jane", Action: "delete", Resource: "pipeline/123"})
+
+		assert.Equal(t, errAuditKeyMissing, err)
+		assert.Equal(t, 0, buffer.Len())
+	})
+}
+
+func TestNewAuditLogger(t *testing.T) {
+	t.Run("LogsErrorWithoutKey", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_AUDIT_KEY", "")
+		logs := captureLogs(t)
+
+		// act
+		NewAuditLogger(WithAuditWriter(&bytes.Buffer{}))
+
+		assert.Contains(t, logs.String(), "Audit events can't be written without a key")
+	})
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	key := []byte("secret")
+	writeAuditLog := func(chains ...int) string {
+		var buffer bytes.Buffer
+		for _, events := range chains {
+			logger := NewAuditLogger(WithAuditWriter(&buffer), WithAuditKey(key))
+			for i := 0; i < events; i++ {
+				logger.Log(AuditEvent{Actor: "jane", Action: "update", Resource: "pipeline/123", Details: map[string]interface{}{"attempt": i}})
+			}
+		}
+		return buffer.String()
+	}
+
+	t.Run("ReturnsNilForUntamperedLog", func(t *testing.T) {
+		auditLog := writeAuditLog(3)
+
+		// act
+		err := VerifyAuditLog(strings.NewReader(auditLog), key)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfChainIsRestarted", func(t *testing.T) {
+		auditLog := writeAuditLog(3, 2)
+
+		// act
+		err := VerifyAuditLog(strings.NewReader(auditLog), key)
+
+		assert.EqualError(t, err, "Audit entry 1 follows entry 3")
+	})
+
+	t.Run("ReturnsErrorIfHashesAreRecalculatedWithOtherKey", func(t *testing.T) {
+		auditLog := writeAuditLog(3)
+
+		// act
+		err := VerifyAuditLog(strings.NewReader(auditLog), []byte("other"))
+
+		assert.EqualError(t, err, "Audit entry 1 has been changed")
+	})
+
+	t.Run("ReturnsErrorIfEntryIsChanged", func(t *testing.T) {
+		auditLog := strings.Replace(writeAuditLog(3), `"actor":"jane"`, `"actor":"john"`, 1)
+
+		// act
+		err := VerifyAuditLog(strings.NewReader(auditLog), key)
+
+		assert.EqualError(t, err, "Audit entry 1 has been changed")
+	})
+
+	t.Run("ReturnsErrorIfEntryIsRemoved", func(t *testing.T) {
+		lines := strings.SplitAfter(writeAuditLog(3), "\n")
+		auditLog := lines[0] + lines[2]
+
+		// act
+		err := VerifyAuditLog(strings.NewReader(auditLog), key)
+
+		assert.EqualError(t, err, "Audit entry 3 follows entry 1")
+	})
+}
@@ -0,0 +1,116 @@
+package foundation
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// BearerTokenAuth returns a middleware that only allows requests with an 'Authorization: Bearer <token>' header matching one of the tokens
+func BearerTokenAuth(realm string, tokens ...string) Middleware {
+	return authMiddleware("bearer", fmt.Sprintf("Bearer realm=%q", realm), func(r *http.Request) (bool, bool) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(strings.ToLower(authorization), "bearer ") {
+			return false, false
+		}
+		return true, secureCompareAny(strings.TrimSpace(authorization[7:]), tokens)
+	})
+}
+
+// BasicAuth returns a middleware that only allows requests with basic auth credentials matching one of the username/password pairs
+func BasicAuth(realm string, credentials map[string]string) Middleware {
+	return authMiddleware("basic", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm), func(r *http.Request) (bool, bool) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return false, false
+		}
+
+		// compare against all credentials to avoid leaking which usernames exist
+		valid := false
+		for u, p := range credentials {
+			if secureCompare(username, u) && secureCompare(password, p) {
+				valid = true
+			}
+		}
+		return true, valid
+	})
+}
+
+// APIKeyAuth returns a middleware that only allows requests with the header (for example X-API-Key) matching one of the keys
+func APIKeyAuth(header string, keys ...string) Middleware {
+	return authMiddleware("apikey", "", func(r *http.Request) (bool, bool) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return false, false
+		}
+		return true, secureCompareAny(key, keys)
+	})
+}
+
+var authFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_auth_failures_total",
+	Help: "Total number of http requests rejected by the authentication middleware.",
+}, []string{"scheme", "reason"})
+
+// registerAuthMetrics exposes http_auth_failures_total
+func registerAuthMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, authFailuresTotal)
+}
+
+// authMiddleware rejects requests with a 401 status code if authenticate returns false for either presence or validity of the credentials
+func authMiddleware(scheme, challenge string, authenticate func(r *http.Request) (present, valid bool)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			present, valid := authenticate(r)
+			if present && valid {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reason := "invalid"
+			if !present {
+				reason = "missing"
+			}
+			authFailuresTotal.WithLabelValues(scheme, reason).Inc()
+
+			log.Debug().
+				Str("scheme", scheme).
+				Str("reason", reason).
+				Str("path", r.URL.Path).
+				Str("remoteIp", clientIP(r)).
+				Msg("Rejected unauthenticated request")
+
+			if challenge != "" {
+				w.Header().Set("WWW-Authenticate", challenge)
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, http.StatusText(http.StatusUnauthorized)+"\n")
+		})
+	}
+}
+
+// secureCompareAny compares the value against all expected values in constant time
+func secureCompareAny(value string, expectedValues []string) bool {
+	valid := false
+	for _, expected := range expectedValues {
+		if secureCompare(value, expected) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// secureCompare compares the sha256 hashes of both values in constant time, so neither content nor length is leaked through timing
+func secureCompare(value, expected string) bool {
+	valueHash := sha256.Sum256([]byte(value))
+	expectedHash := sha256.Sum256([]byte(expected))
+
+	return subtle.ConstantTimeCompare(valueHash[:], expectedHash[:]) == 1
+}
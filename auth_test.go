@@ -0,0 +1,96 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerTokenAuth(t *testing.T) {
+
+	t.Run("AllowsRequestWithValidToken", func(t *testing.T) {
+
+		handler := BearerTokenAuth("admin", "secret-token")(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Authorization", "Bearer secret-token")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Returns401WithChallengeForInvalidToken", func(t *testing.T) {
+
+		handler := BearerTokenAuth("admin", "secret-token")(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Authorization", "Bearer wrong-token")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, `Bearer realm="admin"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+}
+
+func TestBasicAuth(t *testing.T) {
+
+	t.Run("AllowsRequestWithValidCredentials", func(t *testing.T) {
+
+		handler := BasicAuth("metrics", map[string]string{"prometheus": "scrape"})(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.SetBasicAuth("prometheus", "scrape")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Returns401ForMissingCredentials", func(t *testing.T) {
+
+		handler := BasicAuth("metrics", map[string]string{"prometheus": "scrape"})(http.NotFoundHandler())
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, `Basic realm="metrics", charset="UTF-8"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+
+	t.Run("Returns401ForInvalidKey", func(t *testing.T) {
+
+		handler := APIKeyAuth("X-API-Key", "key-1", "key-2")(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-API-Key", "key-3")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("AllowsRequestWithAnyValidKey", func(t *testing.T) {
+
+		handler := APIKeyAuth("X-API-Key", "key-1", "key-2")(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-API-Key", "key-2")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
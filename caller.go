@@ -0,0 +1,59 @@
+package foundation
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const foundationPackage = "github.com/estafette/estafette-foundation."
+
+// WithCaller adds the file and line logging the message as caller field to all logs; messages logged by this package
+// on behalf of the application, like HandleError, get the file and line in the application calling it. It can be
+// enabled with envvar ESTAFETTE_LOG_CALLER=true as well
+func WithCaller() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Caller = true
+	}
+}
+
+// callerHook adds the first caller outside of zerolog, the standard log library and this package, since zerolog's own
+// Caller() with a fixed skip frame count points at this package for messages logged through its wrappers
+type callerHook struct{}
+
+func (h callerHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if frame, ok := applicationCaller(); ok {
+		e.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(frame.File, frame.Line))
+	}
+}
+
+// applicationCaller returns the first stack frame that isn't part of the logging libraries or this package, except for
+// its tests
+func applicationCaller() (frame runtime.Frame, ok bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isLoggingFrame(frame) {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	switch {
+	case strings.HasPrefix(frame.Function, "github.com/rs/zerolog"):
+		return true
+	case strings.HasPrefix(frame.Function, "log."):
+		return true
+	case strings.HasPrefix(frame.Function, foundationPackage):
+		return !strings.HasSuffix(frame.File, "_test.go")
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package foundation
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CPUThrottling is the cpu throttling of the container as reported by the cgroup
+type CPUThrottling struct {
+	Periods          int64
+	ThrottledPeriods int64
+	ThrottledSeconds float64
+}
+
+// cgroupCollector reads the cgroup memory usage and limit, cpu quota and throttling on each scrape, so dashboards can
+// show headroom relative to the kubernetes limits instead of node level values
+type cgroupCollector struct {
+	memoryUsageBytes         *prometheus.Desc
+	memoryLimitBytes         *prometheus.Desc
+	cpuQuotaCores            *prometheus.Desc
+	cpuPeriodsTotal          *prometheus.Desc
+	cpuThrottledPeriodsTotal *prometheus.Desc
+	cpuThrottledSecondsTotal *prometheus.Desc
+}
+
+var cgroupMetrics = &cgroupCollector{
+	memoryUsageBytes:         prometheus.NewDesc("cgroup_memory_usage_bytes", "Memory usage of the container in bytes as reported by the cgroup.", nil, nil),
+	memoryLimitBytes:         prometheus.NewDesc("cgroup_memory_limit_bytes", "Memory limit of the container in bytes as set by the cgroup, 0 if unlimited.", nil, nil),
+	cpuQuotaCores:            prometheus.NewDesc("cgroup_cpu_quota_cores", "Number of cpus the container is allowed to use as set by the cgroup.", nil, nil),
+	cpuPeriodsTotal:          prometheus.NewDesc("cgroup_cpu_periods_total", "Number of cpu enforcement periods that elapsed.", nil, nil),
+	cpuThrottledPeriodsTotal: prometheus.NewDesc("cgroup_cpu_throttled_periods_total", "Number of cpu enforcement periods in which the container got throttled.", nil, nil),
+	cpuThrottledSecondsTotal: prometheus.NewDesc("cgroup_cpu_throttled_seconds_total", "Total time the container got throttled for.", nil, nil),
+}
+
+// registerCgroupMetrics exposes the cgroup memory and cpu metrics
+func registerCgroupMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, cgroupMetrics)
+}
+
+func (c *cgroupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.memoryUsageBytes
+	ch <- c.memoryLimitBytes
+	ch <- c.cpuQuotaCores
+	ch <- c.cpuPeriodsTotal
+	ch <- c.cpuThrottledPeriodsTotal
+	ch <- c.cpuThrottledSecondsTotal
+}
+
+// Collect only emits the metrics that can be read, so there's nothing outside a container
+func (c *cgroupCollector) Collect(ch chan<- prometheus.Metric) {
+	if usage, ok := GetMemoryUsage(); ok {
+		ch <- prometheus.MustNewConstMetric(c.memoryUsageBytes, prometheus.GaugeValue, float64(usage.UsageBytes))
+		ch <- prometheus.MustNewConstMetric(c.memoryLimitBytes, prometheus.GaugeValue, float64(usage.LimitBytes))
+	}
+
+	if cpus, ok := readCgroupCPUQuota(); ok {
+		ch <- prometheus.MustNewConstMetric(c.cpuQuotaCores, prometheus.GaugeValue, cpus)
+	}
+
+	if throttling, ok := GetCPUThrottling(); ok {
+		ch <- prometheus.MustNewConstMetric(c.cpuPeriodsTotal, prometheus.CounterValue, float64(throttling.Periods))
+		ch <- prometheus.MustNewConstMetric(c.cpuThrottledPeriodsTotal, prometheus.CounterValue, float64(throttling.ThrottledPeriods))
+		ch <- prometheus.MustNewConstMetric(c.cpuThrottledSecondsTotal, prometheus.CounterValue, throttling.ThrottledSeconds)
+	}
+}
+
+// GetCPUThrottling reads the cpu throttling from cgroup v2 or v1 cpu.stat; it returns false if it can't be read
+func GetCPUThrottling() (throttling CPUThrottling, ok bool) {
+	// cgroup v2 reports the throttled time in microseconds
+	if content, err := readCgroupFile("cpu.stat"); err == nil && strings.Contains(content, "nr_periods") {
+		throttling.Periods = readCgroupKeyValue(content, "nr_periods")
+		throttling.ThrottledPeriods = readCgroupKeyValue(content, "nr_throttled")
+		throttling.ThrottledSeconds = float64(readCgroupKeyValue(content, "throttled_usec")) / 1e6
+		return throttling, true
+	}
+
+	// cgroup v1 reports it in nanoseconds
+	content, err := readCgroupFile(filepath.Join("cpu", "cpu.stat"))
+	if err != nil {
+		return throttling, false
+	}
+	throttling.Periods = readCgroupKeyValue(content, "nr_periods")
+	throttling.ThrottledPeriods = readCgroupKeyValue(content, "nr_throttled")
+	throttling.ThrottledSeconds = float64(readCgroupKeyValue(content, "throttled_time")) / 1e9
+
+	return throttling, true
+}
@@ -0,0 +1,79 @@
+package foundation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCPUThrottling(t *testing.T) {
+	t.Run("ReturnsThrottlingFromCgroupV2", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"cpu.stat": "usage_usec 1000000\nnr_periods 100\nnr_throttled 25\nthrottled_usec 1500000\n",
+		})
+
+		// act
+		throttling, ok := GetCPUThrottling()
+
+		assert.True(t, ok)
+		assert.Equal(t, CPUThrottling{Periods: 100, ThrottledPeriods: 25, ThrottledSeconds: 1.5}, throttling)
+	})
+
+	t.Run("ReturnsThrottlingFromCgroupV1", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"cpu/cpu.stat": "nr_periods 100\nnr_throttled 25\nthrottled_time 2000000000\n",
+		})
+
+		// act
+		throttling, ok := GetCPUThrottling()
+
+		assert.True(t, ok)
+		assert.Equal(t, CPUThrottling{Periods: 100, ThrottledPeriods: 25, ThrottledSeconds: 2}, throttling)
+	})
+
+	t.Run("ReturnsFalseWithoutCgroup", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{})
+
+		// act
+		_, ok := GetCPUThrottling()
+
+		assert.False(t, ok)
+	})
+}
+
+func TestCgroupCollector(t *testing.T) {
+	t.Run("CollectsMemoryAndCPUMetrics", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"memory.current": "500000000\n",
+			"memory.max":     "1000000000\n",
+			"cpu.max":        "150000 100000\n",
+			"cpu.stat":       "nr_periods 100\nnr_throttled 25\nthrottled_usec 1500000\n",
+		})
+
+		// act
+		err := testutil.CollectAndCompare(cgroupMetrics, strings.NewReader(`
+# HELP cgroup_cpu_quota_cores Number of cpus the container is allowed to use as set by the cgroup.
+# TYPE cgroup_cpu_quota_cores gauge
+cgroup_cpu_quota_cores 1.5
+# HELP cgroup_cpu_throttled_seconds_total Total time the container got throttled for.
+# TYPE cgroup_cpu_throttled_seconds_total counter
+cgroup_cpu_throttled_seconds_total 1.5
+# HELP cgroup_memory_limit_bytes Memory limit of the container in bytes as set by the cgroup, 0 if unlimited.
+# TYPE cgroup_memory_limit_bytes gauge
+cgroup_memory_limit_bytes 1e+09
+`), "cgroup_cpu_quota_cores", "cgroup_cpu_throttled_seconds_total", "cgroup_memory_limit_bytes")
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("CollectsNothingWithoutCgroup", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{})
+
+		// act
+		count := testutil.CollectAndCount(cgroupMetrics)
+
+		assert.Equal(t, 0, count)
+	})
+}
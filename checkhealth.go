@@ -0,0 +1,52 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CheckHealth requests url until it responds with a 2xx status or timeout expires, retrying failed attempts; it's meant
+// for a healthcheck subcommand in images without curl, exiting non-zero when it returns an error
+// if err := foundation.CheckHealth("http://localhost:5000/readiness", 5*time.Second); err != nil {
+// log.Fatal().Err(err).Msg("Application is unhealthy")
+// }
+func CheckHealth(url string, timeout time.Duration) error {
+	return checkHealth(http.DefaultClient, url, timeout)
+}
+
+func checkHealth(client *http.Client, url string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return Retry(func() error {
+		return checkHealthOnce(ctx, client, url)
+	}, Attempts(3), DelayMillisecond(200), Fixed(), LastErrorOnly(true), Name("checkhealth"), Context(ctx), func(c *RetryConfig) {
+		// stop retrying once the timeout has expired
+		c.IsRetryableError = func(err error) bool {
+			return ctx.Err() == nil
+		}
+	})
+}
+
+func checkHealthOnce(ctx context.Context, client *http.Client, url string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("Health check %v returned status %v: %s", url, response.StatusCode, body)
+	}
+
+	return nil
+}
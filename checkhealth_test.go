@@ -0,0 +1,61 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("ReturnsNilIfHealthy", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(readinessHandler))
+		defer server.Close()
+
+		// act
+		err := CheckHealth(server.URL, time.Second)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("RetriesUntilHealthy", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		// act
+		err := CheckHealth(server.URL, 5*time.Second)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("ReturnsErrorIfUnhealthy", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("I'm not ready!"))
+		}))
+		defer server.Close()
+
+		// act
+		err := CheckHealth(server.URL, 5*time.Second)
+
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "returned status 503: I'm not ready!")
+		}
+	})
+
+	t.Run("ReturnsErrorIfUnreachable", func(t *testing.T) {
+		// act
+		err := CheckHealth("http://127.0.0.1:1/readiness", time.Second)
+
+		assert.NotNil(t, err)
+	})
+}
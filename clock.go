@@ -0,0 +1,205 @@
+package foundation
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers, so time-dependent behaviour can be tested without real sleeps
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals on its channel, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+var (
+	clockMutex sync.RWMutex
+	clock      Clock = systemClock{}
+)
+
+// SetClock sets the clock used by retries, rate limiters, warmup timing and the goroutine monitor; passing nil restores
+// the system clock
+func SetClock(c Clock) {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+
+	if c == nil {
+		c = systemClock{}
+	}
+	clock = c
+}
+
+func currentClock() Clock {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+
+	return clock
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.ticker.C }
+func (t systemTicker) Stop()               { t.ticker.Stop() }
+
+// ManualClock is a Clock for tests that only moves forward when Advance or Set is called
+type ManualClock struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*manualWaiter
+}
+
+type manualWaiter struct {
+	deadline time.Time
+	period   time.Duration
+	c        chan time.Time
+}
+
+// NewManualClock returns a ManualClock starting at the given time
+func NewManualClock(start time.Time) *ManualClock {
+	c := &ManualClock{now: start}
+	c.cond = sync.NewCond(&c.mutex)
+	return c
+}
+
+// Now returns the current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Since returns the time elapsed on the clock since t
+func (c *ManualClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Sleep blocks until the clock has been advanced by at least d
+func (c *ManualClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// After returns a channel that receives the time once the clock has been advanced by at least d
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	return c.addWaiter(d, 0).c
+}
+
+// NewTicker returns a ticker that ticks each time the clock passes another multiple of d
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for ManualClock.NewTicker")
+	}
+
+	return &manualTicker{clock: c, waiter: c.addWaiter(d, d)}
+}
+
+// Advance moves the clock forward by d, firing all timers and tickers that are due
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing all timers and tickers that are due
+func (c *ManualClock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = t
+
+	// fire in deadline order so the receivers observe the same order as with real timers
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].deadline.Before(c.waiters[j].deadline) })
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(t) {
+			remaining = append(remaining, w)
+			continue
+		}
+
+		// like time.Ticker drop ticks for slow receivers
+		select {
+		case w.c <- t:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.deadline.After(t) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+}
+
+// BlockUntil blocks until at least n timers, sleeps or tickers are waiting on the clock, so a test can advance the
+// clock only once the code under test is waiting for it
+func (c *ManualClock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *ManualClock) addWaiter(d, period time.Duration) *manualWaiter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	w := &manualWaiter{
+		deadline: c.now.Add(d),
+		period:   period,
+		c:        make(chan time.Time, 1),
+	}
+
+	if d <= 0 && period == 0 {
+		w.c <- c.now
+		return w
+	}
+
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+
+	return w
+}
+
+func (c *ManualClock) removeWaiter(w *manualWaiter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type manualTicker struct {
+	clock  *ManualClock
+	waiter *manualWaiter
+}
+
+func (t *manualTicker) C() <-chan time.Time { return t.waiter.c }
+func (t *manualTicker) Stop()               { t.clock.removeWaiter(t.waiter) }
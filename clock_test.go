@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualClock(t *testing.T) {
+	t.Run("NowReturnsStartTime", func(t *testing.T) {
+		start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := NewManualClock(start)
+
+		// act
+		now := clock.Now()
+
+		assert.Equal(t, start, now)
+	})
+
+	t.Run("AfterFiresOnceAdvancedPastDuration", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		c := clock.After(time.Second)
+
+		// act
+		clock.Advance(500 * time.Millisecond)
+		select {
+		case <-c:
+			assert.Fail(t, "fired too early")
+		default:
+		}
+		clock.Advance(500 * time.Millisecond)
+
+		select {
+		case <-c:
+		default:
+			assert.Fail(t, "didn't fire")
+		}
+	})
+
+	t.Run("SleepReturnsWhenAdvanced", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(time.Minute)
+			close(done)
+		}()
+		clock.BlockUntil(1)
+
+		// act
+		clock.Advance(time.Minute)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "sleep didn't return")
+		}
+	})
+
+	t.Run("TickerTicksEachInterval", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		ticker := clock.NewTicker(time.Second)
+		ticks := 0
+
+		// act
+		for i := 0; i < 3; i++ {
+			clock.Advance(time.Second)
+			select {
+			case <-ticker.C():
+				ticks++
+			default:
+			}
+		}
+		ticker.Stop()
+		clock.Advance(time.Second)
+
+		assert.Equal(t, 3, ticks)
+		select {
+		case <-ticker.C():
+			assert.Fail(t, "ticked after stop")
+		default:
+		}
+	})
+}
+
+func TestSetClock(t *testing.T) {
+	t.Run("RetryWaitsOnInjectedClock", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		done := make(chan error)
+		go func() {
+			done <- Retry(func() error { return errors.New("failed") }, Attempts(2), DelayMillisecond(int(time.Hour/time.Millisecond)), Fixed(), LastErrorOnly(true))
+		}()
+		clock.BlockUntil(1)
+
+		// act
+		clock.Advance(time.Hour)
+
+		select {
+		case err := <-done:
+			assert.EqualError(t, err, "failed")
+		case <-time.After(time.Second):
+			assert.Fail(t, "retry didn't continue after advancing the clock")
+		}
+	})
+}
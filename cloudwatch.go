@@ -0,0 +1,84 @@
+package foundation
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// EMFMetric is a single metric value in a CloudWatch embedded metric format payload
+type EMFMetric struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+// newLoggerCloudWatch outputs logs in json with the timestamp, level and message fields CloudWatch Logs Insights
+// and Lambda use
+func newLoggerCloudWatch(applicationInfo ApplicationInfo, runtimeInfo RuntimeInfo, output io.Writer) zerolog.Logger {
+
+	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.000Z07:00"
+	zerolog.TimestampFunc = func() time.Time {
+		return time.Now().UTC()
+	}
+	zerolog.TimestampFieldName = "timestamp"
+	zerolog.LevelFieldName = "level"
+	zerolog.LevelFieldMarshalFunc = func(l zerolog.Level) string {
+		return strings.ToUpper(l.String())
+	}
+
+	// set some default fields added to all logs
+	return withRuntimeInfo(zerolog.New(output).With().
+		Timestamp().
+		Str("service", applicationInfo.App).
+		Str("version", applicationInfo.Version), runtimeInfo).
+		Logger()
+}
+
+// WithEmbeddedMetrics adds the metrics and dimensions to a log event in CloudWatch embedded metric format, so
+// CloudWatch extracts them as metrics in the namespace; use with the CloudWatch log format
+// log.Info().Func(foundation.WithEmbeddedMetrics("myapp", map[string]string{"route": "/api"}, foundation.EMFMetric{Name: "latency", Unit: "Milliseconds", Value: 12})).Msg("Handled request")
+func WithEmbeddedMetrics(namespace string, dimensions map[string]string, metrics ...EMFMetric) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		dimensionNames := make([]string, 0, len(dimensions))
+		for name := range dimensions {
+			dimensionNames = append(dimensionNames, name)
+		}
+		sort.Strings(dimensionNames)
+
+		metricDefinitions := zerolog.Arr()
+		for _, metric := range metrics {
+			definition := zerolog.Dict().Str("Name", metric.Name)
+			if metric.Unit != "" {
+				definition = definition.Str("Unit", metric.Unit)
+			}
+			metricDefinitions = metricDefinitions.Dict(definition)
+		}
+
+		e.Dict("_aws", zerolog.Dict().
+			Int64("Timestamp", currentClock().Now().UnixMilli()).
+			Array("CloudWatchMetrics", zerolog.Arr().Dict(zerolog.Dict().
+				Str("Namespace", namespace).
+				Interface("Dimensions", [][]string{dimensionNames}).
+				Array("Metrics", metricDefinitions))))
+
+		// emf expects dimension and metric values as top level fields
+		for _, name := range dimensionNames {
+			e.Str(name, dimensions[name])
+		}
+		for _, metric := range metrics {
+			e.Float64(metric.Name, metric.Value)
+		}
+	}
+}
+
+// LogEmbeddedMetrics logs the metrics in CloudWatch embedded metric format
+func LogEmbeddedMetrics(namespace string, dimensions map[string]string, metrics ...EMFMetric) {
+	log.Info().
+		Func(WithEmbeddedMetrics(namespace, dimensions, metrics...)).
+		Msgf("Metrics for namespace %v", namespace)
+}
@@ -0,0 +1,65 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func restoreZerologGlobals(t *testing.T) {
+	timeFieldFormat := zerolog.TimeFieldFormat
+	timestampFunc := zerolog.TimestampFunc
+	timestampFieldName := zerolog.TimestampFieldName
+	levelFieldName := zerolog.LevelFieldName
+	levelFieldMarshalFunc := zerolog.LevelFieldMarshalFunc
+	errorMarshalFunc := zerolog.ErrorMarshalFunc
+	restoreLogger(t)
+
+	t.Cleanup(func() {
+		zerolog.TimeFieldFormat = timeFieldFormat
+		zerolog.TimestampFunc = timestampFunc
+		zerolog.TimestampFieldName = timestampFieldName
+		zerolog.LevelFieldName = levelFieldName
+		zerolog.LevelFieldMarshalFunc = levelFieldMarshalFunc
+		zerolog.ErrorMarshalFunc = errorMarshalFunc
+	})
+}
+
+func TestNewLoggerCloudWatch(t *testing.T) {
+	t.Run("WritesTimestampAndUppercaseLevel", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		log.Logger = newLoggerCloudWatch(ApplicationInfo{App: "myapp", Version: "1.0.0"}, RuntimeInfo{}, &buffer)
+
+		// act
+		log.Warn().Msg("Hello")
+
+		var event map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event))
+		assert.Equal(t, "WARN", event["level"])
+		assert.Equal(t, "Hello", event["message"])
+		assert.Equal(t, "myapp", event["service"])
+		_, err := time.Parse("2006-01-02T15:04:05.000Z", event["timestamp"].(string))
+		assert.Nil(t, err)
+	})
+}
+
+func TestWithEmbeddedMetrics(t *testing.T) {
+	t.Run("AddsEmbeddedMetricFormatPayload", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1600000000, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		var buffer bytes.Buffer
+		logger := zerolog.New(&buffer)
+
+		// act
+		logger.Info().Func(WithEmbeddedMetrics("myapp", map[string]string{"route": "/api"}, EMFMetric{Name: "latency", Unit: "Milliseconds", Value: 12})).Msg("Handled request")
+
+		assert.Equal(t, `{"level":"info","_aws":{"Timestamp":1600000000000,"CloudWatchMetrics":[{"Namespace":"myapp","Dimensions":[["route"]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"}]}]},"route":"/api","latency":12,"message":"Handled request"}`+"\n", buffer.String())
+	})
+}
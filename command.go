@@ -15,6 +15,7 @@ import (
 // HandleError logs a fatal when the error is not nil
 func HandleError(err error) {
 	if err != nil {
+		reportError(context.Background(), err, true, 2, nil)
 		log.Fatal().Err(err).Msg("Fatal error")
 	}
 }
@@ -61,10 +62,13 @@ func RunCommandWithArgsExtended(ctx context.Context, command string, args []stri
 
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := newLineRedactingWriter(os.Stdout), newLineRedactingWriter(os.Stderr)
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err := runInstrumentedCommand(command, cmd.Run)
 
 	return err
 }
@@ -76,13 +80,15 @@ func RunCommandWithArgsExtendedCombinedStdErr(ctx context.Context, command strin
 
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdout = os.Stdout
+	stdout := newLineRedactingWriter(os.Stdout)
+	defer stdout.Flush()
+	cmd.Stdout = stdout
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err := runInstrumentedCommand(command, cmd.Run)
 	if err != nil {
-		return fmt.Errorf("%s: %s", err, stderr.String())
+		return fmt.Errorf("%s: %s", err, RedactString(stderr.String()))
 	}
 
 	return nil
@@ -96,7 +102,11 @@ func GetCommandWithArgsOutput(ctx context.Context, command string, args []string
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
 
-	output, err := cmd.CombinedOutput()
+	var output []byte
+	err := runInstrumentedCommand(command, func() (err error) {
+		output, err = cmd.CombinedOutput()
+		return err
+	})
 
 	return string(output), err
 }
@@ -129,11 +139,14 @@ func RunCommandInDirectoryWithArgsExtended(ctx context.Context, dir string, comm
 
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := newLineRedactingWriter(os.Stdout), newLineRedactingWriter(os.Stderr)
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	cmd.Dir = dir
 
-	err := cmd.Run()
+	err := runInstrumentedCommand(command, cmd.Run)
 
 	return err
 }
@@ -152,14 +165,16 @@ func RunCommandInDirectoryWithArgsExtendedCombinedStdErr(ctx context.Context, di
 
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdout = os.Stdout
+	stdout := newLineRedactingWriter(os.Stdout)
+	defer stdout.Flush()
+	cmd.Stdout = stdout
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	cmd.Dir = dir
 
-	err := cmd.Run()
+	err := runInstrumentedCommand(command, cmd.Run)
 	if err != nil {
-		return fmt.Errorf("%s: %s", err, stderr.String())
+		return fmt.Errorf("%s: %s", err, RedactString(stderr.String()))
 	}
 	return nil
 }
@@ -180,7 +195,11 @@ func GetCommandWithArgsInDirectoryOutput(ctx context.Context, dir string, comman
 	cmd.Env = os.Environ()
 	cmd.Dir = dir
 
-	output, err := cmd.CombinedOutput()
+	var output []byte
+	err := runInstrumentedCommand(command, func() (err error) {
+		output, err = cmd.CombinedOutput()
+		return err
+	})
 
 	return string(output), err
 }
@@ -225,10 +244,13 @@ func RunCommandWithArgsWithoutLog(ctx context.Context, command string, args []st
 func RunCommandWithArgsExtendedWithoutLog(ctx context.Context, command string, args []string) error {
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Env = os.Environ()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := newLineRedactingWriter(os.Stdout), newLineRedactingWriter(os.Stderr)
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err := runInstrumentedCommand(command, cmd.Run)
 
 	return err
 }
@@ -0,0 +1,59 @@
+package foundation
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	commandDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "command_duration_seconds",
+		Help:    "Duration of commands run by the RunCommand and GetCommand functions.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"command", "exit_code"})
+
+	commandExecutionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "command_executions_total",
+		Help: "Total number of commands run by the RunCommand and GetCommand functions.",
+	}, []string{"command", "status"})
+)
+
+// registerCommandMetrics exposes command_duration_seconds and command_executions_total
+func registerCommandMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, commandDurationSeconds)
+	registerCollector(registerer, commandExecutionsTotal)
+}
+
+// runInstrumentedCommand runs the command with run and records its duration and outcome, labeled with the name of the
+// executable, like kubectl
+func runInstrumentedCommand(command string, run func() error) error {
+	start := currentClock().Now()
+	err := run()
+	duration := currentClock().Since(start)
+
+	exitCode, status := commandExitCode(err)
+	name := filepath.Base(command)
+
+	commandDurationSeconds.WithLabelValues(name, exitCode).Observe(duration.Seconds())
+	commandExecutionsTotal.WithLabelValues(name, status).Inc()
+
+	return err
+}
+
+// commandExitCode returns the exit code, or "none" if the command didn't start or got killed, and whether it succeeded
+func commandExitCode(err error) (exitCode, status string) {
+	if err == nil {
+		return "0", "succeeded"
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) && exitError.ExitCode() >= 0 {
+		return strconv.Itoa(exitError.ExitCode()), "failed"
+	}
+
+	return "none", "failed"
+}
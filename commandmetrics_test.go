@@ -0,0 +1,52 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInstrumentedCommand(t *testing.T) {
+	t.Run("CountsSucceededAndFailedCommands", func(t *testing.T) {
+		succeededBefore := testutil.ToFloat64(commandExecutionsTotal.WithLabelValues("true", "succeeded"))
+		failedBefore := testutil.ToFloat64(commandExecutionsTotal.WithLabelValues("false", "failed"))
+
+		// act
+		_ = RunCommandWithArgsExtended(context.Background(), "/bin/true", []string{})
+		_, _ = GetCommandWithArgsOutput(context.Background(), "false", []string{})
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(commandExecutionsTotal.WithLabelValues("true", "succeeded"))-succeededBefore)
+		assert.Equal(t, float64(1), testutil.ToFloat64(commandExecutionsTotal.WithLabelValues("false", "failed"))-failedBefore)
+	})
+}
+
+func TestCommandExitCode(t *testing.T) {
+	t.Run("ReturnsZeroForSuccess", func(t *testing.T) {
+		// act
+		exitCode, status := commandExitCode(nil)
+
+		assert.Equal(t, "0", exitCode)
+		assert.Equal(t, "succeeded", status)
+	})
+
+	t.Run("ReturnsExitCodeOfFailedCommand", func(t *testing.T) {
+		err := RunCommandWithArgsExtended(context.Background(), "sh", []string{"-c", "exit 3"})
+
+		// act
+		exitCode, status := commandExitCode(err)
+
+		assert.Equal(t, "3", exitCode)
+		assert.Equal(t, "failed", status)
+	})
+
+	t.Run("ReturnsNoneIfCommandDidNotRun", func(t *testing.T) {
+		// act
+		exitCode, status := commandExitCode(errors.New("exec: not found"))
+
+		assert.Equal(t, "none", exitCode)
+		assert.Equal(t, "failed", status)
+	})
+}
@@ -0,0 +1,139 @@
+package foundation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog"
+)
+
+// ConsoleTheme configures how the console log format renders events; the zero value renders neither timestamp nor
+// level, like the console format always did
+type ConsoleTheme struct {
+	// Timestamp renders the time of each event in TimeFormat, time.Kitchen if empty
+	Timestamp  bool
+	TimeFormat string
+
+	// Level renders the level of each event, in the ansi color code from LevelColors or the default color for the level
+	Level       bool
+	LevelColors map[zerolog.Level]int
+}
+
+// WithConsoleTheme customizes the console log format, for example WithConsoleTheme(ConsoleTheme{Timestamp: true,
+// Level: true}) for local debugging; setting envvars ESTAFETTE_LOG_CONSOLE_TIMESTAMP=true or
+// ESTAFETTE_LOG_CONSOLE_LEVEL=true does the same without changing code
+func WithConsoleTheme(theme ConsoleTheme) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.ConsoleTheme = theme
+	}
+}
+
+// consoleThemeFromEnv returns the console theme with the timestamp and level enabled by envvars
+func consoleThemeFromEnv() ConsoleTheme {
+	return ConsoleTheme{
+		Timestamp: strings.ToLower(os.Getenv("ESTAFETTE_LOG_CONSOLE_TIMESTAMP")) == "true",
+		Level:     strings.ToLower(os.Getenv("ESTAFETTE_LOG_CONSOLE_LEVEL")) == "true",
+	}
+}
+
+var defaultConsoleLevelColors = map[zerolog.Level]int{
+	zerolog.TraceLevel: 35, // magenta
+	zerolog.DebugLevel: 33, // yellow
+	zerolog.InfoLevel:  32, // green
+	zerolog.WarnLevel:  31, // red
+	zerolog.ErrorLevel: 31,
+	zerolog.FatalLevel: 31,
+	zerolog.PanicLevel: 31,
+}
+
+// format converts the json log events to plain text, colorized if the output is a terminal
+func (t ConsoleTheme) format(output io.Writer) io.Writer {
+	consoleWriter := zerolog.ConsoleWriter{
+		Out:        newRedactingWriter(output),
+		NoColor:    !consoleColorEnabled(output),
+		TimeFormat: t.TimeFormat,
+	}
+	if consoleWriter.TimeFormat == "" {
+		consoleWriter.TimeFormat = time.Kitchen
+	}
+
+	if !t.Timestamp {
+		consoleWriter.FormatTimestamp = func(i interface{}) string {
+			return ""
+		}
+	}
+
+	if t.Level {
+		consoleWriter.FormatLevel = func(i interface{}) string {
+			return t.formatLevel(i, consoleWriter.NoColor)
+		}
+	} else {
+		consoleWriter.FormatLevel = func(i interface{}) string {
+			return ""
+		}
+	}
+
+	return consoleWriter
+}
+
+// formatLevel renders the level as three uppercase letters, like INF for info
+func (t ConsoleTheme) formatLevel(i interface{}, noColor bool) string {
+	levelString, ok := i.(string)
+	if !ok {
+		return "???"
+	}
+
+	level, err := zerolog.ParseLevel(levelString)
+	if err != nil {
+		return strings.ToUpper(levelString)
+	}
+
+	abbreviations := map[zerolog.Level]string{
+		zerolog.TraceLevel: "TRC",
+		zerolog.DebugLevel: "DBG",
+		zerolog.InfoLevel:  "INF",
+		zerolog.WarnLevel:  "WRN",
+		zerolog.ErrorLevel: "ERR",
+		zerolog.FatalLevel: "FTL",
+		zerolog.PanicLevel: "PNC",
+	}
+	abbreviation, ok := abbreviations[level]
+	if !ok {
+		return strings.ToUpper(levelString)
+	}
+
+	if noColor {
+		return abbreviation
+	}
+
+	color, ok := t.LevelColors[level]
+	if !ok {
+		color = defaultConsoleLevelColors[level]
+	}
+
+	return fmt.Sprintf("\x1b[%dm%v\x1b[0m", color, abbreviation)
+}
+
+// consoleColorEnabled follows the NO_COLOR and FORCE_COLOR conventions and otherwise only colorizes terminals
+func consoleColorEnabled(output io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	switch strings.ToLower(os.Getenv("FORCE_COLOR")) {
+	case "", "0", "false":
+	default:
+		return true
+	}
+
+	file, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
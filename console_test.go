@@ -0,0 +1,71 @@
+package foundation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleTheme(t *testing.T) {
+	t.Run("RendersOnlyMessageAndFieldsByDefault", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("FORCE_COLOR", "")
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatConsole, WithLogWriter(&buffer))
+
+		// act
+		logger.Warn().Str("attempt", "2").Msg("Reconnecting")
+
+		assert.Equal(t, "Reconnecting attempt=2\n", buffer.String())
+	})
+
+	t.Run("RendersLevelIfEnabled", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("FORCE_COLOR", "")
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatConsole, WithLogWriter(&buffer), WithConsoleTheme(ConsoleTheme{Level: true}))
+
+		// act
+		logger.Warn().Msg("Reconnecting")
+
+		assert.Equal(t, "WRN Reconnecting\n", buffer.String())
+	})
+
+	t.Run("RendersTimestampIfEnabled", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("FORCE_COLOR", "")
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatConsole, WithLogWriter(&buffer), WithConsoleTheme(ConsoleTheme{Timestamp: true, TimeFormat: "2006"}))
+
+		// act
+		logger.Info().Msg("Hello")
+
+		assert.Regexp(t, `^\d{4} Hello\n$`, buffer.String())
+	})
+
+	t.Run("ColorizesLevelWithThemeColorIfForced", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("FORCE_COLOR", "1")
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatConsole, WithLogWriter(&buffer), WithConsoleTheme(ConsoleTheme{Level: true, LevelColors: map[zerolog.Level]int{zerolog.WarnLevel: 36}}))
+
+		// act
+		logger.Warn().Msg("Reconnecting")
+
+		assert.Contains(t, buffer.String(), "\x1b[36mWRN\x1b[0m")
+	})
+
+	t.Run("DoesNotColorizeIfNoColorIsSet", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		t.Setenv("FORCE_COLOR", "1")
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatConsole, WithLogWriter(&buffer), WithConsoleTheme(ConsoleTheme{Level: true}))
+
+		// act
+		logger.Error().Msg("Failed")
+
+		assert.NotContains(t, buffer.String(), "\x1b[")
+	})
+}
@@ -0,0 +1,133 @@
+package foundation
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CORSConfig is used to configure the CORS middleware
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests; use * to allow all origins or *.example.com to allow all subdomains
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in cross-origin requests
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in cross-origin requests
+	AllowedHeaders []string
+	// AllowCredentials allows cookies and authorization headers to be sent in cross-origin requests; it can't be combined
+	// with allowing all origins
+	AllowCredentials bool
+	// MaxAge sets for how long browsers can cache the result of a preflight request
+	MaxAge time.Duration
+}
+
+// NewCORSConfigFromEnv returns a CORSConfig filled from the envvars ESTAFETTE_CORS_ALLOWED_ORIGINS, ESTAFETTE_CORS_ALLOWED_METHODS,
+// ESTAFETTE_CORS_ALLOWED_HEADERS (all comma separated), ESTAFETTE_CORS_ALLOW_CREDENTIALS and ESTAFETTE_CORS_MAX_AGE (a duration like 10m)
+func NewCORSConfigFromEnv() CORSConfig {
+	config := CORSConfig{
+		AllowedOrigins: splitCommaSeparated(os.Getenv("ESTAFETTE_CORS_ALLOWED_ORIGINS")),
+		AllowedMethods: splitCommaSeparated(os.Getenv("ESTAFETTE_CORS_ALLOWED_METHODS")),
+		AllowedHeaders: splitCommaSeparated(os.Getenv("ESTAFETTE_CORS_ALLOWED_HEADERS")),
+	}
+
+	config.AllowCredentials, _ = strconv.ParseBool(os.Getenv("ESTAFETTE_CORS_ALLOW_CREDENTIALS"))
+	config.MaxAge, _ = time.ParseDuration(os.Getenv("ESTAFETTE_CORS_MAX_AGE"))
+
+	return config
+}
+
+// Validate returns an error if the config allows credentials for all origins, which would let any website make
+// authenticated requests on behalf of its visitors
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials && StringArrayContains(c.AllowedOrigins, "*") {
+		return errors.New("Cors config can't allow credentials for all origins, list the allowed origins instead of *")
+	}
+
+	return nil
+}
+
+// CORS returns a middleware that sets the cross-origin resource sharing headers for allowed origins and answers preflight
+// requests; it logs a fatal if the config is invalid
+func CORS(config CORSConfig) Middleware {
+	if err := config.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Creating cors middleware failed")
+	}
+
+	if len(config.AllowedMethods) == 0 {
+		config.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if len(config.AllowedHeaders) == 0 {
+		config.AllowedHeaders = []string{"Accept", "Authorization", "Content-Type", RequestIDHeader}
+	}
+
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" || !config.isOriginAllowed(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if StringArrayContains(config.AllowedOrigins, "*") {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			// answer preflight requests without calling the wrapped handler
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+				if config.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c CORSConfig) isOriginAllowed(origin string) bool {
+	for _, allowedOrigin := range c.AllowedOrigins {
+		if allowedOrigin == "*" || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+
+		// support wildcard subdomains like https://*.example.com
+		if i := strings.Index(allowedOrigin, "*"); i >= 0 {
+			prefix, suffix := allowedOrigin[:i], allowedOrigin[i+1:]
+			if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitCommaSeparated splits a comma separated string into its trimmed non-empty values
+func splitCommaSeparated(value string) (values []string) {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return
+}
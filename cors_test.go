@@ -0,0 +1,98 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+
+	t.Run("SetsAllowOriginForAllowedOrigin", func(t *testing.T) {
+
+		handler := CORS(CORSConfig{AllowedOrigins: []string{"https://dashboard.estafette.io"}})(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Origin", "https://dashboard.estafette.io")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "https://dashboard.estafette.io", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("DoesNotSetAllowOriginForOtherOrigin", func(t *testing.T) {
+
+		handler := CORS(CORSConfig{AllowedOrigins: []string{"https://*.estafette.io"}})(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Origin", "https://evil.example.com")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "", recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("AnswersPreflightRequest", func(t *testing.T) {
+
+		handler := CORS(CORSConfig{AllowedOrigins: []string{"https://*.estafette.io"}, AllowCredentials: true, MaxAge: 10 * time.Minute})(http.NotFoundHandler())
+		request := httptest.NewRequest("OPTIONS", "/", nil)
+		request.Header.Set("Origin", "https://dashboard.estafette.io")
+		request.Header.Set("Access-Control-Request-Method", "POST")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Equal(t, "https://dashboard.estafette.io", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+		assert.Contains(t, recorder.Header().Get("Access-Control-Allow-Methods"), "POST")
+	})
+}
+
+func TestNewCORSConfigFromEnv(t *testing.T) {
+
+	t.Run("ReturnsConfigFromEnvvars", func(t *testing.T) {
+
+		t.Setenv("ESTAFETTE_CORS_ALLOWED_ORIGINS", "https://a.estafette.io, https://b.estafette.io")
+		t.Setenv("ESTAFETTE_CORS_ALLOW_CREDENTIALS", "true")
+		t.Setenv("ESTAFETTE_CORS_MAX_AGE", "1h")
+
+		// act
+		config := NewCORSConfigFromEnv()
+
+		assert.Equal(t, []string{"https://a.estafette.io", "https://b.estafette.io"}, config.AllowedOrigins)
+		assert.True(t, config.AllowCredentials)
+		assert.Equal(t, time.Hour, config.MaxAge)
+	})
+}
+
+func TestCORSConfigValidate(t *testing.T) {
+
+	t.Run("ReturnsErrorForCredentialsWithAllOrigins", func(t *testing.T) {
+
+		config := CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+
+		// act
+		err := config.Validate()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsNilForCredentialsWithListedOrigins", func(t *testing.T) {
+
+		config := CORSConfig{AllowedOrigins: []string{"https://*.estafette.io"}, AllowCredentials: true}
+
+		// act
+		err := config.Validate()
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,205 @@
+package foundation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Credential is a credential estafette injects into extension stages, with type specific fields in AdditionalProperties
+type Credential struct {
+	Name                 string                 `json:"name"`
+	Type                 string                 `json:"type"`
+	AdditionalProperties map[string]interface{} `json:"additionalProperties,omitempty"`
+}
+
+// CredentialsConfig configures where credentials are read from and how encrypted values are decrypted
+type CredentialsConfig struct {
+	Path          string
+	DecryptionKey string
+}
+
+// CredentialsOption allows to override credentials config
+type CredentialsOption func(*CredentialsConfig)
+
+// WithCredentialsPath sets the directory containing the <type>.json credentials files, used when the
+// ESTAFETTE_CREDENTIALS_<TYPE> envvar isn't set
+// default is /credentials
+func WithCredentialsPath(path string) CredentialsOption {
+	return func(c *CredentialsConfig) {
+		c.Path = path
+	}
+}
+
+// WithDecryptionKey sets the key to decrypt values still in estafette.secret(...) form
+// default is envvar ESTAFETTE_SECRET_DECRYPTION_KEY
+func WithDecryptionKey(key string) CredentialsOption {
+	return func(c *CredentialsConfig) {
+		c.DecryptionKey = key
+	}
+}
+
+var estafetteSecretRegex = regexp.MustCompile(`^estafette\.secret\(([a-zA-Z0-9.=_-]+)\)$`)
+
+// GetCredentials returns the credentials of the given type, read from envvar ESTAFETTE_CREDENTIALS_<UPPER_SNAKE_TYPE>
+// or else from file <path>/<lower_snake_type>.json. Encrypted values get decrypted, and values of secret looking fields
+// and decrypted values are masked in log and command output.
+func GetCredentials(credentialType string, opts ...CredentialsOption) ([]Credential, error) {
+	// default
+	config := CredentialsConfig{
+		Path:          "/credentials",
+		DecryptionKey: os.Getenv("ESTAFETTE_SECRET_DECRYPTION_KEY"),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	envvarName := "ESTAFETTE_CREDENTIALS_" + ToUpperSnakeCase(credentialType)
+	credentialsJSON := os.Getenv(envvarName)
+	if credentialsJSON == "" {
+		filePath := filepath.Join(config.Path, ToLowerSnakeCase(credentialType)+".json")
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("Credentials of type %v are not set in envvar %v and reading file %v failed: %w", credentialType, envvarName, filePath, err)
+		}
+		credentialsJSON = string(content)
+	}
+
+	var credentials []Credential
+	if err := json.Unmarshal([]byte(credentialsJSON), &credentials); err != nil {
+		return nil, fmt.Errorf("Unmarshalling credentials of type %v failed: %w", credentialType, err)
+	}
+
+	for _, credential := range credentials {
+		for key, value := range credential.AdditionalProperties {
+			decrypted, err := decryptCredentialValue(key, value, config.DecryptionKey)
+			if err != nil {
+				return nil, fmt.Errorf("Decrypting field %v of credential %v failed: %w", key, credential.Name, err)
+			}
+			credential.AdditionalProperties[key] = decrypted
+		}
+	}
+
+	return credentials, nil
+}
+
+// GetCredential looks up the credential of the given type by name and unmarshals it into target, a pointer to a typed
+// struct with fields for name, type and the additional properties
+func GetCredential(credentialType, name string, target interface{}, opts ...CredentialsOption) error {
+	credentials, err := GetCredentials(credentialType, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, credential := range credentials {
+		if credential.Name != name {
+			continue
+		}
+
+		// flatten so the target doesn't need to model the additionalProperties nesting
+		fields := map[string]interface{}{}
+		for key, value := range credential.AdditionalProperties {
+			fields[key] = value
+		}
+		fields["name"] = credential.Name
+		fields["type"] = credential.Type
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(data, target)
+	}
+
+	return fmt.Errorf("Credential %v of type %v not found", name, credentialType)
+}
+
+// decryptCredentialValue decrypts estafette.secret(...) strings, recursing into nested values, and registers secret
+// looking and decrypted string values for masking
+func decryptCredentialValue(key string, value interface{}, decryptionKey string) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case string:
+		if matches := estafetteSecretRegex.FindStringSubmatch(typedValue); len(matches) == 2 {
+			decrypted, err := decryptEstafetteSecret(matches[1], decryptionKey)
+			if err != nil {
+				return nil, err
+			}
+			RedactSecrets(decrypted)
+			return decrypted, nil
+		}
+		if secretKeyRegex.MatchString(key) {
+			RedactSecrets(typedValue)
+		}
+		return typedValue, nil
+	case map[string]interface{}:
+		for k, v := range typedValue {
+			decrypted, err := decryptCredentialValue(k, v, decryptionKey)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[k] = decrypted
+		}
+		return typedValue, nil
+	case []interface{}:
+		for i, v := range typedValue {
+			decrypted, err := decryptCredentialValue(key, v, decryptionKey)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[i] = decrypted
+		}
+		return typedValue, nil
+	}
+
+	return value, nil
+}
+
+// decryptEstafetteSecret decrypts the nonce.ciphertext content of an estafette.secret(...) value using aes-256-gcm
+func decryptEstafetteSecret(encrypted, key string) (string, error) {
+	if len(key) != 32 {
+		return "", fmt.Errorf("Decryption key should be 32 bytes, got %v", len(key))
+	}
+
+	parts := strings.Split(encrypted, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("Encrypted value should consist of a nonce and ciphertext")
+	}
+
+	nonce, err := base64.URLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.URLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return "", err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != aesGCM.NonceSize() {
+		return "", fmt.Errorf("Nonce should be %v bytes, got %v", aesGCM.NonceSize(), len(nonce))
+	}
+
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,114 @@
+package foundation
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testKubernetesEngineCredential struct {
+	Name           string `json:"name"`
+	Project        string `json:"project"`
+	ServiceAccount string `json:"serviceAccountKeyfile"`
+}
+
+func encryptTestSecret(t *testing.T, value, key string) string {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	ciphertext := aesGCM.Seal(nil, nonce, []byte(value), nil)
+
+	return fmt.Sprintf("estafette.secret(%v.%v)", base64.URLEncoding.EncodeToString(nonce), base64.URLEncoding.EncodeToString(ciphertext))
+}
+
+func TestGetCredentials(t *testing.T) {
+	t.Run("ReadsCredentialsFromEnvvar", func(t *testing.T) {
+		resetSecrets(t)
+		t.Setenv("ESTAFETTE_CREDENTIALS_KUBERNETES_ENGINE", `[{"name":"gke-prd","type":"kubernetes-engine","additionalProperties":{"project":"prd","serviceAccountKeyfile":"{}"}}]`)
+
+		// act
+		credentials, err := GetCredentials("kubernetes-engine")
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(credentials)) {
+			assert.Equal(t, "gke-prd", credentials[0].Name)
+			assert.Equal(t, "prd", credentials[0].AdditionalProperties["project"])
+		}
+	})
+
+	t.Run("ReadsCredentialsFromFile", func(t *testing.T) {
+		resetSecrets(t)
+		t.Setenv("ESTAFETTE_CREDENTIALS_KUBERNETES_ENGINE", "")
+		dir := t.TempDir()
+		err := ioutil.WriteFile(filepath.Join(dir, "kubernetes_engine.json"), []byte(`[{"name":"gke-prd","type":"kubernetes-engine"}]`), 0600)
+		assert.Nil(t, err)
+
+		// act
+		credentials, err := GetCredentials("kubernetes-engine", WithCredentialsPath(dir))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(credentials))
+	})
+
+	t.Run("DecryptsAndMasksEncryptedValues", func(t *testing.T) {
+		resetSecrets(t)
+		key := "abcdefghijklmnopqrstuvwxyz012345"
+		t.Setenv("ESTAFETTE_CREDENTIALS_CONTAINER_REGISTRY", fmt.Sprintf(`[{"name":"dockerhub","type":"container-registry","additionalProperties":{"username":"estafette","secret":"%v"}}]`, encryptTestSecret(t, "hunter2", key)))
+
+		// act
+		credentials, err := GetCredentials("container-registry", WithDecryptionKey(key))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "hunter2", credentials[0].AdditionalProperties["secret"])
+		assert.Equal(t, "*** estafette", RedactString("hunter2 estafette"))
+	})
+
+	t.Run("ReturnsErrorIfEncryptedValueCannotBeDecrypted", func(t *testing.T) {
+		resetSecrets(t)
+		t.Setenv("ESTAFETTE_CREDENTIALS_CONTAINER_REGISTRY", fmt.Sprintf(`[{"name":"dockerhub","additionalProperties":{"password":"%v"}}]`, encryptTestSecret(t, "hunter2", "abcdefghijklmnopqrstuvwxyz012345")))
+
+		// act
+		_, err := GetCredentials("container-registry", WithDecryptionKey("01234567890123456789012345678901"))
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetCredential(t *testing.T) {
+	t.Run("UnmarshalsNamedCredentialIntoTypedStruct", func(t *testing.T) {
+		resetSecrets(t)
+		t.Setenv("ESTAFETTE_CREDENTIALS_KUBERNETES_ENGINE", `[{"name":"gke-dev","additionalProperties":{"project":"dev"}},{"name":"gke-prd","additionalProperties":{"project":"prd","serviceAccountKeyfile":"{\"private_key\":\"abc\"}"}}]`)
+		var credential testKubernetesEngineCredential
+
+		// act
+		err := GetCredential("kubernetes-engine", "gke-prd", &credential)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "gke-prd", credential.Name)
+		assert.Equal(t, "prd", credential.Project)
+		assert.Equal(t, "***", RedactString(credential.ServiceAccount))
+	})
+
+	t.Run("ReturnsErrorIfNotFound", func(t *testing.T) {
+		resetSecrets(t)
+		t.Setenv("ESTAFETTE_CREDENTIALS_KUBERNETES_ENGINE", `[{"name":"gke-dev"}]`)
+		var credential testKubernetesEngineCredential
+
+		// act
+		err := GetCredential("kubernetes-engine", "gke-prd", &credential)
+
+		assert.NotNil(t, err)
+	})
+}
@@ -1,6 +1,11 @@
 package foundation
 
-import "runtime"
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
 
 // ApplicationInfo contains basic information about an application
 type ApplicationInfo struct {
@@ -22,6 +27,79 @@ func (ai *ApplicationInfo) GoVersion() string {
 	return runtime.Version()
 }
 
+// Runtime returns the Kubernetes runtime information the application is running with, read from downward-API envvars
+func (ai *ApplicationInfo) Runtime() RuntimeInfo {
+	return NewRuntimeInfoFromEnv()
+}
+
+// Validate returns the names of the fields that are empty or still contain a placeholder value, which usually means the
+// build forgot to inject them
+func (ai *ApplicationInfo) Validate() (invalidFields []string) {
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"appGroup", ai.AppGroup},
+		{"app", ai.App},
+		{"version", ai.Version},
+		{"branch", ai.Branch},
+		{"revision", ai.Revision},
+		{"buildDate", ai.BuildDate},
+	} {
+		if isPlaceholderValue(field.value) {
+			invalidFields = append(invalidFields, field.name)
+		}
+	}
+
+	return
+}
+
+// String returns a single line description of the application
+func (ai ApplicationInfo) String() string {
+	return fmt.Sprintf("%v version %v (branch %v, revision %v, built %v)", ai.App, ai.Version, ai.Branch, ai.Revision, ai.BuildDate)
+}
+
+// Banner returns the multi-line startup banner for the application
+func (ai *ApplicationInfo) Banner() string {
+	lines := []string{
+		fmt.Sprintf("Starting %v version %v...", ai.App, ai.Version),
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"appgroup", ai.AppGroup},
+		{"branch", ai.Branch},
+		{"revision", ai.Revision},
+		{"buildDate", ai.BuildDate},
+		{"goVersion", ai.GoVersion()},
+		{"os", ai.OperatingSystem()},
+	} {
+		if field.value == "" {
+			field.value = "-"
+		}
+		lines = append(lines, fmt.Sprintf("  %-10v %v", field.name+":", field.value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isPlaceholderValue returns true for empty values and values commonly used as a default for build information
+func isPlaceholderValue(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "$") {
+		return true
+	}
+
+	switch strings.ToLower(value) {
+	case "unknown", "undefined", "n/a", "none", "<nil>", "null":
+		return true
+	}
+
+	return false
+}
+
 // NewApplicationInfo returns an ApplicationInfo object
 func NewApplicationInfo(appgroup, app, version, branch, revision, buildDate string) ApplicationInfo {
 	return ApplicationInfo{
@@ -33,3 +111,50 @@ func NewApplicationInfo(appgroup, app, version, branch, revision, buildDate stri
 		BuildDate: buildDate,
 	}
 }
+
+// RuntimeInfo contains information about the Kubernetes pod and container an application is running in
+type RuntimeInfo struct {
+	PodName        string `json:"podName,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	NodeName       string `json:"nodeName,omitempty"`
+	ContainerName  string `json:"containerName,omitempty"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	CPULimit       string `json:"cpuLimit,omitempty"`
+	MemoryLimit    string `json:"memoryLimit,omitempty"`
+}
+
+// NewRuntimeInfoFromEnv returns a RuntimeInfo object filled from the envvars POD_NAME, POD_NAMESPACE, NODE_NAME, CONTAINER_NAME,
+// SERVICE_ACCOUNT, CPU_LIMIT and MEMORY_LIMIT, which can be set with the Kubernetes downward API
+func NewRuntimeInfoFromEnv() RuntimeInfo {
+	return RuntimeInfo{
+		PodName:        os.Getenv("POD_NAME"),
+		Namespace:      os.Getenv("POD_NAMESPACE"),
+		NodeName:       os.Getenv("NODE_NAME"),
+		ContainerName:  os.Getenv("CONTAINER_NAME"),
+		ServiceAccount: os.Getenv("SERVICE_ACCOUNT"),
+		CPULimit:       os.Getenv("CPU_LIMIT"),
+		MemoryLimit:    os.Getenv("MEMORY_LIMIT"),
+	}
+}
+
+// IsAvailable returns true if any of the runtime information has been provided
+func (ri RuntimeInfo) IsAvailable() bool {
+	return ri.PodName != "" || ri.Namespace != "" || ri.NodeName != "" || ri.ContainerName != "" || ri.ServiceAccount != ""
+}
+
+// Labels returns the non-empty pod, namespace, node, container and service account values keyed by their field name
+func (ri RuntimeInfo) Labels() map[string]string {
+	labels := map[string]string{}
+	for key, value := range map[string]string{
+		"pod":            ri.PodName,
+		"namespace":      ri.Namespace,
+		"node":           ri.NodeName,
+		"container":      ri.ContainerName,
+		"serviceAccount": ri.ServiceAccount,
+	} {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,103 @@
+package foundation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRuntimeInfoFromEnv(t *testing.T) {
+
+	t.Run("ReturnsValuesFromDownwardApiEnvvars", func(t *testing.T) {
+
+		t.Setenv("POD_NAME", "myapp-5f7b9c-x2x4z")
+		t.Setenv("POD_NAMESPACE", "mynamespace")
+		t.Setenv("NODE_NAME", "gke-node-1")
+		t.Setenv("SERVICE_ACCOUNT", "myapp")
+		t.Setenv("CPU_LIMIT", "2")
+		t.Setenv("MEMORY_LIMIT", "536870912")
+
+		// act
+		runtimeInfo := NewRuntimeInfoFromEnv()
+
+		assert.Equal(t, "myapp-5f7b9c-x2x4z", runtimeInfo.PodName)
+		assert.Equal(t, "mynamespace", runtimeInfo.Namespace)
+		assert.Equal(t, "gke-node-1", runtimeInfo.NodeName)
+		assert.Equal(t, "myapp", runtimeInfo.ServiceAccount)
+		assert.Equal(t, "2", runtimeInfo.CPULimit)
+		assert.Equal(t, "536870912", runtimeInfo.MemoryLimit)
+		assert.True(t, runtimeInfo.IsAvailable())
+	})
+
+	t.Run("ReturnsNotAvailableIfEnvvarsAreNotSet", func(t *testing.T) {
+
+		t.Setenv("POD_NAME", "")
+		t.Setenv("POD_NAMESPACE", "")
+		t.Setenv("NODE_NAME", "")
+		t.Setenv("CONTAINER_NAME", "")
+		t.Setenv("SERVICE_ACCOUNT", "")
+
+		// act
+		runtimeInfo := NewRuntimeInfoFromEnv()
+
+		assert.False(t, runtimeInfo.IsAvailable())
+	})
+}
+
+func TestRuntimeInfoLabels(t *testing.T) {
+
+	t.Run("ReturnsOnlyNonEmptyValues", func(t *testing.T) {
+
+		runtimeInfo := RuntimeInfo{
+			PodName:   "myapp-5f7b9c-x2x4z",
+			Namespace: "mynamespace",
+		}
+
+		// act
+		labels := runtimeInfo.Labels()
+
+		assert.Equal(t, map[string]string{"pod": "myapp-5f7b9c-x2x4z", "namespace": "mynamespace"}, labels)
+	})
+}
+
+func TestApplicationInfoValidate(t *testing.T) {
+
+	t.Run("ReturnsNoFieldsIfAllAreSet", func(t *testing.T) {
+
+		applicationInfo := NewApplicationInfo("estafette", "myapp", "1.0.0", "main", "a1b2c3d", "2020-01-01T00:00:00Z")
+
+		// act
+		invalidFields := applicationInfo.Validate()
+
+		assert.Empty(t, invalidFields)
+	})
+
+	t.Run("ReturnsEmptyAndPlaceholderFields", func(t *testing.T) {
+
+		applicationInfo := NewApplicationInfo("estafette", "myapp", "1.0.0", "unknown", "", "${ESTAFETTE_BUILD_DATETIME}")
+
+		// act
+		invalidFields := applicationInfo.Validate()
+
+		assert.Equal(t, []string{"branch", "revision", "buildDate"}, invalidFields)
+	})
+}
+
+func TestApplicationInfoBanner(t *testing.T) {
+
+	t.Run("ReturnsMultiLineBannerStartingWithAppAndVersion", func(t *testing.T) {
+
+		applicationInfo := NewApplicationInfo("estafette", "myapp", "1.0.0", "main", "", "2020-01-01T00:00:00Z")
+
+		// act
+		banner := applicationInfo.Banner()
+
+		lines := strings.Split(banner, "\n")
+		if assert.Equal(t, 7, len(lines)) {
+			assert.Equal(t, "Starting myapp version 1.0.0...", lines[0])
+			assert.Equal(t, "  appgroup:  estafette", lines[1])
+			assert.Equal(t, "  revision:  -", lines[3])
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package foundation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// WithDuplicateSuppression collapses identical messages logged in a row within window into the first one, followed by
+// a "Last message repeated N times" message once a different message is logged or the window has passed, so noisy
+// loops like reconnects don't blow out the log quota; fatal and panic messages are never suppressed
+func WithDuplicateSuppression(window time.Duration) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.DuplicateSuppressionWindow = window
+	}
+}
+
+// duplicateSuppressionHook discards events with the same level and message as the previous one within the window and
+// logs how many were discarded with the logger it's added to, without the hook
+type duplicateSuppressionHook struct {
+	logger zerolog.Logger
+	window time.Duration
+
+	mutex       sync.Mutex
+	lastLevel   zerolog.Level
+	lastMessage string
+	windowStart time.Time
+	repeated    int
+	flushing    bool
+}
+
+// suppressDuplicates returns the logger with the duplicate suppression hook added
+func suppressDuplicates(logger zerolog.Logger, window time.Duration) zerolog.Logger {
+	return logger.Hook(&duplicateSuppressionHook{
+		logger:    logger,
+		window:    window,
+		lastLevel: zerolog.NoLevel,
+	})
+}
+
+func (h *duplicateSuppressionHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level == zerolog.FatalLevel || level == zerolog.PanicLevel {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := currentClock().Now()
+	if level == h.lastLevel && msg == h.lastMessage && now.Sub(h.windowStart) < h.window {
+		h.repeated++
+		e.Discard()
+
+		// report the repetitions once the window has passed, in case no other message gets logged
+		if !h.flushing {
+			h.flushing = true
+			go func(after <-chan time.Time) {
+				<-after
+				h.mutex.Lock()
+				defer h.mutex.Unlock()
+				h.flushing = false
+				h.logRepeated()
+			}(currentClock().After(h.window - now.Sub(h.windowStart)))
+		}
+		return
+	}
+
+	h.logRepeated()
+
+	h.lastLevel = level
+	h.lastMessage = msg
+	h.windowStart = now
+}
+
+// logRepeated logs how many times the last message got discarded, if at all
+func (h *duplicateSuppressionHook) logRepeated() {
+	if h.repeated == 0 {
+		return
+	}
+
+	h.logger.WithLevel(h.lastLevel).
+		Int("repeated", h.repeated).
+		Msgf("Last message repeated %v times", h.repeated)
+
+	h.repeated = 0
+}
@@ -0,0 +1,67 @@
+package foundation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppressDuplicates(t *testing.T) {
+	t.Run("CollapsesRepeatedMessagesUntilDifferentMessage", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1600000000, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		var buffer lockedBuffer
+		logger := suppressDuplicates(zerolog.New(&buffer), time.Minute)
+
+		// act
+		for i := 0; i < 4; i++ {
+			logger.Warn().Msg("Reconnecting")
+		}
+		logger.Info().Msg("Connected")
+
+		assert.Equal(t, `{"level":"warn","message":"Reconnecting"}
+{"level":"warn","repeated":3,"message":"Last message repeated 3 times"}
+{"level":"info","message":"Connected"}
+`, buffer.String())
+	})
+
+	t.Run("ReportsRepetitionsOnceWindowHasPassed", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1600000000, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		var buffer lockedBuffer
+		logger := suppressDuplicates(zerolog.New(&buffer), time.Minute)
+		logger.Warn().Msg("Reconnecting")
+		logger.Warn().Msg("Reconnecting")
+		clock.BlockUntil(1)
+
+		// act
+		clock.Advance(time.Minute)
+
+		assert.Eventually(t, func() bool {
+			return buffer.String() == `{"level":"warn","message":"Reconnecting"}
+{"level":"warn","repeated":1,"message":"Last message repeated 1 times"}
+`
+		}, time.Second, time.Millisecond)
+
+		logger.Warn().Msg("Reconnecting")
+		assert.Contains(t, buffer.String(), `"repeated":1,"message":"Last message repeated 1 times"}
+{"level":"warn","message":"Reconnecting"}`)
+	})
+
+	t.Run("NeverSuppressesDifferentLevels", func(t *testing.T) {
+		var buffer lockedBuffer
+		logger := suppressDuplicates(zerolog.New(&buffer), time.Minute)
+
+		// act
+		logger.Info().Msg("Reconnecting")
+		logger.Warn().Msg("Reconnecting")
+
+		assert.Equal(t, `{"level":"info","message":"Reconnecting"}
+{"level":"warn","message":"Reconnecting"}
+`, buffer.String())
+	})
+}
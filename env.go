@@ -0,0 +1,130 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	secretKeyRegex     = regexp.MustCompile(`(?i)(PASS|SECRET|TOKEN|KEY|CREDENTIAL|DSN|AUTH|PRIVATE|COOKIE|SESSION)`)
+	secretKeySafeRegex = regexp.MustCompile(`(?i)_(FILE|PATH|DIR)$`)
+
+	envDumpMutex  sync.RWMutex
+	envDumpConfig *EnvDumpConfig
+)
+
+// EnvDumpConfig configures which envvars get logged at startup and served from /debug/env
+type EnvDumpConfig struct {
+	Prefixes []string
+}
+
+// EnvDumpOption allows to override env dump config
+type EnvDumpOption func(*EnvDumpConfig)
+
+// WithEnvPrefixes adds prefixes of envvars to include, for example application specific ones
+// default is ESTAFETTE_ and JAEGER_ plus the prefixes in envvar ESTAFETTE_ENV_DUMP_PREFIXES
+func WithEnvPrefixes(prefixes ...string) EnvDumpOption {
+	return func(c *EnvDumpConfig) {
+		c.Prefixes = append(c.Prefixes, prefixes...)
+	}
+}
+
+// DumpEnvironment logs all envvars with one of the configured prefixes, redacting values that look like secrets, and
+// enables the /debug/env endpoint on the liveness and readiness port serving the same
+func DumpEnvironment(opts ...EnvDumpOption) {
+	// default
+	config := EnvDumpConfig{
+		Prefixes: append([]string{"ESTAFETTE_", "JAEGER_"}, splitCommaSeparated(os.Getenv("ESTAFETTE_ENV_DUMP_PREFIXES"))...),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	envDumpMutex.Lock()
+	envDumpConfig = &config
+	envDumpMutex.Unlock()
+
+	environment := redactedEnvironment(config.Prefixes)
+
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dict := zerolog.Dict()
+	for _, key := range keys {
+		dict.Str(key, environment[key])
+	}
+
+	log.Info().
+		Dict("environment", dict).
+		Msgf("Environment contains %v variables with prefixes %v", len(environment), strings.Join(config.Prefixes, ", "))
+}
+
+// EnvHandler serves the envvars included by DumpEnvironment as json, with secret values redacted; it responds with a
+// 404 if DumpEnvironment hasn't been called
+func EnvHandler(w http.ResponseWriter, _ *http.Request) {
+	envDumpMutex.RLock()
+	config := envDumpConfig
+	envDumpMutex.RUnlock()
+
+	if config == nil {
+		http.NotFound(w, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(redactedEnvironment(config.Prefixes))
+}
+
+// redactedEnvironment returns the envvars starting with any of the prefixes with secret values redacted
+func redactedEnvironment(prefixes []string) map[string]string {
+	environment := map[string]string{}
+	for _, keyValue := range os.Environ() {
+		key, value, _ := strings.Cut(keyValue, "=")
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				environment[key] = redactEnvValue(key, value)
+				break
+			}
+		}
+	}
+
+	return environment
+}
+
+// redactEnvValue redacts the value if the key suggests it's a secret, if it's a pem encoded key or certificate, or the
+// password if it's a url with credentials
+func redactEnvValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretKeyRegex.MatchString(key) && !secretKeySafeRegex.MatchString(key) {
+		return redactedValue
+	}
+	if strings.Contains(value, "-----BEGIN ") {
+		return redactedValue
+	}
+	if parsedURL, err := url.Parse(value); err == nil && parsedURL.User != nil {
+		if _, hasPassword := parsedURL.User.Password(); hasPassword {
+			parsedURL.User = url.UserPassword(parsedURL.User.Username(), "xxxxx")
+			return parsedURL.String()
+		}
+	}
+
+	return value
+}
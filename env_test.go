@@ -0,0 +1,90 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactEnvValue(t *testing.T) {
+	t.Run("RedactsValueIfKeyLooksLikeSecret", func(t *testing.T) {
+		// act
+		value := redactEnvValue("ESTAFETTE_DB_PASSWORD", "hunter2")
+
+		assert.Equal(t, "[REDACTED]", value)
+	})
+
+	t.Run("KeepsValueIfKeyIsPathToSecret", func(t *testing.T) {
+		// act
+		value := redactEnvValue("ESTAFETTE_TLS_KEY_FILE", "/secrets/tls.key")
+
+		assert.Equal(t, "/secrets/tls.key", value)
+	})
+
+	t.Run("RedactsPemEncodedValues", func(t *testing.T) {
+		// act
+		value := redactEnvValue("ESTAFETTE_CERTIFICATE", "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----")
+
+		assert.Equal(t, "[REDACTED]", value)
+	})
+
+	t.Run("RedactsPasswordInURL", func(t *testing.T) {
+		// act
+		value := redactEnvValue("ESTAFETTE_DATABASE_URL", "postgres://user:hunter2@db:5432/app")
+
+		assert.Equal(t, "postgres://user:xxxxx@db:5432/app", value)
+	})
+
+	t.Run("KeepsOtherValues", func(t *testing.T) {
+		// act
+		value := redactEnvValue("ESTAFETTE_LOG_FORMAT", "json")
+
+		assert.Equal(t, "json", value)
+	})
+}
+
+func TestDumpEnvironment(t *testing.T) {
+	t.Run("ServesMatchingEnvvarsFromEnvHandler", func(t *testing.T) {
+		t.Setenv("MYAPP_API_TOKEN", "abc")
+		t.Setenv("MYAPP_REGION", "europe-west1")
+		t.Setenv("OTHER_SETTING", "value")
+		defer func() { envDumpConfig = nil }()
+		captureLogs(t)
+		DumpEnvironment(WithEnvPrefixes("MYAPP_"))
+		recorder := httptest.NewRecorder()
+
+		// act
+		EnvHandler(recorder, httptest.NewRequest(http.MethodGet, "/debug/env", nil))
+
+		var environment map[string]string
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &environment))
+		assert.Equal(t, "[REDACTED]", environment["MYAPP_API_TOKEN"])
+		assert.Equal(t, "europe-west1", environment["MYAPP_REGION"])
+		_, hasOther := environment["OTHER_SETTING"]
+		assert.False(t, hasOther)
+	})
+
+	t.Run("LogsMatchingEnvvars", func(t *testing.T) {
+		t.Setenv("MYAPP_API_TOKEN", "abc")
+		defer func() { envDumpConfig = nil }()
+		logs := captureLogs(t)
+
+		// act
+		DumpEnvironment(WithEnvPrefixes("MYAPP_"))
+
+		assert.Contains(t, logs.String(), `"MYAPP_API_TOKEN":"[REDACTED]"`)
+		assert.NotContains(t, logs.String(), "abc")
+	})
+
+	t.Run("EnvHandlerReturns404IfNotEnabled", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		// act
+		EnvHandler(recorder, httptest.NewRequest(http.MethodGet, "/debug/env", nil))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
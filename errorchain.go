@@ -0,0 +1,146 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// errorLink is an error in the chain of wrapped errors
+type errorLink struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func (l errorLink) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("message", l.Message).Str("type", l.Type)
+}
+
+type errorLinks []errorLink
+
+func (l errorLinks) MarshalZerologArray(a *zerolog.Array) {
+	for _, link := range l {
+		a.Object(link)
+	}
+}
+
+// errorWithChain logs an error as object with its message and the chain of errors it wraps
+type errorWithChain struct {
+	err   error
+	chain errorLinks
+}
+
+func (e errorWithChain) MarshalZerologObject(event *zerolog.Event) {
+	event.Str("message", e.err.Error()).Array("chain", e.chain)
+}
+
+// marshalErrorChain logs errors wrapping other errors, with fmt.Errorf("...: %w", err) or as multierror, as object with
+// the full chain, which errorChainWriter moves to the errorChain field; other errors are logged as string like before
+func marshalErrorChain(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+
+	chain := errorChain(err)
+	if len(chain) < 2 {
+		return err
+	}
+
+	return errorWithChain{err: err, chain: chain}
+}
+
+// errorChain returns the error and all errors it wraps, depth first for errors wrapping multiple errors
+func errorChain(err error) (chain errorLinks) {
+	if err == nil {
+		return nil
+	}
+
+	chain = append(chain, errorLink{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	})
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		wrapped := errorChain(wrapper.Unwrap())
+		if len(wrapped) > 0 && wrapped[0].Message == err.Error() {
+			// wrappers that only add context like a stack trace don't add to the chain
+			chain = chain[:0]
+		}
+		chain = append(chain, wrapped...)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range wrapper.Unwrap() {
+			chain = append(chain, errorChain(wrapped)...)
+		}
+	case interface{ WrappedErrors() []error }:
+		// multierrors from github.com/hashicorp/go-multierror
+		for _, wrapped := range wrapper.WrappedErrors() {
+			chain = append(chain, errorChain(wrapped)...)
+		}
+	}
+
+	return chain
+}
+
+// errorChainFieldName is the field errorChainWriter moves the chain of wrapped errors to
+const errorChainFieldName = "errorChain"
+
+// errorChainWriter moves the chain of wrapped errors from the error object written by marshalErrorChain to the
+// errorChain field, since zerolog can only marshal an error as a single field; this keeps the error field a string, so
+// log indexers don't reject events for having an error field of another type than before
+type errorChainWriter struct {
+	writer io.Writer
+}
+
+func newErrorChainWriter(writer io.Writer) zerolog.LevelWriter {
+	return &errorChainWriter{writer: writer}
+}
+
+func (w *errorChainWriter) Write(p []byte) (n int, err error) {
+	if _, err = w.writer.Write(moveErrorChain(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *errorChainWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if _, err = writeLevel(w.writer, level, moveErrorChain(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// moveErrorChain turns "error":{"message":"...","chain":[...]} in a json log event into
+// "error":"...","errorChain":[...]; events without an error chain are returned as is
+func moveErrorChain(p []byte) []byte {
+	key := []byte(`"` + zerolog.ErrorFieldName + `":`)
+	start := bytes.Index(p, append(key, `{"message":`...))
+	if start < 0 {
+		return p
+	}
+
+	var errWithChain struct {
+		Message json.RawMessage `json:"message"`
+		Chain   json.RawMessage `json:"chain"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(p[start+len(key):]))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&errWithChain); err != nil || errWithChain.Chain == nil {
+		return p
+	}
+	end := start + len(key) + int(decoder.InputOffset())
+
+	moved := make([]byte, 0, len(p))
+	moved = append(moved, p[:start]...)
+	moved = append(moved, key...)
+	moved = append(moved, errWithChain.Message...)
+	moved = append(moved, `,"`+errorChainFieldName+`":`...)
+	moved = append(moved, errWithChain.Chain...)
+
+	return append(moved, p[end:]...)
+}
@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type multiError []error
+
+func (m multiError) Error() string {
+	return fmt.Sprintf("%v errors occurred", len(m))
+}
+
+func (m multiError) WrappedErrors() []error {
+	return m
+}
+
+func TestErrorChain(t *testing.T) {
+	t.Run("ReturnsAllWrappedErrors", func(t *testing.T) {
+		err := fmt.Errorf("Loading config failed: %w", fmt.Errorf("Reading file failed: %w", errors.New("permission denied")))
+
+		// act
+		chain := errorChain(err)
+
+		assert.Equal(t, errorLinks{
+			{Message: "Loading config failed: Reading file failed: permission denied", Type: "*fmt.wrapError"},
+			{Message: "Reading file failed: permission denied", Type: "*fmt.wrapError"},
+			{Message: "permission denied", Type: "*errors.errorString"},
+		}, chain)
+	})
+
+	t.Run("ReturnsErrorsOfMultiError", func(t *testing.T) {
+		err := multiError{errors.New("first"), errors.New("second")}
+
+		// act
+		chain := errorChain(err)
+
+		assert.Equal(t, errorLinks{
+			{Message: "2 errors occurred", Type: "foundation.multiError"},
+			{Message: "first", Type: "*errors.errorString"},
+			{Message: "second", Type: "*errors.errorString"},
+		}, chain)
+	})
+
+	t.Run("SkipsWrappersWithoutOwnMessage", func(t *testing.T) {
+		err := pkgerrors.Wrap(errors.New("permission denied"), "Reading file failed")
+
+		// act
+		chain := errorChain(err)
+
+		assert.Equal(t, errorLinks{
+			{Message: "Reading file failed: permission denied", Type: "*errors.withMessage"},
+			{Message: "permission denied", Type: "*errors.errorString"},
+		}, chain)
+	})
+}
+
+func TestMarshalErrorChain(t *testing.T) {
+	t.Run("LogsWrappedErrorAsStringWithChainInSeparateFieldInJSONFormat", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		logger := newLoggerJSON(ApplicationInfo{App: "myapp"}, RuntimeInfo{}, &buffer)
+
+		// act
+		logger.Error().Err(fmt.Errorf("Loading config failed: %w", errors.New("permission denied"))).Msg("Starting failed")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "Loading config failed: permission denied", event["error"])
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"message": "Loading config failed: permission denied", "type": "*fmt.wrapError"},
+				map[string]interface{}{"message": "permission denied", "type": "*errors.errorString"},
+			}, event["errorChain"])
+		}
+	})
+
+	t.Run("LogsErrorWithoutWrappedErrorsAsString", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		logger := newLoggerJSON(ApplicationInfo{App: "myapp"}, RuntimeInfo{}, &buffer)
+
+		// act
+		logger.Error().Err(errors.New("permission denied")).Msg("Starting failed")
+
+		assert.Contains(t, buffer.String(), `"error":"permission denied"`)
+		assert.NotContains(t, buffer.String(), "errorChain")
+	})
+
+	t.Run("KeepsFieldOrderAndLevel", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		writer := &levelRecordingWriter{}
+		logger := zerolog.New(newErrorChainWriter(writer))
+		zerolog.ErrorMarshalFunc = marshalErrorChain
+
+		// act
+		logger.Error().Err(fmt.Errorf("Loading config failed: %w", errors.New("permission denied"))).Str("file", "config.yaml").Msg("Starting failed")
+
+		assert.Equal(t, []zerolog.Level{zerolog.ErrorLevel}, writer.levels)
+		assert.Equal(t, `{"level":"error","error":"Loading config failed: permission denied","errorChain":[{"message":"Loading config failed: permission denied","type":"*fmt.wrapError"},{"message":"permission denied","type":"*errors.errorString"}],"file":"config.yaml","message":"Starting failed"}`+"\n", writer.String())
+	})
+}
@@ -0,0 +1,362 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrorReport contains an error and the context it occurred in, to be forwarded to an error reporting service
+type ErrorReport struct {
+	Error           error
+	Fatal           bool
+	Stack           []StackFrame
+	ApplicationInfo ApplicationInfo
+	RuntimeInfo     RuntimeInfo
+	TraceID         string
+	Tags            map[string]string
+	Time            time.Time
+}
+
+// StackFrame is a single frame of the stack an error got reported from
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Reporter forwards error reports to an error reporting service like Sentry or Google Error Reporting
+type Reporter interface {
+	Report(ctx context.Context, report ErrorReport) error
+}
+
+var (
+	errorReporterMutex    sync.RWMutex
+	errorReporter         Reporter
+	errorReportsWaitGroup sync.WaitGroup
+	errorReporterTimeout  = 5 * time.Second
+)
+
+// SetErrorReporter sets the reporter HandleError, the Recovery middleware and exhausted retries forward errors to;
+// passing nil disables error reporting
+func SetErrorReporter(reporter Reporter) {
+	errorReporterMutex.Lock()
+	defer errorReporterMutex.Unlock()
+
+	errorReporter = reporter
+}
+
+// InitErrorReportingFromEnv sets the error reporter based on the ESTAFETTE_ERROR_REPORTER envvar, being either sentry
+// (configured with SENTRY_DSN and optionally SENTRY_ENVIRONMENT) or google; if the envvar is empty but SENTRY_DSN is
+// set sentry is used
+func InitErrorReportingFromEnv() error {
+	reporterType := strings.ToLower(os.Getenv("ESTAFETTE_ERROR_REPORTER"))
+	if reporterType == "" && os.Getenv("SENTRY_DSN") != "" {
+		reporterType = "sentry"
+	}
+
+	switch reporterType {
+	case "":
+		return nil
+	case "sentry":
+		reporter, err := NewSentryReporter(os.Getenv("SENTRY_DSN"), os.Getenv("SENTRY_ENVIRONMENT"))
+		if err != nil {
+			return err
+		}
+		SetErrorReporter(reporter)
+	case "google":
+		SetErrorReporter(NewGoogleErrorReporter(os.Stderr))
+	default:
+		return fmt.Errorf("Error reporter %v is not supported", reporterType)
+	}
+
+	log.Debug().Str("reporter", reporterType).Msg("Initialized error reporting")
+
+	return nil
+}
+
+// ReportError forwards the error to the configured reporter in the background, if any; use FlushErrorReports to wait
+// for pending reports before exiting
+func ReportError(ctx context.Context, err error, tags map[string]string) {
+	reportError(ctx, err, false, 2, tags)
+}
+
+// FlushErrorReports waits for pending error reports to be sent, for at most the timeout; it returns false if the
+// timeout elapsed
+func FlushErrorReports(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		errorReportsWaitGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// reportError builds an error report and forwards it to the configured reporter; fatal errors are reported
+// synchronously because the process exits right after
+func reportError(ctx context.Context, err error, fatal bool, skip int, tags map[string]string) {
+	errorReporterMutex.RLock()
+	reporter := errorReporter
+	errorReporterMutex.RUnlock()
+
+	if reporter == nil || err == nil {
+		return
+	}
+
+	applicationInfo := getApplicationInfo()
+	report := ErrorReport{
+		Error:           err,
+		Fatal:           fatal,
+		Stack:           stackFrames(skip + 1),
+		ApplicationInfo: applicationInfo,
+		RuntimeInfo:     applicationInfo.Runtime(),
+		TraceID:         traceIDFromContext(ctx),
+		Tags:            tags,
+		Time:            time.Now().UTC(),
+	}
+
+	// the originating context is likely cancelled before a background report is sent
+	send := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), errorReporterTimeout)
+		defer cancel()
+
+		if err := reporter.Report(ctx, report); err != nil {
+			log.Warn().Err(err).Msg("Reporting error failed")
+		}
+	}
+
+	if fatal {
+		send()
+		return
+	}
+
+	errorReportsWaitGroup.Add(1)
+	go func() {
+		defer errorReportsWaitGroup.Done()
+		send()
+	}()
+}
+
+// stackFrames returns the stack of the calling goroutine, most recent call first
+func stackFrames(skip int) (stack []StackFrame) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+
+	return
+}
+
+// SentryReporter sends error reports to Sentry using its envelope endpoint
+type SentryReporter struct {
+	endpoint    string
+	publicKey   string
+	dsn         string
+	environment string
+	client      *http.Client
+}
+
+// NewSentryReporter returns a reporter sending errors to the Sentry project identified by the dsn
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	parsedDSN, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("Parsing sentry dsn failed: %w", err)
+	}
+	if parsedDSN.User == nil || parsedDSN.User.Username() == "" {
+		return nil, fmt.Errorf("Sentry dsn has no public key")
+	}
+
+	path := strings.TrimSuffix(parsedDSN.Path, "/")
+	lastSlash := strings.LastIndex(path, "/")
+	projectID := path[lastSlash+1:]
+	if projectID == "" {
+		return nil, fmt.Errorf("Sentry dsn has no project id")
+	}
+
+	return &SentryReporter{
+		endpoint:    fmt.Sprintf("%v://%v%v/api/%v/envelope/", parsedDSN.Scheme, parsedDSN.Host, path[:lastSlash], projectID),
+		publicKey:   parsedDSN.User.Username(),
+		dsn:         dsn,
+		environment: environment,
+		client:      &http.Client{},
+	}, nil
+}
+
+// Report sends the error report to Sentry
+func (r *SentryReporter) Report(ctx context.Context, report ErrorReport) error {
+	eventID := make([]byte, 16)
+	_, _ = rand.Read(eventID)
+
+	level := "error"
+	if report.Fatal {
+		level = "fatal"
+	}
+
+	// sentry expects the most recent frame last
+	frames := make([]map[string]interface{}, 0, len(report.Stack))
+	for i := len(report.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, map[string]interface{}{
+			"function": report.Stack[i].Function,
+			"filename": report.Stack[i].File,
+			"lineno":   report.Stack[i].Line,
+		})
+	}
+
+	tags := map[string]string{}
+	for key, value := range report.RuntimeInfo.Labels() {
+		tags[key] = value
+	}
+	if report.TraceID != "" {
+		tags["trace_id"] = report.TraceID
+	}
+	for key, value := range report.Tags {
+		tags[key] = value
+	}
+
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   report.Time.Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"logger":      report.ApplicationInfo.App,
+		"release":     fmt.Sprintf("%v@%v", report.ApplicationInfo.App, report.ApplicationInfo.Version),
+		"environment": r.environment,
+		"server_name": report.RuntimeInfo.PodName,
+		"tags":        tags,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{
+				{
+					"type":       fmt.Sprintf("%T", report.Error),
+					"value":      report.Error.Error(),
+					"stacktrace": map[string]interface{}{"frames": frames},
+				},
+			},
+		},
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	if err := encoder.Encode(map[string]interface{}{"event_id": event["event_id"], "dsn": r.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)}); err != nil {
+		return err
+	}
+	if err := encoder.Encode(map[string]interface{}{"type": "event"}); err != nil {
+		return err
+	}
+	if err := encoder.Encode(event); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-sentry-envelope")
+	request.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=estafette-foundation/1.0, sentry_key=%v", r.publicKey))
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("Sentry responded with status code %v", response.StatusCode)
+	}
+
+	return nil
+}
+
+// GoogleErrorReporter writes error reports as structured log entries that Google Error Reporting picks up from Cloud
+// Logging
+type GoogleErrorReporter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewGoogleErrorReporter returns a reporter writing Google Error Reporting events to the writer, usually os.Stderr
+func NewGoogleErrorReporter(writer io.Writer) *GoogleErrorReporter {
+	return &GoogleErrorReporter{
+		writer: writer,
+	}
+}
+
+// Report writes the error report as a ReportedErrorEvent log entry
+func (r *GoogleErrorReporter) Report(ctx context.Context, report ErrorReport) error {
+	severity := "ERROR"
+	if report.Fatal {
+		severity = "CRITICAL"
+	}
+
+	// error reporting parses the stack in the format of runtime/debug.Stack
+	var message strings.Builder
+	message.WriteString(report.Error.Error())
+	message.WriteString("\n\ngoroutine 1 [running]:\n")
+	for _, frame := range report.Stack {
+		fmt.Fprintf(&message, "%v()\n\t%v:%v\n", frame.Function, frame.File, frame.Line)
+	}
+
+	entry := map[string]interface{}{
+		"@type":     "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent",
+		"eventTime": report.Time.Format(time.RFC3339Nano),
+		"severity":  severity,
+		"message":   message.String(),
+		"serviceContext": map[string]string{
+			"service": report.ApplicationInfo.App,
+			"version": report.ApplicationInfo.Version,
+		},
+	}
+	if len(report.Stack) > 0 {
+		entry["context"] = map[string]interface{}{
+			"reportLocation": map[string]interface{}{
+				"filePath":     report.Stack[0].File,
+				"lineNumber":   report.Stack[0].Line,
+				"functionName": report.Stack[0].Function,
+			},
+		}
+	}
+	// error reporting only links traces by their resource name, which requires the project
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); report.TraceID != "" && project != "" {
+		entry["logging.googleapis.com/trace"] = cloudTraceName(project, report.TraceID)
+	}
+	if len(report.Tags) > 0 {
+		entry["logging.googleapis.com/labels"] = report.Tags
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, err = r.writer.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,224 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+type fakeReporter struct {
+	mutex   sync.Mutex
+	reports []ErrorReport
+}
+
+func (r *fakeReporter) Report(ctx context.Context, report ErrorReport) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func useFakeReporter(t *testing.T) *fakeReporter {
+	reporter := &fakeReporter{}
+	SetErrorReporter(reporter)
+	t.Cleanup(func() {
+		SetErrorReporter(nil)
+	})
+
+	return reporter
+}
+
+func TestReportError(t *testing.T) {
+	t.Run("ForwardsErrorToReporter", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+
+		// act
+		ReportError(context.Background(), errors.New("boom"), map[string]string{"key": "value"})
+
+		assert.True(t, FlushErrorReports(time.Second))
+		if assert.Equal(t, 1, len(reporter.reports)) {
+			assert.Equal(t, "boom", reporter.reports[0].Error.Error())
+			assert.Equal(t, "value", reporter.reports[0].Tags["key"])
+			assert.Contains(t, reporter.reports[0].Stack[0].Function, "TestReportError")
+		}
+	})
+
+	t.Run("IsNoopWithoutReporter", func(t *testing.T) {
+		SetErrorReporter(nil)
+
+		// act
+		ReportError(context.Background(), errors.New("boom"), nil)
+
+		assert.True(t, FlushErrorReports(time.Second))
+	})
+
+	t.Run("ReportsRecoveredPanics", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+		handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}), Recovery())
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.True(t, FlushErrorReports(time.Second))
+		if assert.Equal(t, 1, len(reporter.reports)) {
+			assert.Equal(t, "Recovered from panic: boom", reporter.reports[0].Error.Error())
+		}
+	})
+
+	t.Run("ReportsExhaustedRetries", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+
+		// act
+		_ = Retry(func() error { return errors.New("boom") }, Attempts(2), DelayMillisecond(1), Fixed(), Report())
+
+		assert.True(t, FlushErrorReports(time.Second))
+		assert.Equal(t, 1, len(reporter.reports))
+	})
+
+	t.Run("DoesNotReportExhaustedRetriesWithoutReportOption", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+
+		// act
+		_ = Retry(func() error { return errors.New("boom") }, Attempts(2), DelayMillisecond(1), Fixed())
+
+		assert.True(t, FlushErrorReports(time.Second))
+		assert.Equal(t, 0, len(reporter.reports))
+	})
+
+	t.Run("DoesNotReportRetriesExhaustedByCancelledContext", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+
+		// act
+		_ = Retry(func() error { return fmt.Errorf("Calling backend failed: %w", context.DeadlineExceeded) }, Attempts(2), DelayMillisecond(1), Fixed(), Report())
+
+		assert.True(t, FlushErrorReports(time.Second))
+		assert.Equal(t, 0, len(reporter.reports))
+	})
+
+	t.Run("ReportsExhaustedRetriesWithTraceOfContext", func(t *testing.T) {
+		reporter := useFakeReporter(t)
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+		defer closer.Close()
+		span := tracer.StartSpan("retry")
+		defer span.Finish()
+		ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+		// act
+		_ = Retry(func() error { return errors.New("boom") }, Attempts(2), DelayMillisecond(1), Fixed(), Report(), Context(ctx))
+
+		assert.True(t, FlushErrorReports(time.Second))
+		if assert.Equal(t, 1, len(reporter.reports)) {
+			assert.Equal(t, span.Context().(jaeger.SpanContext).TraceID().String(), reporter.reports[0].TraceID)
+		}
+	})
+}
+
+func TestSentryReporter(t *testing.T) {
+	t.Run("ReturnsErrorForDSNWithoutKey", func(t *testing.T) {
+		// act
+		_, err := NewSentryReporter("https://sentry.io/42", "")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("PostsEnvelopeToProjectEndpoint", func(t *testing.T) {
+		var path, auth, body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			auth = r.Header.Get("X-Sentry-Auth")
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+		}))
+		defer server.Close()
+		reporter, err := NewSentryReporter(strings.Replace(server.URL, "http://", "http://publickey@", 1)+"/42", "production")
+		assert.Nil(t, err)
+
+		// act
+		err = reporter.Report(context.Background(), ErrorReport{
+			Error:           errors.New("boom"),
+			ApplicationInfo: ApplicationInfo{App: "myapp", Version: "1.0.0"},
+			Stack:           []StackFrame{{Function: "main.main", File: "main.go", Line: 10}},
+			Time:            time.Now(),
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/api/42/envelope/", path)
+		assert.Contains(t, auth, "sentry_key=publickey")
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		if assert.Equal(t, 3, len(lines)) {
+			var event map[string]interface{}
+			assert.Nil(t, json.Unmarshal([]byte(lines[2]), &event))
+			assert.Equal(t, "myapp@1.0.0", event["release"])
+			assert.Equal(t, "production", event["environment"])
+			assert.Equal(t, "error", event["level"])
+		}
+	})
+}
+
+func TestGoogleErrorReporter(t *testing.T) {
+	t.Run("WritesReportedErrorEvent", func(t *testing.T) {
+		var buffer bytes.Buffer
+		reporter := NewGoogleErrorReporter(&buffer)
+
+		// act
+		err := reporter.Report(context.Background(), ErrorReport{
+			Error:           errors.New("boom"),
+			Fatal:           true,
+			ApplicationInfo: ApplicationInfo{App: "myapp", Version: "1.0.0"},
+			Stack:           []StackFrame{{Function: "main.main", File: "main.go", Line: 10}},
+			Time:            time.Now(),
+		})
+
+		assert.Nil(t, err)
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+		assert.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", entry["@type"])
+		assert.Equal(t, "CRITICAL", entry["severity"])
+		assert.Equal(t, "boom\n\ngoroutine 1 [running]:\nmain.main()\n\tmain.go:10\n", entry["message"])
+		assert.Equal(t, map[string]interface{}{"service": "myapp", "version": "1.0.0"}, entry["serviceContext"])
+	})
+
+	t.Run("WritesTraceAsResourceNameOfProject", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+		var buffer bytes.Buffer
+		reporter := NewGoogleErrorReporter(&buffer)
+
+		// act
+		err := reporter.Report(context.Background(), ErrorReport{Error: errors.New("boom"), TraceID: "1a2b3c", Time: time.Now()})
+
+		assert.Nil(t, err)
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+		assert.Equal(t, "projects/my-project/traces/000000000000000000000000001a2b3c", entry["logging.googleapis.com/trace"])
+	})
+
+	t.Run("LeavesOutTraceWithoutProject", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+		var buffer bytes.Buffer
+		reporter := NewGoogleErrorReporter(&buffer)
+
+		// act
+		err := reporter.Report(context.Background(), ErrorReport{Error: errors.New("boom"), TraceID: "1a2b3c", Time: time.Now()})
+
+		assert.Nil(t, err)
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+		assert.NotContains(t, entry, "logging.googleapis.com/trace")
+	})
+}
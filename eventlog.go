@@ -0,0 +1,9 @@
+package foundation
+
+// WithWindowsEventLog writes warnings, errors and fatals to the windows event log as well, with the app name as
+// source; it can be enabled with envvar ESTAFETTE_LOG_WINDOWS_EVENT_LOG=true as well and is ignored on other platforms
+func WithWindowsEventLog() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.WindowsEventLog = true
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"errors"
+	"io"
+)
+
+// newWindowsEventLogWriter isn't supported on other platforms than windows
+func newWindowsEventLogWriter(source string) (io.WriteCloser, error) {
+	return nil, errors.New("The windows event log is only available on windows")
+}
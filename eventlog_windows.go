@@ -0,0 +1,64 @@
+package foundation
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// windowsEventLogEventID is the event id all log events are written with, since they don't map to predefined events
+const windowsEventLogEventID = 1
+
+// windowsEventLogWriter writes warning and higher json log events to the windows event log
+type windowsEventLogWriter struct {
+	log *eventlog.Log
+}
+
+// newWindowsEventLogWriter registers the source in the windows event log if needed, which requires administrator
+// rights the first time, and opens it
+func newWindowsEventLogWriter(source string) (io.WriteCloser, error) {
+	// registering fails if the source already exists, which is fine
+	_ = eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &windowsEventLogWriter{log: log}, nil
+}
+
+func (w *windowsEventLogWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func (w *windowsEventLogWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	// errors are ignored to not fail the other writers when the event log is unavailable
+	switch level {
+	case zerolog.WarnLevel:
+		_ = w.log.Warning(windowsEventLogEventID, windowsEventLogMessage(p))
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel:
+		_ = w.log.Error(windowsEventLogEventID, windowsEventLogMessage(p))
+	}
+
+	return len(p), nil
+}
+
+func (w *windowsEventLogWriter) Close() error {
+	return w.log.Close()
+}
+
+// windowsEventLogMessage puts the message first, since the event viewer shows the start of it in its overview,
+// followed by the json event with all fields
+func windowsEventLogMessage(p []byte) string {
+	var event map[string]interface{}
+	if err := json.Unmarshal(p, &event); err == nil {
+		if message, ok := event[zerolog.MessageFieldName].(string); ok && message != "" {
+			return message + "\n\n" + string(p)
+		}
+	}
+
+	return string(p)
+}
@@ -0,0 +1,100 @@
+package foundation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LifecycleEventType identifies a stage in the lifecycle of an application using foundation
+type LifecycleEventType string
+
+const (
+	// EventLoggingInitialized is published once logging has been configured
+	EventLoggingInitialized LifecycleEventType = "logging.initialized"
+	// EventMetricsServing is published once the Prometheus metrics listener is bound and starts serving
+	EventMetricsServing LifecycleEventType = "metrics.serving"
+	// EventStarted is published once the application has been marked as started with MarkStarted
+	EventStarted LifecycleEventType = "application.started"
+	// EventWarmupFinished is published once all registered warmup steps have run
+	EventWarmupFinished LifecycleEventType = "warmup.finished"
+	// EventShutdownSignalReceived is published when a signal to shut down has been received
+	EventShutdownSignalReceived LifecycleEventType = "shutdown.signalReceived"
+	// EventShutdownHookFinished is published after each function passed to HandleGracefulShutdown has run
+	EventShutdownHookFinished LifecycleEventType = "shutdown.hookFinished"
+	// EventShutdownFinished is published once all pending work has finished and the application is about to exit
+	EventShutdownFinished LifecycleEventType = "shutdown.finished"
+	// EventConfigReloaded is published when configuration has been reloaded
+	EventConfigReloaded LifecycleEventType = "config.reloaded"
+)
+
+// LifecycleEvent is published on the lifecycle event bus, with attributes depending on the type of event
+type LifecycleEvent struct {
+	Type       LifecycleEventType
+	Time       time.Time
+	Attributes map[string]string
+}
+
+// LifecycleEventHandler handles lifecycle events; it's called synchronously, so it shouldn't block for long
+type LifecycleEventHandler func(event LifecycleEvent)
+
+type lifecycleSubscription struct {
+	handler LifecycleEventHandler
+}
+
+var (
+	lifecycleSubscriptionsMutex sync.RWMutex
+	lifecycleSubscriptions      []*lifecycleSubscription
+)
+
+// SubscribeLifecycleEvents calls the handler for each lifecycle event published from now on, until the returned
+// unsubscribe function is called
+func SubscribeLifecycleEvents(handler LifecycleEventHandler) (unsubscribe func()) {
+	subscription := &lifecycleSubscription{handler: handler}
+
+	lifecycleSubscriptionsMutex.Lock()
+	lifecycleSubscriptions = append(lifecycleSubscriptions, subscription)
+	lifecycleSubscriptionsMutex.Unlock()
+
+	return func() {
+		lifecycleSubscriptionsMutex.Lock()
+		defer lifecycleSubscriptionsMutex.Unlock()
+
+		for i, s := range lifecycleSubscriptions {
+			if s == subscription {
+				lifecycleSubscriptions = append(lifecycleSubscriptions[:i:i], lifecycleSubscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// PublishLifecycleEvent calls all subscribed handlers with the event, in order of subscription; applications can use
+// it to publish their own events, like EventConfigReloaded
+func PublishLifecycleEvent(eventType LifecycleEventType, attributes map[string]string) {
+	lifecycleSubscriptionsMutex.RLock()
+	subscriptions := append([]*lifecycleSubscription{}, lifecycleSubscriptions...)
+	lifecycleSubscriptionsMutex.RUnlock()
+
+	event := LifecycleEvent{
+		Type:       eventType,
+		Time:       currentClock().Now().UTC(),
+		Attributes: attributes,
+	}
+
+	for _, subscription := range subscriptions {
+		handleLifecycleEvent(subscription.handler, event)
+	}
+}
+
+// handleLifecycleEvent keeps a panicking handler from breaking the publisher, which is foundation itself
+func handleLifecycleEvent(handler LifecycleEventHandler, event LifecycleEvent) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Error().Str("event", string(event.Type)).Msgf("Lifecycle event handler panicked: %v", recovered)
+		}
+	}()
+
+	handler(event)
+}
@@ -0,0 +1,74 @@
+package foundation
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishLifecycleEvent(t *testing.T) {
+	t.Run("CallsSubscribedHandlers", func(t *testing.T) {
+		var events []LifecycleEvent
+		unsubscribe := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			events = append(events, event)
+		})
+		defer unsubscribe()
+
+		// act
+		PublishLifecycleEvent(EventConfigReloaded, map[string]string{"file": "config.yaml"})
+
+		if assert.Equal(t, 1, len(events)) {
+			assert.Equal(t, EventConfigReloaded, events[0].Type)
+			assert.Equal(t, "config.yaml", events[0].Attributes["file"])
+		}
+	})
+
+	t.Run("DoesNotCallUnsubscribedHandlers", func(t *testing.T) {
+		calls := 0
+		unsubscribe := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			calls++
+		})
+		unsubscribe()
+
+		// act
+		PublishLifecycleEvent(EventConfigReloaded, nil)
+
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("RecoversPanickingHandlers", func(t *testing.T) {
+		calls := 0
+		unsubscribePanicking := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			panic("boom")
+		})
+		defer unsubscribePanicking()
+		unsubscribe := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			calls++
+		})
+		defer unsubscribe()
+
+		// act
+		PublishLifecycleEvent(EventConfigReloaded, nil)
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("IsPublishedDuringGracefulShutdown", func(t *testing.T) {
+		var types []LifecycleEventType
+		unsubscribe := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			types = append(types, event.Type)
+		})
+		defer unsubscribe()
+		defer SetReady(true)
+		gracefulShutdown := make(chan os.Signal, 1)
+		gracefulShutdown <- syscall.SIGTERM
+
+		// act
+		HandleGracefulShutdown(gracefulShutdown, &sync.WaitGroup{}, func() {}, func() {})
+
+		assert.Equal(t, []LifecycleEventType{EventShutdownSignalReceived, EventShutdownHookFinished, EventShutdownHookFinished, EventShutdownFinished}, types)
+	})
+}
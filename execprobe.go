@@ -0,0 +1,83 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// RunExecProbe checks the probe endpoint of the application running in the same container and exits with 0 if it's
+// healthy and 1 otherwise, when args contain --probe=liveness, --probe=readiness or --probe=startup; without that flag
+// it returns, so it can be called first thing in main to support exec probes in images without curl
+// foundation.RunExecProbe(os.Args[1:])
+func RunExecProbe(args []string, opts ...ProbeOption) {
+	probe, err := runExecProbe(args, opts...)
+	if probe == "" {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Probe %v failed: %v\n", probe, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// runExecProbe returns the requested probe and the result of checking it, or an empty probe if none is requested
+func runExecProbe(args []string, opts ...ProbeOption) (probe string, err error) {
+	for i, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--probe="):
+			probe = strings.TrimPrefix(arg, "--probe=")
+		case arg == "--probe" && i+1 < len(args):
+			probe = args[i+1]
+		}
+	}
+	if probe == "" {
+		return
+	}
+
+	config := newProbeConfig(opts...)
+
+	var path string
+	switch probe {
+	case "liveness":
+		path = config.LivenessPath
+	case "readiness":
+		path = config.ReadinessPath
+	case "startup":
+		path = "/startup"
+	default:
+		return probe, fmt.Errorf("Unknown probe %v, use liveness, readiness or startup", probe)
+	}
+
+	client := http.DefaultClient
+	url := fmt.Sprintf("http://%v%v", bindAddressForDialing(config.BindAddress, probePortFromEnv()), path)
+	if config.UnixSocket != "" {
+		socket := config.UnixSocket
+		client = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		}
+		url = "http://localhost" + path
+	}
+
+	return probe, checkHealth(client, url, 5*time.Second)
+}
+
+// bindAddressForDialing returns the address to reach a server bound to address and port, using localhost for a
+// server bound to all interfaces
+func bindAddressForDialing(address string, port int) string {
+	if address == "" || address == "0.0.0.0" || address == "::" {
+		address = "localhost"
+	}
+
+	return bindAddress(address, port)
+}
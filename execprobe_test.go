@@ -0,0 +1,51 @@
+package foundation
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunExecProbe(t *testing.T) {
+	t.Run("ReturnsWithoutProbeFlag", func(t *testing.T) {
+		// act
+		probe, err := runExecProbe([]string{"--verbose"})
+
+		assert.Equal(t, "", probe)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ChecksProbeOnPortFromEnv", func(t *testing.T) {
+		port := freeTestPort(t)
+		t.Setenv("ESTAFETTE_PROBE_PORT", fmt.Sprint(port))
+		server := InitLivenessAndReadinessWithPort(port)
+		defer ShutdownHTTPServer(server)()
+
+		// act
+		probe, err := runExecProbe([]string{"--probe=readiness"})
+
+		assert.Equal(t, "readiness", probe)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ChecksProbeOnUnixSocket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "probes.sock")
+		server := InitLivenessAndReadinessWithPort(0, WithProbeUnixSocket(socket))
+		defer ShutdownHTTPServer(server)()
+
+		// act
+		probe, err := runExecProbe([]string{"--probe", "liveness"}, WithProbeUnixSocket(socket))
+
+		assert.Equal(t, "liveness", probe)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnknownProbe", func(t *testing.T) {
+		// act
+		_, err := runExecProbe([]string{"--probe=health"})
+
+		assert.NotNil(t, err)
+	})
+}
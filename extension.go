@@ -0,0 +1,205 @@
+package foundation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// extensionEnvPrefix is the prefix of the envvars estafette sets for each custom property of an extension stage
+	extensionEnvPrefix = "ESTAFETTE_EXTENSION_"
+	// extensionCustomPropertiesEnv holds all custom properties of an extension stage as json
+	extensionCustomPropertiesEnv = "ESTAFETTE_EXTENSION_CUSTOM_PROPERTIES"
+)
+
+// ExtensionParametersValidator can be implemented by a parameters struct to validate the parameters after binding
+type ExtensionParametersValidator interface {
+	Validate() error
+}
+
+// BindExtensionParameters fills the struct target points to from the custom properties of an estafette extension stage.
+// Fields are set from their `default` tag first, then from the json in ESTAFETTE_EXTENSION_CUSTOM_PROPERTIES and last
+// from the ESTAFETTE_EXTENSION_<UPPER_SNAKE_CASED_NAME> envvars, where the name is taken from the `json` tag or the field
+// name. Fields tagged with `required:"true"` have to end up non-empty; if target implements ExtensionParametersValidator
+// its Validate method is called as well. All failures are returned together.
+//
+//	type parameters struct {
+//		Action   string        `json:"action" required:"true"`
+//		Timeout  time.Duration `json:"timeout" default:"5m"`
+//		Clusters []string      `json:"clusters"`
+//	}
+func BindExtensionParameters(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Extension parameters target should be a pointer to a struct, got %T", target)
+	}
+	value = value.Elem()
+
+	var err error
+
+	// defaults
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if defaultValue, ok := field.Tag.Lookup("default"); ok && field.IsExported() {
+			if setErr := setFieldFromString(value.Field(i), defaultValue); setErr != nil {
+				err = AppendErr(err, fmt.Errorf("Default value %q for parameter %v is invalid: %w", defaultValue, extensionParameterName(field), setErr))
+			}
+		}
+	}
+
+	// json encoded custom properties
+	if customProperties := os.Getenv(extensionCustomPropertiesEnv); customProperties != "" {
+		err = AppendErr(err, setFieldsFromJSON(value, customProperties))
+	}
+
+	// envvar per custom property
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := extensionParameterName(field)
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		envvarName := extensionEnvPrefix + ToUpperSnakeCase(name)
+		envvarValue, ok := os.LookupEnv(envvarName)
+		if !ok || envvarValue == "" {
+			continue
+		}
+		if setErr := setFieldFromString(value.Field(i), envvarValue); setErr != nil {
+			err = AppendErr(err, fmt.Errorf("Value of %v for parameter %v is invalid: %w", envvarName, name, setErr))
+		}
+	}
+
+	// validation
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Tag.Get("required") == "true" && field.IsExported() && value.Field(i).IsZero() {
+			err = AppendErr(err, fmt.Errorf("Parameter %v is required", extensionParameterName(field)))
+		}
+	}
+	if validator, ok := target.(ExtensionParametersValidator); ok && err == nil {
+		err = AppendErr(err, validator.Validate())
+	}
+
+	return err
+}
+
+// setFieldsFromJSON sets the fields from the properties in the json object; string values are parsed like envvar values,
+// so durations can be set as "5m", other values are unmarshalled into the field
+func setFieldsFromJSON(value reflect.Value, customProperties string) (err error) {
+	var properties map[string]json.RawMessage
+	if jsonErr := json.Unmarshal([]byte(customProperties), &properties); jsonErr != nil {
+		return fmt.Errorf("Unmarshalling %v failed: %w", extensionCustomPropertiesEnv, jsonErr)
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := extensionParameterName(field)
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		property, ok := properties[name]
+		if !ok {
+			// match keys case insensitively like json.Unmarshal does
+			for key, p := range properties {
+				if strings.EqualFold(key, name) {
+					property, ok = p, true
+					break
+				}
+			}
+		}
+		if !ok || string(property) == "null" {
+			continue
+		}
+
+		var setErr error
+		var stringValue string
+		if json.Unmarshal(property, &stringValue) == nil {
+			setErr = setFieldFromString(value.Field(i), stringValue)
+		} else {
+			setErr = json.Unmarshal(property, value.Field(i).Addr().Interface())
+		}
+		if setErr != nil {
+			err = AppendErr(err, fmt.Errorf("Value of %v in %v is invalid: %w", name, extensionCustomPropertiesEnv, setErr))
+		}
+	}
+
+	return err
+}
+
+// extensionParameterName returns the name from the json tag or the field name if there's no json tag
+func extensionParameterName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// setFieldFromString parses the string according to the field type; slices are parsed as json array or comma separated
+// list and other complex types as json
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return json.Unmarshal([]byte(value), field.Addr().Interface())
+		}
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("Cannot parse %q as %v", value, field.Type())
+		}
+		values := splitCommaSeparated(value)
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			slice.Index(i).SetString(v)
+		}
+		field.Set(slice)
+	default:
+		return json.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+
+	return nil
+}
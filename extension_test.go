@@ -0,0 +1,123 @@
+package foundation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testExtensionParameters struct {
+	Action      string        `json:"action" required:"true"`
+	Timeout     time.Duration `json:"timeout" default:"5m"`
+	Replicas    int           `json:"replicas" default:"1"`
+	DryRun      bool          `json:"dryRun"`
+	Clusters    []string      `json:"clusters"`
+	Labels      map[string]string
+	unexported  string
+	IgnoredJSON string `json:"-"`
+}
+
+type validatedExtensionParameters struct {
+	Action string `json:"action"`
+}
+
+func (p *validatedExtensionParameters) Validate() error {
+	if p.Action != "deploy" && p.Action != "rollback" {
+		return errors.New("Parameter action should be deploy or rollback")
+	}
+	return nil
+}
+
+func TestBindExtensionParameters(t *testing.T) {
+	t.Run("AppliesDefaults", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_ACTION", "deploy")
+		var params testExtensionParameters
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5*time.Minute, params.Timeout)
+		assert.Equal(t, 1, params.Replicas)
+	})
+
+	t.Run("BindsCustomPropertiesJSON", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_CUSTOM_PROPERTIES", `{"action":"deploy","replicas":3,"clusters":["a","b"],"Labels":{"team":"estafette"}}`)
+		var params testExtensionParameters
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "deploy", params.Action)
+		assert.Equal(t, 3, params.Replicas)
+		assert.Equal(t, []string{"a", "b"}, params.Clusters)
+		assert.Equal(t, map[string]string{"team": "estafette"}, params.Labels)
+	})
+
+	t.Run("BindsDurationFromCustomPropertiesJSON", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_CUSTOM_PROPERTIES", `{"action":"deploy","timeout":"10m","clusters":["a"]}`)
+		var params struct {
+			Action   string        `json:"action" required:"true"`
+			Timeout  time.Duration `json:"timeout" default:"5m"`
+			Clusters []string      `json:"clusters"`
+		}
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "deploy", params.Action)
+		assert.Equal(t, 10*time.Minute, params.Timeout)
+		assert.Equal(t, []string{"a"}, params.Clusters)
+	})
+
+	t.Run("BindsEnvvarsOverCustomPropertiesJSON", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_CUSTOM_PROPERTIES", `{"action":"deploy","replicas":3}`)
+		t.Setenv("ESTAFETTE_EXTENSION_REPLICAS", "5")
+		t.Setenv("ESTAFETTE_EXTENSION_DRY_RUN", "true")
+		t.Setenv("ESTAFETTE_EXTENSION_CLUSTERS", "a, b")
+		t.Setenv("ESTAFETTE_EXTENSION_TIMEOUT", "30s")
+		var params testExtensionParameters
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, params.Replicas)
+		assert.True(t, params.DryRun)
+		assert.Equal(t, []string{"a", "b"}, params.Clusters)
+		assert.Equal(t, 30*time.Second, params.Timeout)
+	})
+
+	t.Run("ReturnsAllFailures", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_REPLICAS", "many")
+		var params testExtensionParameters
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.Equal(t, 2, len(err.(MultiError)))
+	})
+
+	t.Run("CallsValidate", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_EXTENSION_ACTION", "destroy")
+		var params validatedExtensionParameters
+
+		// act
+		err := BindExtensionParameters(&params)
+
+		assert.EqualError(t, err, "Parameter action should be deploy or rollback")
+	})
+
+	t.Run("ReturnsErrorIfTargetIsNotAStructPointer", func(t *testing.T) {
+		var params testExtensionParameters
+
+		// act
+		err := BindExtensionParameters(params)
+
+		assert.NotNil(t, err)
+	})
+}
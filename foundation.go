@@ -2,12 +2,16 @@ package foundation
 
 import (
 	"context"
+	"math"
 	"math/rand"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode"
 
 	"syscall"
@@ -22,34 +26,83 @@ var (
 	r = rand.New(rand.NewSource(time.Now().UnixNano()))
 )
 
-// InitGracefulShutdownHandling generates the channel that listens to SIGTERM and a waitgroup to use for finishing work when shutting down
-func InitGracefulShutdownHandling() (gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup) {
+// ShutdownConfig is used to configure graceful shutdown handling
+type ShutdownConfig struct {
+	Signals []os.Signal
+}
+
+// ShutdownOption allows to override shutdown config
+type ShutdownOption func(*ShutdownConfig)
+
+// WithShutdownSignals sets the signals that trigger graceful shutdown, for example adding SIGQUIT or leaving out SIGINT
+// for interactive command line tools
+// default is SIGTERM and SIGINT
+func WithShutdownSignals(signals ...os.Signal) ShutdownOption {
+	return func(c *ShutdownConfig) {
+		c.Signals = signals
+	}
+}
+
+func newShutdownConfig(opts ...ShutdownOption) ShutdownConfig {
+	// default
+	config := ShutdownConfig{
+		Signals: []os.Signal{syscall.SIGTERM, syscall.SIGINT},
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// InitGracefulShutdownHandling generates the channel that listens to SIGTERM and SIGINT, or the signals set with
+// WithShutdownSignals, and a waitgroup to use for finishing work when shutting down
+func InitGracefulShutdownHandling(opts ...ShutdownOption) (gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup) {
+
+	config := newShutdownConfig(opts...)
 
 	// define channel used to gracefully shutdown the application
-	gracefulShutdown = make(chan os.Signal)
+	gracefulShutdown = make(chan os.Signal, 1)
 
-	signal.Notify(gracefulShutdown, syscall.SIGTERM, syscall.SIGINT)
+	// notifying without signals would relay all of them
+	if len(config.Signals) > 0 {
+		signal.Notify(gracefulShutdown, config.Signals...)
+	}
 
 	waitGroup = &sync.WaitGroup{}
 
 	return gracefulShutdown, waitGroup
 }
 
-// HandleGracefulShutdown waits for SIGTERM to unblock gracefulShutdown and waits for the waitgroup to await pending work
+// HandleGracefulShutdown waits for SIGTERM to unblock gracefulShutdown and waits for the waitgroup to await pending work;
+// if a readiness endpoint is served it returns 503 from then on, so Kubernetes stops routing traffic before the
+// shutdown functions run
 func HandleGracefulShutdown(gracefulShutdown chan os.Signal, waitGroup *sync.WaitGroup, functionsOnShutdown ...func()) {
 
 	signalReceived := <-gracefulShutdown
 	log.Info().
 		Msgf("Received signal %v. Waiting for running tasks to finish...", signalReceived)
+	PublishLifecycleEvent(EventShutdownSignalReceived, map[string]string{"signal": signalReceived.String()})
+
+	if atomic.LoadInt32(&readinessServed) == 1 {
+		SetReady(false)
+	}
 
 	// execute any passed function
-	for _, f := range functionsOnShutdown {
+	for i, f := range functionsOnShutdown {
 		f()
+		PublishLifecycleEvent(EventShutdownHookFinished, map[string]string{"hook": strconv.Itoa(i)})
 	}
 
 	waitGroup.Wait()
 
 	log.Info().Msg("Shutting down...")
+	PublishLifecycleEvent(EventShutdownFinished, nil)
+
+	// make sure buffered log events get written before the process exits
+	FlushLogs()
 }
 
 // InitCancellationContext adds cancelation to a context and on sigterm triggers the cancel function
@@ -58,7 +111,7 @@ func InitCancellationContext(ctx context.Context) context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// define channel used to trigger cancellation
-	cancelChannel := make(chan os.Signal)
+	cancelChannel := make(chan os.Signal, 1)
 
 	signal.Notify(cancelChannel, syscall.SIGTERM, syscall.SIGINT)
 
@@ -72,12 +125,31 @@ func InitCancellationContext(ctx context.Context) context.Context {
 
 // ApplyJitter adds +-25% jitter to the input
 func ApplyJitter(input int) (output int) {
+	return ApplyJitterPercent(input, 25)
+}
 
-	deviation := int(0.25 * float64(input))
+// ApplyJitterPercent adds +-percent% jitter to the input; inputs too small to deviate are returned as is
+func ApplyJitterPercent(input int, percent float64) (output int) {
+
+	deviation := int(math.Abs(percent / 100 * float64(input)))
+	if deviation == 0 {
+		return input
+	}
 
 	return input - deviation + r.Intn(2*deviation)
 }
 
+// ApplyJitterDuration adds +-25% jitter to the duration
+func ApplyJitterDuration(input time.Duration) (output time.Duration) {
+
+	deviation := time.Duration(math.Abs(0.25 * float64(input)))
+	if deviation == 0 {
+		return input
+	}
+
+	return input - deviation + time.Duration(r.Int63n(int64(2*deviation)))
+}
+
 // WatchForFileChanges waits for a change to the provided file path and then executes the function
 func WatchForFileChanges(filePath string, functionOnChange func(fsnotify.Event)) {
 	// copied from https://github.com/spf13/viper/blob/v1.3.1/viper.go#L282-L348
@@ -187,31 +259,60 @@ func IntArrayContains(array []int, search int) bool {
 
 // ToUpperSnakeCase turns any input string into an upper snake cased string
 func ToUpperSnakeCase(in string) string {
-	runes := []rune(in)
-	length := len(runes)
+	snake := strings.ToUpper(string(insertWordBoundaries(in)))
 
-	var out []rune
-	for i := 0; i < length; i++ {
-		if i > 0 && unicode.IsUpper(runes[i]) && ((i+1 < length && unicode.IsLower(runes[i+1])) || unicode.IsLower(runes[i-1])) {
-			out = append(out, '_')
-		}
-		out = append(out, unicode.ToUpper(runes[i]))
-	}
+	// make sure nothing but alphanumeric characters and underscores are returned
+	return nonUpperAlphanumericRegex.ReplaceAllString(snake, "_")
+}
 
-	snake := string(out)
+// ToLowerSnakeCase turns any input string into an lower snake cased string
+func ToLowerSnakeCase(in string) string {
+	snake := strings.ToLower(string(insertWordBoundaries(in)))
 
 	// make sure nothing but alphanumeric characters and underscores are returned
-	reg, err := regexp.Compile("[^A-Z0-9]+")
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Failed converting %v to upper snake case", in)
+	return nonLowerAlphanumericRegex.ReplaceAllString(snake, "_")
+}
+
+// ToKebabCase turns any input string into a lower kebab cased string
+func ToKebabCase(in string) string {
+	return strings.ToLower(strings.Join(splitWords(in), "-"))
+}
+
+// ToScreamingKebabCase turns any input string into an upper kebab cased string
+func ToScreamingKebabCase(in string) string {
+	return strings.ToUpper(strings.Join(splitWords(in), "-"))
+}
+
+// ToCamelCase turns any input string into a camel cased string
+func ToCamelCase(in string) string {
+	words := splitWords(in)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = capitalize(word)
+		}
 	}
-	cleanSnake := reg.ReplaceAllString(snake, "_")
+	return strings.Join(words, "")
+}
 
-	return cleanSnake
+// ToPascalCase turns any input string into a pascal cased string
+func ToPascalCase(in string) string {
+	words := splitWords(in)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, "")
 }
 
-// ToLowerSnakeCase turns any input string into an lower snake cased string
-func ToLowerSnakeCase(in string) string {
+var (
+	nonUpperAlphanumericRegex = regexp.MustCompile("[^A-Z0-9]+")
+	nonLowerAlphanumericRegex = regexp.MustCompile("[^a-z0-9]+")
+	nonAlphanumericRegex      = regexp.MustCompile("[^A-Za-z0-9]+")
+)
+
+// insertWordBoundaries inserts an underscore before each uppercase character that starts a new word in camel or pascal cased input
+func insertWordBoundaries(in string) []rune {
 	runes := []rune(in)
 	length := len(runes)
 
@@ -220,17 +321,27 @@ func ToLowerSnakeCase(in string) string {
 		if i > 0 && unicode.IsUpper(runes[i]) && ((i+1 < length && unicode.IsLower(runes[i+1])) || unicode.IsLower(runes[i-1])) {
 			out = append(out, '_')
 		}
-		out = append(out, unicode.ToLower(runes[i]))
+		out = append(out, runes[i])
 	}
 
-	snake := string(out)
+	return out
+}
 
-	// make sure nothing but alphanumeric characters and underscores are returned
-	reg, err := regexp.Compile("[^a-z0-9]+")
-	if err != nil {
-		log.Fatal().Err(err).Msgf("Failed converting %v to lower snake case", in)
+// splitWords splits any input string into its words, on case changes and any non-alphanumeric characters
+func splitWords(in string) (words []string) {
+	for _, word := range nonAlphanumericRegex.Split(string(insertWordBoundaries(in)), -1) {
+		if word != "" {
+			words = append(words, word)
+		}
 	}
-	cleanSnake := reg.ReplaceAllString(snake, "_")
+	return
+}
 
-	return cleanSnake
+// capitalize turns the first character of a word into uppercase and the rest into lowercase
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
 }
@@ -2,6 +2,7 @@ package foundation
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -84,6 +85,162 @@ func TestToLowerSnakeCase(t *testing.T) {
 	})
 }
 
+func TestToKebabCase(t *testing.T) {
+
+	t.Run("ReturnsPascalCaseAsLowercaseWithHyphenBetweenWords", func(t *testing.T) {
+
+		// act
+		kebab := ToKebabCase("PascalCase")
+
+		assert.Equal(t, "pascal-case", kebab)
+	})
+
+	t.Run("ReturnsUpperSnakeCaseAsLowercaseWithHyphenBetweenWords", func(t *testing.T) {
+
+		// act
+		kebab := ToKebabCase("KUBERNETES_ENGINE")
+
+		assert.Equal(t, "kubernetes-engine", kebab)
+	})
+
+	t.Run("ReturnsAcronymsAsSingleWord", func(t *testing.T) {
+
+		// act
+		kebab := ToKebabCase("HTTPServerPort")
+
+		assert.Equal(t, "http-server-port", kebab)
+	})
+}
+
+func TestToScreamingKebabCase(t *testing.T) {
+
+	t.Run("ReturnsCamelCaseAsUppercaseWithHyphenBetweenWords", func(t *testing.T) {
+
+		// act
+		kebab := ToScreamingKebabCase("camelCase")
+
+		assert.Equal(t, "CAMEL-CASE", kebab)
+	})
+}
+
+func TestToCamelCase(t *testing.T) {
+
+	t.Run("ReturnsHyphenSeparatedCaseAsCamelCase", func(t *testing.T) {
+
+		// act
+		camel := ToCamelCase("kubernetes-engine")
+
+		assert.Equal(t, "kubernetesEngine", camel)
+	})
+
+	t.Run("ReturnsUpperSnakeCaseAsCamelCase", func(t *testing.T) {
+
+		// act
+		camel := ToCamelCase("ESTAFETTE_LOG_FORMAT")
+
+		assert.Equal(t, "estafetteLogFormat", camel)
+	})
+
+	t.Run("ReturnsPascalCaseAsCamelCase", func(t *testing.T) {
+
+		// act
+		camel := ToCamelCase("PascalCase")
+
+		assert.Equal(t, "pascalCase", camel)
+	})
+}
+
+func TestToPascalCase(t *testing.T) {
+
+	t.Run("ReturnsLowerSnakeCaseAsPascalCase", func(t *testing.T) {
+
+		// act
+		pascal := ToPascalCase("kubernetes_engine")
+
+		assert.Equal(t, "KubernetesEngine", pascal)
+	})
+
+	t.Run("ReturnsCamelCaseAsPascalCase", func(t *testing.T) {
+
+		// act
+		pascal := ToPascalCase("camelCase")
+
+		assert.Equal(t, "CamelCase", pascal)
+	})
+}
+
+func TestApplyJitter(t *testing.T) {
+
+	t.Run("ReturnsValueWithin25PercentOfInput", func(t *testing.T) {
+
+		for i := 0; i < 100; i++ {
+
+			// act
+			output := ApplyJitter(100)
+
+			assert.True(t, output >= 75 && output < 125)
+		}
+	})
+
+	t.Run("ReturnsInputIfTooSmallToDeviate", func(t *testing.T) {
+
+		for input := 0; input < 4; input++ {
+
+			// act
+			output := ApplyJitter(input)
+
+			assert.Equal(t, input, output)
+		}
+	})
+}
+
+func TestApplyJitterPercent(t *testing.T) {
+
+	t.Run("ReturnsValueWithinPercentOfInput", func(t *testing.T) {
+
+		for i := 0; i < 100; i++ {
+
+			// act
+			output := ApplyJitterPercent(100, 10)
+
+			assert.True(t, output >= 90 && output < 110)
+		}
+	})
+
+	t.Run("ReturnsValueWithinPercentOfNegativeInput", func(t *testing.T) {
+
+		for i := 0; i < 100; i++ {
+
+			// act
+			output := ApplyJitterPercent(-100, 10)
+
+			assert.True(t, output >= -110 && output < -90)
+		}
+	})
+}
+
+func TestApplyJitterDuration(t *testing.T) {
+
+	t.Run("ReturnsDurationWithin25PercentOfInput", func(t *testing.T) {
+
+		for i := 0; i < 100; i++ {
+
+			// act
+			output := ApplyJitterDuration(time.Second)
+
+			assert.True(t, output >= 750*time.Millisecond && output < 1250*time.Millisecond)
+		}
+	})
+
+	t.Run("ReturnsInputIfTooSmallToDeviate", func(t *testing.T) {
+
+		// act
+		output := ApplyJitterDuration(time.Nanosecond)
+
+		assert.Equal(t, time.Nanosecond, output)
+	})
+}
+
 func TestFileExists(t *testing.T) {
 
 	t.Run("ReturnsTrueIfFileExists", func(t *testing.T) {
@@ -0,0 +1,215 @@
+package foundation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GoroutineMonitorConfig configures the goroutine leak and deadlock detection of a GoroutineMonitor
+type GoroutineMonitorConfig struct {
+	Interval         time.Duration
+	Threshold        int
+	GrowthSamples    int
+	BlockedThreshold time.Duration
+	MaxSamples       int
+}
+
+// GoroutineMonitorOption allows to override goroutine monitor config
+type GoroutineMonitorOption func(*GoroutineMonitorConfig)
+
+// WithSampleInterval sets the interval at which the goroutine count is sampled
+// default is 1m
+func WithSampleInterval(interval time.Duration) GoroutineMonitorOption {
+	return func(c *GoroutineMonitorConfig) {
+		c.Interval = interval
+	}
+}
+
+// WithGoroutineThreshold sets the goroutine count above which monotonic growth is reported as a possible leak
+// default is 1000
+func WithGoroutineThreshold(threshold int) GoroutineMonitorOption {
+	return func(c *GoroutineMonitorConfig) {
+		c.Threshold = threshold
+	}
+}
+
+// WithGrowthSamples sets the number of consecutive samples the goroutine count has to grow before it's reported
+// default is 5
+func WithGrowthSamples(samples int) GoroutineMonitorOption {
+	return func(c *GoroutineMonitorConfig) {
+		c.GrowthSamples = samples
+	}
+}
+
+// WithBlockedThreshold sets how long a goroutine can be blocked before it's reported as possibly deadlocked; the go
+// runtime only reports wait times in whole minutes
+// default is 10m
+func WithBlockedThreshold(threshold time.Duration) GoroutineMonitorOption {
+	return func(c *GoroutineMonitorConfig) {
+		c.BlockedThreshold = threshold
+	}
+}
+
+// GoroutineSample is a single measurement taken by the GoroutineMonitor
+type GoroutineSample struct {
+	Time      time.Time `json:"time"`
+	Count     int       `json:"count"`
+	Blocked   int       `json:"blocked"`
+	LeakFound bool      `json:"leakFound"`
+}
+
+// GoroutineMonitor periodically samples the number of goroutines and the goroutine stack profile and logs warnings
+// when the count keeps growing beyond a threshold or goroutines stay blocked for a long time
+type GoroutineMonitor struct {
+	config  GoroutineMonitorConfig
+	mutex   sync.RWMutex
+	samples []GoroutineSample
+	profile []byte
+}
+
+// NewGoroutineMonitor returns a GoroutineMonitor; call Start to begin sampling
+func NewGoroutineMonitor(opts ...GoroutineMonitorOption) *GoroutineMonitor {
+	// default
+	config := GoroutineMonitorConfig{
+		Interval:         time.Minute,
+		Threshold:        1000,
+		GrowthSamples:    5,
+		BlockedThreshold: 10 * time.Minute,
+		MaxSamples:       60,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.MaxSamples <= config.GrowthSamples {
+		config.MaxSamples = config.GrowthSamples + 1
+	}
+
+	return &GoroutineMonitor{
+		config: config,
+	}
+}
+
+// Start samples the goroutines at the configured interval until the context is cancelled
+func (m *GoroutineMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := currentClock().NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			m.sample()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
+
+// Samples returns the retained samples, oldest first
+func (m *GoroutineMonitor) Samples() []GoroutineSample {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]GoroutineSample{}, m.samples...)
+}
+
+// ServeHTTP serves the retained samples as json, or the last goroutine stack profile if the profile query parameter
+// is set, so it can be mounted on a debug endpoint
+func (m *GoroutineMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("profile") != "" {
+		m.mutex.RLock()
+		profile := m.profile
+		m.mutex.RUnlock()
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(profile)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(m.Samples())
+}
+
+func (m *GoroutineMonitor) sample() {
+	var profile bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&profile, 2)
+
+	blocked := countBlockedGoroutines(profile.Bytes(), m.config.BlockedThreshold)
+	sample := GoroutineSample{
+		Time:    currentClock().Now().UTC(),
+		Count:   runtime.NumGoroutine(),
+		Blocked: blocked,
+	}
+
+	m.mutex.Lock()
+	m.samples = append(m.samples, sample)
+	if len(m.samples) > m.config.MaxSamples {
+		m.samples = m.samples[len(m.samples)-m.config.MaxSamples:]
+	}
+	sample.LeakFound = sample.Count > m.config.Threshold && isGrowingMonotonically(m.samples, m.config.GrowthSamples)
+	m.samples[len(m.samples)-1] = sample
+	m.profile = profile.Bytes()
+	m.mutex.Unlock()
+
+	if sample.LeakFound {
+		log.Warn().Int("goroutines", sample.Count).Int("threshold", m.config.Threshold).Msgf("Goroutine count grew for %v consecutive samples to %v, possible goroutine leak", m.config.GrowthSamples, sample.Count)
+	}
+	if blocked > 0 {
+		log.Warn().Int("blocked", blocked).Dur("blockedThreshold", m.config.BlockedThreshold).Msgf("%v goroutines blocked for longer than %v, possible deadlock", blocked, m.config.BlockedThreshold)
+	}
+}
+
+// isGrowingMonotonically returns true if the last growthSamples samples each have a higher count than the one before
+func isGrowingMonotonically(samples []GoroutineSample, growthSamples int) bool {
+	if growthSamples <= 0 || len(samples) <= growthSamples {
+		return false
+	}
+
+	for i := len(samples) - growthSamples; i < len(samples); i++ {
+		if samples[i].Count <= samples[i-1].Count {
+			return false
+		}
+	}
+
+	return true
+}
+
+var goroutineWaitRegex = regexp.MustCompile(`^goroutine \d+ \[[^\]]*?, (\d+) minutes[^\]]*\]:$`)
+
+// countBlockedGoroutines counts goroutines in a debug=2 goroutine profile waiting for at least the threshold
+func countBlockedGoroutines(profile []byte, threshold time.Duration) int {
+	blocked := 0
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		matches := goroutineWaitRegex.FindStringSubmatch(scanner.Text())
+		if len(matches) != 2 {
+			continue
+		}
+		minutes, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		if time.Duration(minutes)*time.Minute >= threshold {
+			blocked++
+		}
+	}
+
+	return blocked
+}
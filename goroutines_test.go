@@ -0,0 +1,98 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoroutineMonitor(t *testing.T) {
+	t.Run("RetainsAtMostMaxSamples", func(t *testing.T) {
+		monitor := NewGoroutineMonitor(WithGrowthSamples(2))
+		monitor.config.MaxSamples = 3
+
+		// act
+		for i := 0; i < 5; i++ {
+			monitor.sample()
+		}
+
+		assert.Equal(t, 3, len(monitor.Samples()))
+	})
+
+	t.Run("ServesSamplesAsJSON", func(t *testing.T) {
+		monitor := NewGoroutineMonitor()
+		monitor.sample()
+		recorder := httptest.NewRecorder()
+
+		// act
+		monitor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/goroutines", nil))
+
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Body.String(), `"count":`)
+	})
+
+	t.Run("ServesProfile", func(t *testing.T) {
+		monitor := NewGoroutineMonitor()
+		monitor.sample()
+		recorder := httptest.NewRecorder()
+
+		// act
+		monitor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/goroutines?profile=1", nil))
+
+		assert.Contains(t, recorder.Body.String(), "goroutine ")
+	})
+}
+
+func TestIsGrowingMonotonically(t *testing.T) {
+	t.Run("ReturnsTrueIfLastSamplesGrow", func(t *testing.T) {
+		samples := []GoroutineSample{{Count: 10}, {Count: 5}, {Count: 6}, {Count: 7}}
+
+		// act
+		growing := isGrowingMonotonically(samples, 2)
+
+		assert.True(t, growing)
+	})
+
+	t.Run("ReturnsFalseIfCountStaysEqual", func(t *testing.T) {
+		samples := []GoroutineSample{{Count: 5}, {Count: 6}, {Count: 6}}
+
+		// act
+		growing := isGrowingMonotonically(samples, 2)
+
+		assert.False(t, growing)
+	})
+
+	t.Run("ReturnsFalseWithTooFewSamples", func(t *testing.T) {
+		samples := []GoroutineSample{{Count: 5}, {Count: 6}}
+
+		// act
+		growing := isGrowingMonotonically(samples, 2)
+
+		assert.False(t, growing)
+	})
+}
+
+func TestCountBlockedGoroutines(t *testing.T) {
+	t.Run("CountsGoroutinesWaitingLongerThanThreshold", func(t *testing.T) {
+		profile := []byte(`goroutine 1 [running]:
+main.main()
+
+goroutine 7 [chan receive, 12 minutes]:
+main.worker()
+
+goroutine 8 [select, 3 minutes]:
+main.worker()
+
+goroutine 9 [sync.Mutex.Lock, 30 minutes, locked to thread]:
+main.worker()
+`)
+
+		// act
+		blocked := countBlockedGoroutines(profile, 10*time.Minute)
+
+		assert.Equal(t, 2, blocked)
+	})
+}
@@ -0,0 +1,96 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/graphite"
+	"github.com/rs/zerolog/log"
+)
+
+// GraphiteConfig configures pushing metrics to graphite
+type GraphiteConfig struct {
+	Prefix   string
+	Interval time.Duration
+	UseTags  bool
+	Gatherer prometheus.Gatherer
+}
+
+// GraphiteOption allows to override graphite config
+type GraphiteOption func(*GraphiteConfig)
+
+// WithGraphitePrefix prefixes all metric names with prefix and a dot
+func WithGraphitePrefix(prefix string) GraphiteOption {
+	return func(c *GraphiteConfig) {
+		c.Prefix = prefix
+	}
+}
+
+// WithGraphiteInterval sets the interval at which metrics are pushed
+// default is 15s
+func WithGraphiteInterval(interval time.Duration) GraphiteOption {
+	return func(c *GraphiteConfig) {
+		c.Interval = interval
+	}
+}
+
+// WithGraphiteTags sends labels as graphite tags instead of appending them to the metric name, for graphite 1.1 and up
+func WithGraphiteTags() GraphiteOption {
+	return func(c *GraphiteConfig) {
+		c.UseTags = true
+	}
+}
+
+// WithGraphiteGatherer pushes the metrics gathered by gatherer instead of the ones in the prometheus default registry
+func WithGraphiteGatherer(gatherer prometheus.Gatherer) GraphiteOption {
+	return func(c *GraphiteConfig) {
+		c.Gatherer = gatherer
+	}
+}
+
+// InitGraphite pushes the contents of the prometheus registry to the graphite plaintext endpoint at address, like
+// localhost:2003, at the configured interval until the context is cancelled, for environments without prometheus
+func InitGraphite(ctx context.Context, address string, opts ...GraphiteOption) error {
+	// default
+	config := GraphiteConfig{
+		Interval: 15 * time.Second,
+		Gatherer: prometheus.DefaultGatherer,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	bridge, err := graphite.NewBridge(&graphite.Config{
+		URL:           address,
+		Prefix:        config.Prefix,
+		Interval:      config.Interval,
+		Timeout:       config.Interval,
+		UseTags:       config.UseTags,
+		Gatherer:      config.Gatherer,
+		Logger:        graphiteLogger{},
+		ErrorHandling: graphite.ContinueOnError,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("address", address).
+		Dur("interval", config.Interval).
+		Msg("Pushing metrics to graphite...")
+
+	go bridge.Run(ctx)
+
+	return nil
+}
+
+// graphiteLogger logs the errors of the graphite bridge as warnings
+type graphiteLogger struct{}
+
+func (graphiteLogger) Println(v ...interface{}) {
+	log.Warn().Msg(fmt.Sprint(v...))
+}
@@ -0,0 +1,46 @@
+package foundation
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitGraphite(t *testing.T) {
+	t.Run("PushesRegistryToGraphite", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		registry := prometheus.NewRegistry()
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "orders_total", Help: "Test counter."}, []string{"status"})
+		registry.MustRegister(counter)
+		counter.WithLabelValues("created").Add(3)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// act
+		err = InitGraphite(ctx, listener.Addr().String(), WithGraphitePrefix("myapp"), WithGraphiteInterval(10*time.Millisecond), WithGraphiteGatherer(registry))
+
+		assert.Nil(t, err)
+		conn, err := listener.Accept()
+		if assert.Nil(t, err) {
+			defer conn.Close()
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			assert.Nil(t, err)
+			assert.Regexp(t, `^myapp\.orders_total\.status\.created 3 \d+\n$`, line)
+		}
+	})
+
+	t.Run("ReturnsErrorWithoutAddress", func(t *testing.T) {
+		// act
+		err := InitGraphite(context.Background(), "")
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,232 @@
+package foundation
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCClientConfig is used to configure the connection created by DialGRPC
+type GRPCClientConfig struct {
+	KeepaliveTime         time.Duration
+	KeepaliveTimeout      time.Duration
+	CallTimeout           time.Duration
+	RetryAttempts         uint
+	RetryDelayMillisecond int
+	RetryableCodes        []codes.Code
+	TransportCredentials  credentials.TransportCredentials
+	DialOptions           []grpc.DialOption
+}
+
+// GRPCClientOption allows to override config
+type GRPCClientOption func(*GRPCClientConfig)
+
+// WithKeepalive sets after how long without activity the client pings the server and how long it waits for the ping to be acknowledged
+// default is 30s and 10s
+func WithKeepalive(keepaliveTime, keepaliveTimeout time.Duration) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.KeepaliveTime = keepaliveTime
+		c.KeepaliveTimeout = keepaliveTimeout
+	}
+}
+
+// WithCallTimeout sets the deadline for each unary call attempt, unless the context passed to the call has an earlier deadline
+// default is 10s
+func WithCallTimeout(timeout time.Duration) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.CallTimeout = timeout
+	}
+}
+
+// WithRetry sets the number of attempts for unary calls failing with a retryable status code and the base delay for the
+// exponential backoff with jitter in between attempts
+// default is 3 attempts with a base delay of 100ms
+func WithRetry(attempts uint, delayMillisecond int) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.RetryAttempts = attempts
+		c.RetryDelayMillisecond = delayMillisecond
+	}
+}
+
+// WithRetryableCodes sets the status codes for which unary calls are retried
+// default is Unavailable and ResourceExhausted
+func WithRetryableCodes(retryableCodes ...codes.Code) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.RetryableCodes = retryableCodes
+	}
+}
+
+// WithTransportCredentials sets the credentials used to secure the connection
+// default is insecure, for calls within the cluster
+func WithTransportCredentials(transportCredentials credentials.TransportCredentials) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.TransportCredentials = transportCredentials
+	}
+}
+
+// WithMTLS secures the connection with the tls config, for example one created with NewClientTLSConfig
+func WithMTLS(tlsConfig *tls.Config) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.TransportCredentials = credentials.NewTLS(tlsConfig)
+	}
+}
+
+// WithDialOptions adds any other grpc dial option
+func WithDialOptions(dialOptions ...grpc.DialOption) GRPCClientOption {
+	return func(c *GRPCClientConfig) {
+		c.DialOptions = append(c.DialOptions, dialOptions...)
+	}
+}
+
+// DialGRPC creates a client connection with keepalive, per-call deadlines, retries with exponential backoff with jitter,
+// opentracing propagation and metrics; it doesn't block until the connection is established, ctx is unused since grpc.NewClient takes none
+// conn, err := DialGRPC(ctx, "dns:///myservice.mynamespace:8080", WithCallTimeout(5*time.Second))
+func DialGRPC(ctx context.Context, target string, opts ...GRPCClientOption) (*grpc.ClientConn, error) {
+
+	// default
+	config := &GRPCClientConfig{
+		KeepaliveTime:         30 * time.Second,
+		KeepaliveTimeout:      10 * time.Second,
+		CallTimeout:           10 * time.Second,
+		RetryAttempts:         3,
+		RetryDelayMillisecond: 100,
+		RetryableCodes:        []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+		TransportCredentials:  insecure.NewCredentials(),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(config.TransportCredentials),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                config.KeepaliveTime,
+			Timeout:             config.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithChainUnaryInterceptor(
+			MetricsUnaryClientInterceptor(),
+			tracingUnaryClientInterceptor(),
+			retryUnaryClientInterceptor(config),
+		),
+		grpc.WithChainStreamInterceptor(
+			MetricsStreamClientInterceptor(),
+			tracingStreamClientInterceptor(),
+		),
+	}
+	dialOptions = append(dialOptions, config.DialOptions...)
+
+	return grpc.NewClient(target, dialOptions...)
+}
+
+// retryUnaryClientInterceptor retries calls failing with a retryable status code using Retry with exponential backoff with jitter
+func retryUnaryClientInterceptor(config *GRPCClientConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		isRetryableError := func(c *RetryConfig) {
+			c.IsRetryableError = func(err error) bool {
+				if ctx.Err() != nil {
+					return false
+				}
+				code := status.Code(err)
+				for _, retryableCode := range config.RetryableCodes {
+					if code == retryableCode {
+						return true
+					}
+				}
+				return false
+			}
+		}
+
+		return Retry(func() error {
+			callCtx, cancel := context.WithTimeout(ctx, config.CallTimeout)
+			defer cancel()
+
+			return invoker(callCtx, method, req, reply, cc, opts...)
+		}, Attempts(config.RetryAttempts), DelayMillisecond(config.RetryDelayMillisecond), ExponentialJitterBackoff(), LastErrorOnly(true), Context(ctx), Name("grpc:"+method), isRetryableError)
+	}
+}
+
+// tracingUnaryClientInterceptor starts a child span for each call and propagates it to the server in the outgoing metadata
+func tracingUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		span, ctx := startGRPCClientSpan(ctx, method)
+		defer span.Finish()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		setGRPCSpanStatus(span, err)
+
+		return err
+	}
+}
+
+// tracingStreamClientInterceptor starts a child span for each stream and propagates it to the server in the outgoing metadata
+func tracingStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		span, ctx := startGRPCClientSpan(ctx, method)
+		defer span.Finish()
+
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		setGRPCSpanStatus(span, err)
+
+		return clientStream, err
+	}
+}
+
+func startGRPCClientSpan(ctx context.Context, method string) (opentracing.Span, context.Context) {
+	var spanOptions []opentracing.StartSpanOption
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		spanOptions = append(spanOptions, opentracing.ChildOf(parent.Context()))
+	}
+	spanOptions = append(spanOptions, ext.SpanKindRPCClient, opentracing.Tag{Key: "component", Value: "grpc"})
+
+	span := opentracing.GlobalTracer().StartSpan(method, spanOptions...)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	opentracing.GlobalTracer().Inject(span.Context(), opentracing.TextMap, grpcMetadataCarrier(md))
+
+	return span, opentracing.ContextWithSpan(metadata.NewOutgoingContext(ctx, md), span)
+}
+
+func setGRPCSpanStatus(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("grpc.code", status.Code(err).String())
+	}
+}
+
+// grpcMetadataCarrier allows opentracing to inject and extract span contexts into and from grpc metadata
+type grpcMetadataCarrier metadata.MD
+
+func (c grpcMetadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	metadata.MD(c)[key] = append(metadata.MD(c)[key], val)
+}
+
+func (c grpcMetadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for key, values := range c {
+		for _, value := range values {
+			if err := handler(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
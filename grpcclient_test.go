@@ -0,0 +1,90 @@
+package foundation
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDialGRPC(t *testing.T) {
+
+	t.Run("RetriesCallsFailingWithRetryableCode", func(t *testing.T) {
+
+		var calls int32
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return status.Error(codes.Unavailable, "not yet")
+			}
+			stream.RecvMsg(&emptypb.Empty{})
+			return stream.SendMsg(&emptypb.Empty{})
+		})
+
+		conn, err := DialGRPC(context.Background(), target, WithRetry(3, 10))
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		// act
+		err = conn.Invoke(context.Background(), "/test.Service/Method", &emptypb.Empty{}, &emptypb.Empty{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("DoesNotRetryCallsFailingWithNonRetryableCode", func(t *testing.T) {
+
+		var calls int32
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.InvalidArgument, "invalid")
+		})
+
+		conn, err := DialGRPC(context.Background(), target, WithRetry(3, 10))
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		// act
+		err = conn.Invoke(context.Background(), "/test.Service/Method", &emptypb.Empty{}, &emptypb.Empty{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("AppliesCallTimeout", func(t *testing.T) {
+
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		})
+
+		conn, err := DialGRPC(context.Background(), target, WithCallTimeout(20*time.Millisecond), WithRetry(1, 10))
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		// act
+		err = conn.Invoke(context.Background(), "/test.Service/Method", &emptypb.Empty{}, &emptypb.Empty{})
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+}
+
+// startTestGRPCServer serves any method with the handler on a random port and returns its address
+func startTestGRPCServer(t *testing.T, handler grpc.StreamHandler, opts ...grpc.ServerOption) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(append(opts, grpc.UnknownServiceHandler(handler))...)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
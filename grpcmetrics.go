@@ -0,0 +1,166 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// the metric names differ from the ones of github.com/grpc-ecosystem/go-grpc-prometheus, so both can be registered
+var (
+	grpcServerRPCsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_rpcs_total",
+		Help: "Total number of rpcs completed by the server, regardless of success or failure.",
+	}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"})
+
+	grpcServerRPCDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_rpc_duration_seconds",
+		Help:    "Duration of rpcs handled by the server.",
+		Buckets: DurationBuckets,
+	}, []string{"grpc_type", "grpc_service", "grpc_method"})
+
+	grpcClientRPCsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_rpcs_total",
+		Help: "Total number of rpcs completed by the client, regardless of success or failure.",
+	}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"})
+
+	grpcClientRPCDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_rpc_duration_seconds",
+		Help:    "Duration of rpcs until the client received the response.",
+		Buckets: DurationBuckets,
+	}, []string{"grpc_type", "grpc_service", "grpc_method"})
+)
+
+// MetricsUnaryServerInterceptor records grpc_server_rpcs_total and grpc_server_rpc_duration_seconds for unary calls
+// grpc.NewServer(grpc.ChainUnaryInterceptor(foundation.MetricsUnaryServerInterceptor()))
+func MetricsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	rpcsTotal, durationSeconds := grpcServerRPCsTotal, grpcServerRPCDurationSeconds
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := currentClock().Now()
+		resp, err := handler(ctx, req)
+		observeGRPC(rpcsTotal, durationSeconds, "unary", info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// MetricsStreamServerInterceptor records grpc_server_rpcs_total and grpc_server_rpc_duration_seconds for streams
+// grpc.NewServer(grpc.ChainStreamInterceptor(foundation.MetricsStreamServerInterceptor()))
+func MetricsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	rpcsTotal, durationSeconds := grpcServerRPCsTotal, grpcServerRPCDurationSeconds
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := currentClock().Now()
+		err := handler(srv, ss)
+		observeGRPC(rpcsTotal, durationSeconds, grpcStreamType(info.IsClientStream, info.IsServerStream), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// MetricsUnaryClientInterceptor records grpc_client_rpcs_total and grpc_client_rpc_duration_seconds for unary calls;
+// DialGRPC adds it already
+func MetricsUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	rpcsTotal, durationSeconds := grpcClientRPCsTotal, grpcClientRPCDurationSeconds
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := currentClock().Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observeGRPC(rpcsTotal, durationSeconds, "unary", method, start, err)
+
+		return err
+	}
+}
+
+// MetricsStreamClientInterceptor records grpc_client_rpcs_total and grpc_client_rpc_duration_seconds for streams, once
+// the stream ends; DialGRPC adds it already
+func MetricsStreamClientInterceptor() grpc.StreamClientInterceptor {
+	rpcsTotal, durationSeconds := grpcClientRPCsTotal, grpcClientRPCDurationSeconds
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := currentClock().Now()
+		grpcType := grpcStreamType(desc.ClientStreams, desc.ServerStreams)
+
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observeGRPC(rpcsTotal, durationSeconds, grpcType, method, start, err)
+			return clientStream, err
+		}
+
+		return &metricsClientStream{
+			ClientStream: clientStream,
+			observe: func(err error) {
+				observeGRPC(rpcsTotal, durationSeconds, grpcType, method, start, err)
+			},
+		}, nil
+	}
+}
+
+// metricsClientStream observes the stream when receiving fails, which is with io.EOF once the server has finished it
+type metricsClientStream struct {
+	grpc.ClientStream
+	observe     func(err error)
+	observeOnce sync.Once
+}
+
+func (s *metricsClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.observeOnce.Do(func() {
+			if errors.Is(err, io.EOF) {
+				s.observe(nil)
+			} else {
+				s.observe(err)
+			}
+		})
+	}
+
+	return err
+}
+
+// registerGRPCMetrics exposes grpc_server_rpcs_total, grpc_server_rpc_duration_seconds, grpc_client_rpcs_total and
+// grpc_client_rpc_duration_seconds
+func registerGRPCMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, grpcServerRPCsTotal)
+	registerCollector(registerer, grpcServerRPCDurationSeconds)
+	registerCollector(registerer, grpcClientRPCsTotal)
+	registerCollector(registerer, grpcClientRPCDurationSeconds)
+}
+
+func observeGRPC(rpcsTotal *prometheus.CounterVec, durationSeconds *prometheus.HistogramVec, grpcType, fullMethod string, start time.Time, err error) {
+	service, method := splitGRPCMethod(fullMethod)
+
+	rpcsTotal.WithLabelValues(grpcType, service, method, status.Code(err).String()).Inc()
+	durationSeconds.WithLabelValues(grpcType, service, method).Observe(currentClock().Since(start).Seconds())
+}
+
+// splitGRPCMethod splits /package.Service/Method into package.Service and Method
+func splitGRPCMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "unknown", fullMethod
+}
+
+func grpcStreamType(clientStream, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return "bidi_stream"
+	case clientStream:
+		return "client_stream"
+	case serverStream:
+		return "server_stream"
+	}
+
+	return "unary"
+}
@@ -0,0 +1,103 @@
+package foundation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestMetricsUnaryServerInterceptor(t *testing.T) {
+	t.Run("CountsCallsByCode", func(t *testing.T) {
+		interceptor := MetricsUnaryServerInterceptor()
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.UnaryServer/Get"}
+		before := testutil.ToFloat64(grpcServerRPCsTotal.WithLabelValues("unary", "test.UnaryServer", "Get", "NotFound"))
+
+		// act
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, float64(1), testutil.ToFloat64(grpcServerRPCsTotal.WithLabelValues("unary", "test.UnaryServer", "Get", "NotFound"))-before)
+	})
+}
+
+func TestMetricsStreamServerInterceptor(t *testing.T) {
+	t.Run("CountsStreamsByCode", func(t *testing.T) {
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			stream.RecvMsg(&emptypb.Empty{})
+			return stream.SendMsg(&emptypb.Empty{})
+		}, grpc.ChainStreamInterceptor(MetricsStreamServerInterceptor()))
+		before := testutil.ToFloat64(grpcServerRPCsTotal.WithLabelValues("bidi_stream", "test.StreamServer", "Method", "OK"))
+
+		conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		// act
+		err = conn.Invoke(context.Background(), "/test.StreamServer/Method", &emptypb.Empty{}, &emptypb.Empty{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(grpcServerRPCsTotal.WithLabelValues("bidi_stream", "test.StreamServer", "Method", "OK"))-before)
+	})
+}
+
+func TestMetricsUnaryClientInterceptor(t *testing.T) {
+	t.Run("IsAddedByDialGRPC", func(t *testing.T) {
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(codes.PermissionDenied, "denied")
+		})
+		before := testutil.ToFloat64(grpcClientRPCsTotal.WithLabelValues("unary", "test.UnaryClient", "Method", "PermissionDenied"))
+
+		conn, err := DialGRPC(context.Background(), target)
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		// act
+		err = conn.Invoke(context.Background(), "/test.UnaryClient/Method", &emptypb.Empty{}, &emptypb.Empty{})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, float64(1), testutil.ToFloat64(grpcClientRPCsTotal.WithLabelValues("unary", "test.UnaryClient", "Method", "PermissionDenied"))-before)
+	})
+}
+
+func TestMetricsStreamClientInterceptor(t *testing.T) {
+	t.Run("CountsStreamOnceItEnds", func(t *testing.T) {
+		target := startTestGRPCServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+			stream.SendMsg(&emptypb.Empty{})
+			return nil
+		})
+		before := testutil.ToFloat64(grpcClientRPCsTotal.WithLabelValues("server_stream", "test.StreamClient", "Method", "OK"))
+
+		conn, err := DialGRPC(context.Background(), target)
+		assert.Nil(t, err)
+		defer conn.Close()
+		stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/test.StreamClient/Method")
+		assert.Nil(t, err)
+		assert.Nil(t, stream.SendMsg(&emptypb.Empty{}))
+		assert.Nil(t, stream.CloseSend())
+
+		// act
+		assert.Nil(t, stream.RecvMsg(&emptypb.Empty{}))
+		assert.NotNil(t, stream.RecvMsg(&emptypb.Empty{}))
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(grpcClientRPCsTotal.WithLabelValues("server_stream", "test.StreamClient", "Method", "OK"))-before)
+	})
+}
+
+func TestSplitGRPCMethod(t *testing.T) {
+	t.Run("SplitsServiceAndMethod", func(t *testing.T) {
+		// act
+		service, method := splitGRPCMethod("/helloworld.Greeter/SayHello")
+
+		assert.Equal(t, "helloworld.Greeter", service)
+		assert.Equal(t, "SayHello", method)
+	})
+}
@@ -0,0 +1,312 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthCheckFunc checks a dependency of the application, like a database connection, and returns an error if it's
+// unhealthy
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheckConfig configures a registered health check
+type HealthCheckConfig struct {
+	Required bool
+	Timeout  time.Duration
+	CacheFor time.Duration
+}
+
+// HealthCheckOption allows to override health check config
+type HealthCheckOption func(*HealthCheckConfig)
+
+// WithOptionalCheck reports the check's failures without making the application unhealthy, for dependencies it can
+// do without
+func WithOptionalCheck() HealthCheckOption {
+	return func(c *HealthCheckConfig) {
+		c.Required = false
+	}
+}
+
+// WithCheckTimeout fails the check if it doesn't finish within timeout, so a slow dependency can't make probe requests
+// hang; a timeout of 0 leaves it to the context of the request
+// default is 5s
+func WithCheckTimeout(timeout time.Duration) HealthCheckOption {
+	return func(c *HealthCheckConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithCheckCacheFor reuses the result of the check for the given duration, so frequent probes don't hammer the
+// dependency
+// default is 0, running the check on every probe
+func WithCheckCacheFor(duration time.Duration) HealthCheckOption {
+	return func(c *HealthCheckConfig) {
+		c.CacheFor = duration
+	}
+}
+
+type healthCheck struct {
+	name   string
+	check  HealthCheckFunc
+	config HealthCheckConfig
+
+	mutex      sync.Mutex
+	lastResult *HealthCheckResult
+	lastRunAt  time.Time
+	// resultReady is closed once the result of the current run is available; nil if no run is waiting for a result
+	resultReady chan struct{}
+	// checkRunning is true as long as the check func hasn't returned, which can be after its run timed out
+	checkRunning bool
+}
+
+// HealthCheckResult is the outcome of a single health check
+type HealthCheckResult struct {
+	Name     string        `json:"name"`
+	Required bool          `json:"required"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Cached   bool          `json:"cached,omitempty"`
+}
+
+// Healthy returns true if the check succeeded
+func (r HealthCheckResult) Healthy() bool {
+	return r.Error == ""
+}
+
+// HealthReport is the outcome of all registered health checks
+type HealthReport struct {
+	Healthy bool                `json:"healthy"`
+	Checks  []HealthCheckResult `json:"checks"`
+}
+
+// HealthChecker runs named health checks; the application is healthy as long as all required checks succeed
+type HealthChecker struct {
+	mutex  sync.RWMutex
+	checks []*healthCheck
+
+	reportMutex sync.RWMutex
+	lastReport  *HealthReport
+}
+
+// NewHealthChecker returns a HealthChecker without any checks, which is healthy
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{}
+}
+
+// DefaultHealthChecker holds the checks registered with RegisterHealthCheck, which the /readiness endpoint runs
+var DefaultHealthChecker = NewHealthChecker()
+
+// RegisterHealthCheck registers a named check with the DefaultHealthChecker; the /readiness endpoint returns 503 as long
+// as any required check fails
+// foundation.RegisterHealthCheck("database", func(ctx context.Context) error { return db.PingContext(ctx) })
+func RegisterHealthCheck(name string, check HealthCheckFunc, opts ...HealthCheckOption) {
+	DefaultHealthChecker.Register(name, check, opts...)
+}
+
+// Register adds a named check, which is required unless WithOptionalCheck is passed and times out after 5 seconds
+// unless WithCheckTimeout is passed; registering a check with the name of an existing one replaces it
+func (h *HealthChecker) Register(name string, check HealthCheckFunc, opts ...HealthCheckOption) {
+	// default
+	config := HealthCheckConfig{
+		Required: true,
+		Timeout:  5 * time.Second,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for i, existing := range h.checks {
+		if existing.name == name {
+			h.checks[i] = &healthCheck{name: name, check: check, config: config}
+			return
+		}
+	}
+	h.checks = append(h.checks, &healthCheck{name: name, check: check, config: config})
+}
+
+// Check runs all checks concurrently and returns their results in order of registration
+func (h *HealthChecker) Check(ctx context.Context) HealthReport {
+	h.mutex.RLock()
+	checks := append([]*healthCheck{}, h.checks...)
+	h.mutex.RUnlock()
+
+	report := HealthReport{
+		Healthy: true,
+		Checks:  make([]HealthCheckResult, len(checks)),
+	}
+
+	var waitGroup sync.WaitGroup
+	for i, check := range checks {
+		waitGroup.Add(1)
+		go func(i int, check *healthCheck) {
+			defer waitGroup.Done()
+			report.Checks[i] = check.run(ctx)
+		}(i, check)
+	}
+	waitGroup.Wait()
+
+	for _, result := range report.Checks {
+		if result.Required && !result.Healthy() {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
+
+// RunInBackground runs the checks every interval until ctx is cancelled, so Report serves the last results instead of
+// running the checks on every probe request; kubelet, service mesh and uptime probes add up to a lot of load on the
+// dependencies otherwise
+func (h *HealthChecker) RunInBackground(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := currentClock().NewTicker(interval)
+		defer ticker.Stop()
+		defer h.setLastReport(nil)
+
+		for {
+			report := h.Check(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			h.setLastReport(&report)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
+
+// RunHealthChecksInBackground runs the checks of the DefaultHealthChecker every interval until ctx is cancelled, so
+// the /readiness endpoint serves their last results
+// foundation.RunHealthChecksInBackground(ctx, 10*time.Second)
+func RunHealthChecksInBackground(ctx context.Context, interval time.Duration) {
+	DefaultHealthChecker.RunInBackground(ctx, interval)
+}
+
+// Report returns the last results of the checks run in the background, or runs them if they're not run in the
+// background or haven't finished their first run yet
+func (h *HealthChecker) Report(ctx context.Context) HealthReport {
+	h.reportMutex.RLock()
+	lastReport := h.lastReport
+	h.reportMutex.RUnlock()
+
+	if lastReport != nil {
+		return *lastReport
+	}
+
+	return h.Check(ctx)
+}
+
+func (h *HealthChecker) setLastReport(report *HealthReport) {
+	h.reportMutex.Lock()
+	defer h.reportMutex.Unlock()
+
+	h.lastReport = report
+}
+
+// run runs the check, unless its cached result can be used; probes arriving while the check runs wait for its result,
+// for as long as their context allows, and as long as a check that timed out hasn't returned its result is reused
+// instead of piling up runs against a hanging dependency
+func (c *healthCheck) run(ctx context.Context) HealthCheckResult {
+	clock := currentClock()
+
+	c.mutex.Lock()
+	if c.lastResult != nil && c.config.CacheFor > 0 && clock.Since(c.lastRunAt) < c.config.CacheFor {
+		result := *c.lastResult
+		c.mutex.Unlock()
+		result.Cached = true
+		return result
+	}
+	if c.resultReady != nil {
+		resultReady := c.resultReady
+		c.mutex.Unlock()
+		return c.waitForResult(ctx, resultReady)
+	}
+	if c.checkRunning && c.lastResult != nil {
+		result := *c.lastResult
+		c.mutex.Unlock()
+		result.Cached = true
+		return result
+	}
+	resultReady := make(chan struct{})
+	c.resultReady = resultReady
+	c.checkRunning = true
+	c.mutex.Unlock()
+
+	result := HealthCheckResult{
+		Name:     c.name,
+		Required: c.config.Required,
+	}
+
+	start := clock.Now()
+	err := c.runWithTimeout(ctx)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Duration = clock.Since(start)
+	observeHealthCheck(c.name, result.Duration, err)
+
+	c.mutex.Lock()
+	c.lastResult = &result
+	c.lastRunAt = start
+	c.resultReady = nil
+	c.mutex.Unlock()
+	close(resultReady)
+
+	return result
+}
+
+// waitForResult returns the result of the run that's in progress once it's ready, or a failed result if ctx is done
+// first
+func (c *healthCheck) waitForResult(ctx context.Context, resultReady chan struct{}) HealthCheckResult {
+	select {
+	case <-resultReady:
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		return *c.lastResult
+	case <-ctx.Done():
+		return HealthCheckResult{
+			Name:     c.name,
+			Required: c.config.Required,
+			Error:    fmt.Sprintf("Waiting for health check %v failed: %v", c.name, ctx.Err()),
+		}
+	}
+}
+
+// runWithTimeout stops waiting for the check once the timeout expires, even if the check ignores its context
+func (c *healthCheck) runWithTimeout(ctx context.Context) error {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := c.check(ctx)
+
+		c.mutex.Lock()
+		c.checkRunning = false
+		c.mutex.Unlock()
+
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("Health check %v timed out: %w", c.name, ctx.Err())
+	}
+}
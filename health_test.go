@@ -0,0 +1,198 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetHealthChecks replaces the DefaultHealthChecker with an empty one for the duration of the test
+func resetHealthChecks(t *testing.T) {
+	original := DefaultHealthChecker
+	DefaultHealthChecker = NewHealthChecker()
+	t.Cleanup(func() {
+		DefaultHealthChecker = original
+	})
+}
+
+func TestHealthChecker(t *testing.T) {
+	t.Run("IsHealthyWithoutChecks", func(t *testing.T) {
+		checker := NewHealthChecker()
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.True(t, report.Healthy)
+		assert.Empty(t, report.Checks)
+	})
+
+	t.Run("IsUnhealthyIfRequiredCheckFails", func(t *testing.T) {
+		checker := NewHealthChecker()
+		checker.Register("database", func(ctx context.Context) error { return nil })
+		checker.Register("cache", func(ctx context.Context) error { return errors.New("Connection refused") })
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.False(t, report.Healthy)
+		assert.Equal(t, "database", report.Checks[0].Name)
+		assert.True(t, report.Checks[0].Healthy())
+		assert.Equal(t, "cache", report.Checks[1].Name)
+		assert.Equal(t, "Connection refused", report.Checks[1].Error)
+	})
+
+	t.Run("IsHealthyIfOnlyOptionalCheckFails", func(t *testing.T) {
+		checker := NewHealthChecker()
+		checker.Register("cache", func(ctx context.Context) error { return errors.New("Connection refused") }, WithOptionalCheck())
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.True(t, report.Healthy)
+		assert.False(t, report.Checks[0].Healthy())
+		assert.False(t, report.Checks[0].Required)
+	})
+
+	t.Run("ReplacesCheckWithSameName", func(t *testing.T) {
+		checker := NewHealthChecker()
+		checker.Register("database", func(ctx context.Context) error { return errors.New("Connection refused") })
+		checker.Register("database", func(ctx context.Context) error { return nil })
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.True(t, report.Healthy)
+		assert.Equal(t, 1, len(report.Checks))
+	})
+
+	t.Run("FailsCheckExceedingTimeout", func(t *testing.T) {
+		checker := NewHealthChecker()
+		release := make(chan struct{})
+		defer close(release)
+		checker.Register("slow", func(ctx context.Context) error { <-release; return nil }, WithCheckTimeout(10*time.Millisecond))
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.False(t, report.Healthy)
+		assert.Contains(t, report.Checks[0].Error, "timed out")
+	})
+
+	t.Run("ReusesTimedOutResultWhileCheckIsStillRunning", func(t *testing.T) {
+		checker := NewHealthChecker()
+		release := make(chan struct{})
+		defer close(release)
+		var runs int32
+		checker.Register("slow", func(ctx context.Context) error { atomic.AddInt32(&runs, 1); <-release; return nil }, WithCheckTimeout(10*time.Millisecond))
+		checker.Check(context.Background())
+
+		// act
+		report := checker.Check(context.Background())
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+		assert.True(t, report.Checks[0].Cached)
+		assert.Contains(t, report.Checks[0].Error, "timed out")
+	})
+
+	t.Run("StopsWaitingForRunningCheckOnceContextIsDone", func(t *testing.T) {
+		checker := NewHealthChecker()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		checker.Register("slow", func(ctx context.Context) error { close(started); <-release; return nil }, WithCheckTimeout(0))
+		go checker.Check(context.Background())
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// act
+		report := checker.Check(ctx)
+
+		assert.False(t, report.Healthy)
+		assert.Contains(t, report.Checks[0].Error, "Waiting for health check slow failed")
+	})
+
+	t.Run("ReusesResultWithinCacheWindow", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		checker := NewHealthChecker()
+		runs := 0
+		checker.Register("database", func(ctx context.Context) error { runs++; return nil }, WithCheckCacheFor(10*time.Second))
+
+		// act
+		checker.Check(context.Background())
+		cached := checker.Check(context.Background())
+		clock.Advance(10 * time.Second)
+		expired := checker.Check(context.Background())
+
+		assert.Equal(t, 2, runs)
+		assert.True(t, cached.Checks[0].Cached)
+		assert.False(t, expired.Checks[0].Cached)
+	})
+}
+
+func TestRegisterHealthCheck(t *testing.T) {
+	t.Run("MakesReadinessReturn503IfCheckFails", func(t *testing.T) {
+		resetHealthChecks(t)
+		RegisterHealthCheck("database", func(ctx context.Context) error { return errors.New("Connection refused") })
+		recorder := httptest.NewRecorder()
+
+		// act
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "I'm not ready!\ndatabase: Connection refused\n", recorder.Body.String())
+	})
+}
+
+func TestRunInBackground(t *testing.T) {
+	t.Run("ServesLastResultsUntilNextRun", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		checker := NewHealthChecker()
+		var runs int32
+		checker.Register("database", func(ctx context.Context) error { atomic.AddInt32(&runs, 1); return nil })
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// act
+		checker.RunInBackground(ctx, 10*time.Second)
+
+		clock.BlockUntil(1)
+		assert.Eventually(t, func() bool {
+			checker.reportMutex.RLock()
+			defer checker.reportMutex.RUnlock()
+			return checker.lastReport != nil
+		}, time.Second, time.Millisecond)
+		checker.Report(context.Background())
+		checker.Report(context.Background())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+		clock.Advance(10 * time.Second)
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 2 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("RunsChecksPerReportOnceStopped", func(t *testing.T) {
+		checker := NewHealthChecker()
+		var runs int32
+		checker.Register("database", func(ctx context.Context) error { atomic.AddInt32(&runs, 1); return nil })
+		ctx, cancel := context.WithCancel(context.Background())
+		checker.RunInBackground(ctx, time.Hour)
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 1 }, time.Second, time.Millisecond)
+
+		// act
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			checker.Report(context.Background())
+			return atomic.LoadInt32(&runs) > 2
+		}, time.Second, time.Millisecond)
+	})
+}
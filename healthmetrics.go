@@ -0,0 +1,37 @@
+package foundation
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	healthCheckStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_check_status",
+		Help: "Result of the last run of a health check, 1 if it succeeded and 0 if it failed.",
+	}, []string{"check"})
+
+	healthCheckDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "health_check_duration_seconds",
+		Help:    "Duration of health check runs.",
+		Buckets: DurationBuckets,
+	}, []string{"check"})
+)
+
+// registerHealthMetrics exposes health_check_status and health_check_duration_seconds
+func registerHealthMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, healthCheckStatus)
+	registerCollector(registerer, healthCheckDurationSeconds)
+}
+
+// observeHealthCheck records the status and duration of a health check run, leaving out cached results
+func observeHealthCheck(name string, duration time.Duration, err error) {
+	status := 1.0
+	if err != nil {
+		status = 0
+	}
+
+	healthCheckStatus.WithLabelValues(name).Set(status)
+	healthCheckDurationSeconds.WithLabelValues(name).Observe(duration.Seconds())
+}
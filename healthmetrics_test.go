@@ -0,0 +1,25 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthMetrics(t *testing.T) {
+	t.Run("RecordsStatusAndDurationPerCheck", func(t *testing.T) {
+		checker := NewHealthChecker()
+		checker.Register("metrics-database", func(ctx context.Context) error { return nil })
+		checker.Register("metrics-cache", func(ctx context.Context) error { return errors.New("Connection refused") })
+
+		// act
+		checker.Check(context.Background())
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(healthCheckStatus.WithLabelValues("metrics-database")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(healthCheckStatus.WithLabelValues("metrics-cache")))
+		assert.GreaterOrEqual(t, testutil.CollectAndCount(healthCheckDurationSeconds, "health_check_duration_seconds"), 2)
+	})
+}
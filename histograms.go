@@ -0,0 +1,35 @@
+package foundation
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// DurationBuckets are the standard buckets in seconds for latencies, from 5ms to a minute, so latency SLO queries
+	// are comparable across services
+	DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+	// SizeBuckets are the standard buckets in bytes for payload sizes, from 100B to 1GB
+	SizeBuckets = prometheus.ExponentialBuckets(100, 10, 8)
+)
+
+// NewDurationHistogram registers a histogram with DurationBuckets for observing durations in seconds; like
+// NewMetricHistogram it's mirrored to StatsD if InitStatsD has been called
+// requestDuration := foundation.NewDurationHistogram("http_request_duration_seconds", "Duration of http requests.", "route")
+func NewDurationHistogram(name, help string, labelNames ...string) *MetricHistogram {
+	return NewMetricHistogram(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: DurationBuckets,
+	}, labelNames...)
+}
+
+// NewSizeHistogram registers a histogram with SizeBuckets for observing sizes in bytes; like NewMetricHistogram it's
+// mirrored to StatsD if InitStatsD has been called
+func NewSizeHistogram(name, help string, labelNames ...string) *MetricHistogram {
+	return NewMetricHistogram(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: SizeBuckets,
+	}, labelNames...)
+}
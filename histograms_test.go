@@ -0,0 +1,51 @@
+package foundation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDurationHistogram(t *testing.T) {
+	t.Run("UsesDurationBuckets", func(t *testing.T) {
+		histogram := NewDurationHistogram("test_duration_histogram_seconds", "Test duration histogram.", "route")
+
+		// act
+		histogram.Observe(0.2, "/api")
+
+		err := testutil.CollectAndCompare(histogram.vec, strings.NewReader(`
+# HELP test_duration_histogram_seconds Test duration histogram.
+# TYPE test_duration_histogram_seconds histogram
+test_duration_histogram_seconds_bucket{route="/api",le="0.005"} 0
+test_duration_histogram_seconds_bucket{route="/api",le="0.01"} 0
+test_duration_histogram_seconds_bucket{route="/api",le="0.025"} 0
+test_duration_histogram_seconds_bucket{route="/api",le="0.05"} 0
+test_duration_histogram_seconds_bucket{route="/api",le="0.1"} 0
+test_duration_histogram_seconds_bucket{route="/api",le="0.25"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="0.5"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="1"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="2.5"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="5"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="10"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="30"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="60"} 1
+test_duration_histogram_seconds_bucket{route="/api",le="+Inf"} 1
+test_duration_histogram_seconds_sum{route="/api"} 0.2
+test_duration_histogram_seconds_count{route="/api"} 1
+`))
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestNewSizeHistogram(t *testing.T) {
+	t.Run("UsesSizeBuckets", func(t *testing.T) {
+		// act
+		histogram := NewSizeHistogram("test_size_histogram_bytes", "Test size histogram.")
+
+		assert.NotNil(t, histogram)
+		assert.Equal(t, []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8, 1e9}, SizeBuckets)
+	})
+}
@@ -0,0 +1,403 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HTTPServerConfig is used to configure the server created by NewHTTPServer
+type HTTPServerConfig struct {
+	Port              int
+	Handler           http.Handler
+	Middlewares       []Middleware
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	DrainTimeout      time.Duration
+	ShutdownTimeout   time.Duration
+	WaitGroup         *sync.WaitGroup
+	MaxInFlight       int64
+}
+
+// HTTPServerOption allows to override config
+type HTTPServerOption func(*HTTPServerConfig)
+
+// WithPort sets the port the server listens on
+// default is 8080
+func WithPort(port int) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.Port = port
+	}
+}
+
+// WithHandler sets the handler to serve requests with
+// default is http.DefaultServeMux
+func WithHandler(handler http.Handler) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.Handler = handler
+	}
+}
+
+// WithMiddleware adds middlewares to wrap the handler with, in the order they're passed
+func WithMiddleware(middlewares ...Middleware) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.Middlewares = append(c.Middlewares, middlewares...)
+	}
+}
+
+// WithReadTimeout sets the maximum duration for reading an entire request, including the body
+// default is 30s
+func WithReadTimeout(timeout time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.ReadTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the maximum duration before timing out writes of the response
+// default is 30s
+func WithWriteTimeout(timeout time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.WriteTimeout = timeout
+	}
+}
+
+// WithIdleTimeout sets the maximum amount of time to wait for the next request when keep-alives are enabled
+// default is 120s
+func WithIdleTimeout(timeout time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.IdleTimeout = timeout
+	}
+}
+
+// WithDrainTimeout sets the maximum duration to wait for in-flight requests to finish while still accepting new
+// connections, to give load balancers time to stop sending traffic; this is part of the shutdown timeout
+// default is 5s
+func WithDrainTimeout(timeout time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.DrainTimeout = timeout
+	}
+}
+
+// WithShutdownTimeout sets the maximum duration to wait for in-flight requests to finish when shutting down, after which
+// remaining connections are closed forcefully
+// default is 20s
+func WithShutdownTimeout(timeout time.Duration) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.ShutdownTimeout = timeout
+	}
+}
+
+// WithWaitGroup registers each in-flight request with the waitgroup returned by InitGracefulShutdownHandling, so
+// HandleGracefulShutdown waits for them to finish
+func WithWaitGroup(waitGroup *sync.WaitGroup) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.WaitGroup = waitGroup
+	}
+}
+
+// WithMaxInFlightReadiness registers a health check that makes the /readiness endpoint return 503 while more than max
+// requests are in flight, so Kubernetes sheds traffic to other instances during spikes
+// default is 0, which doesn't register the check
+func WithMaxInFlightReadiness(max int64) HTTPServerOption {
+	return func(c *HTTPServerConfig) {
+		c.MaxInFlight = max
+	}
+}
+
+// HTTPServer is an http.Server with sane timeouts, a middleware chain and tracking of in-flight requests for graceful shutdown
+type HTTPServer struct {
+	*http.Server
+
+	config       HTTPServerConfig
+	inFlight     int64
+	shuttingDown int32
+	stopped      chan struct{}
+	stopOnce     sync.Once
+
+	connectionsMutex sync.Mutex
+	connections      map[net.Conn]http.ConnState
+}
+
+// NewHTTPServer returns an HTTPServer with sane timeouts, which can be configured by passing options
+// server := NewHTTPServer(WithPort(8080), WithHandler(router), WithMiddleware(RequestLogging()))
+func NewHTTPServer(opts ...HTTPServerOption) *HTTPServer {
+
+	// default
+	config := HTTPServerConfig{
+		Port:              8080,
+		Handler:           http.DefaultServeMux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		DrainTimeout:      5 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	server := &HTTPServer{
+		config:      config,
+		stopped:     make(chan struct{}),
+		connections: map[net.Conn]http.ConnState{},
+	}
+
+	server.Server = &http.Server{
+		Addr:              fmt.Sprintf(":%v", config.Port),
+		Handler:           server.trackInFlight(Chain(config.Handler, config.Middlewares...)),
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		ConnState:         server.trackConnection,
+	}
+
+	if config.MaxInFlight > 0 {
+		RegisterHealthCheck("in-flight-requests", server.MaxInFlightCheck(config.MaxInFlight))
+	}
+
+	return server
+}
+
+// Start binds the listener and serves requests in the background until Stop is called or ctx is done, which stops the
+// server like GracefulShutdown does; it returns an error if binding the listener fails
+func (s *HTTPServer) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("port", s.Addr).
+		Msg("Serving http requests...")
+
+	go func() {
+		if err := s.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Serving http requests failed")
+		}
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.GracefulShutdown()
+		case <-s.stopped:
+		}
+	}()
+
+	return nil
+}
+
+// Stop disables keep-alives and waits for in-flight requests to finish for at most the drain timeout while still
+// accepting connections, then closes the listeners and waits for the remaining requests until the shutdown timeout
+// expires, after which any connection still open is closed forcefully
+func (s *HTTPServer) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopped) })
+	atomic.StoreInt32(&s.shuttingDown, 1)
+	s.SetKeepAlivesEnabled(false)
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
+	defer cancel()
+
+	log.Debug().
+		Int64("inFlight", s.InFlightRequests()).
+		Msg("Draining http server...")
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, s.config.DrainTimeout)
+	s.waitForInFlightRequests(drainCtx)
+	drainCancel()
+
+	log.Debug().
+		Int64("inFlight", s.InFlightRequests()).
+		Msg("Shutting down http server...")
+
+	if err := s.Shutdown(ctx); err != nil {
+		forceClosed := s.openConnections()
+		s.Close()
+
+		log.Warn().
+			Int("connections", forceClosed).
+			Msgf("Force closed %v connections that didn't finish within the shutdown timeout", forceClosed)
+
+		return fmt.Errorf("Force closed %v connections: %w", forceClosed, err)
+	}
+
+	// wait for handlers that outlived their response, like the ones that timed out
+	return s.waitForInFlightRequests(ctx)
+}
+
+func (s *HTTPServer) waitForInFlightRequests(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for s.InFlightRequests() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// GracefulShutdown stops the server and logs any error; pass it to HandleGracefulShutdown as function to run on shutdown
+// foundation.HandleGracefulShutdown(gracefulShutdown, waitGroup, server.GracefulShutdown)
+func (s *HTTPServer) GracefulShutdown() {
+	if err := s.Stop(context.Background()); err != nil {
+		log.Warn().Err(err).Msg("Shutting down http server gracefully failed")
+	}
+}
+
+// InFlightRequests returns the number of requests currently being handled
+func (s *HTTPServer) InFlightRequests() int64 {
+	return atomic.LoadInt64(&s.inFlight)
+}
+
+// MaxInFlightCheck returns a health check that fails while more than max requests are in flight
+func (s *HTTPServer) MaxInFlightCheck(max int64) HealthCheckFunc {
+	return func(ctx context.Context) error {
+		if inFlight := s.InFlightRequests(); inFlight > max {
+			return fmt.Errorf("%v requests in flight exceed the maximum of %v", inFlight, max)
+		}
+		return nil
+	}
+}
+
+func (s *HTTPServer) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.begin()
+		defer s.end()
+
+		// ask clients to reconnect, so they end up at another instance
+		if atomic.LoadInt32(&s.shuttingDown) == 1 {
+			w.Header().Set("Connection", "close")
+		}
+
+		next.ServeHTTP(w, r.WithContext(contextWithInFlightTracker(r.Context(), s)))
+	})
+}
+
+func (s *HTTPServer) begin() {
+	if s.config.WaitGroup != nil {
+		s.config.WaitGroup.Add(1)
+	}
+	atomic.AddInt64(&s.inFlight, 1)
+}
+
+func (s *HTTPServer) end() {
+	atomic.AddInt64(&s.inFlight, -1)
+	if s.config.WaitGroup != nil {
+		s.config.WaitGroup.Done()
+	}
+}
+
+func (s *HTTPServer) trackConnection(conn net.Conn, state http.ConnState) {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		delete(s.connections, conn)
+	default:
+		s.connections[conn] = state
+	}
+}
+
+// openConnections returns the number of connections that haven't been closed yet
+func (s *HTTPServer) openConnections() int {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+
+	return len(s.connections)
+}
+
+// listenAndServeInBackground binds the address of the server, or the unix socket if set, before returning and serves it
+// in the background; errors binding or serving are passed to onError and to the group if set, or are fatal if neither
+// is set, and an error binding is returned as well
+func listenAndServeInBackground(server *http.Server, unixSocket, name string, onError func(err error), group *ServerGroup) error {
+	handleError := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+		if group != nil {
+			group.fail(name, err)
+		}
+		if onError == nil && group == nil {
+			log.Fatal().Err(err).Msgf("Starting %v listener failed", name)
+		}
+	}
+
+	listener, err := listen(server, unixSocket)
+	if err != nil {
+		handleError(err)
+		return err
+	}
+
+	if group != nil {
+		group.serve(server, listener, handleError)
+		return nil
+	}
+
+	serveInBackground(server, listener, handleError)
+
+	return nil
+}
+
+// bindAddress joins the address to bind to, empty for all interfaces, with port, adding brackets around ipv6 addresses
+func bindAddress(address string, port int) string {
+	return net.JoinHostPort(address, strconv.Itoa(port))
+}
+
+// listen binds the address of the server, or the unix socket if set
+func listen(server *http.Server, unixSocket string) (net.Listener, error) {
+	network, address := "tcp", server.Addr
+	if unixSocket != "" {
+		// remove the socket left behind by a previous run, since binding to an existing path fails
+		if info, err := os.Stat(unixSocket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(unixSocket)
+		}
+		network, address = "unix", unixSocket
+	}
+
+	return net.Listen(network, address)
+}
+
+// serveInBackground serves the server on listener until it's shut down, passing any other error to onError
+func serveInBackground(server *http.Server, listener net.Listener, onError func(err error)) {
+	go func() {
+		if err := serve(server, listener); err != nil {
+			onError(err)
+		}
+	}()
+}
+
+// serve serves the server on listener, with tls if configured, until it's shut down; it only returns errors other than
+// the one returned after shutting down
+func serve(server *http.Server, listener net.Listener) error {
+	var err error
+	if server.TLSConfig != nil {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
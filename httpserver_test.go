@@ -0,0 +1,206 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+
+	t.Run("SetsDefaultTimeouts", func(t *testing.T) {
+
+		// act
+		server := NewHTTPServer()
+
+		assert.Equal(t, ":8080", server.Addr)
+		assert.Equal(t, 10*time.Second, server.ReadHeaderTimeout)
+		assert.Equal(t, 30*time.Second, server.ReadTimeout)
+		assert.Equal(t, 30*time.Second, server.WriteTimeout)
+		assert.Equal(t, 120*time.Second, server.IdleTimeout)
+	})
+
+	t.Run("AppliesMiddlewaresInOrder", func(t *testing.T) {
+
+		var calls []string
+		middleware := func(name string) Middleware {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "handler")
+		})
+
+		server := NewHTTPServer(WithPort(5010), WithHandler(handler), WithMiddleware(middleware("first"), middleware("second")))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+		defer server.Stop(context.Background())
+
+		// act
+		resp, err := http.Get("http://localhost:5010/")
+
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, []string{"first", "second", "handler"}, calls)
+		}
+	})
+
+	t.Run("ReturnsErrorIfPortIsInUse", func(t *testing.T) {
+
+		server := NewHTTPServer(WithPort(5011), WithHandler(http.NotFoundHandler()))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+		defer server.Stop(context.Background())
+
+		// act
+		err = NewHTTPServer(WithPort(5011)).Start(context.Background())
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("WaitsForInFlightRequestsOnStop", func(t *testing.T) {
+
+		requestStarted := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			time.Sleep(200 * time.Millisecond)
+			io.WriteString(w, "done")
+		})
+		waitGroup := &sync.WaitGroup{}
+
+		server := NewHTTPServer(WithPort(5012), WithHandler(handler), WithWaitGroup(waitGroup))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+
+		var body []byte
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			resp, err := http.Get("http://localhost:5012/")
+			if err == nil {
+				defer resp.Body.Close()
+				body, _ = ioutil.ReadAll(resp.Body)
+			}
+		}()
+		<-requestStarted
+		assert.Equal(t, int64(1), server.InFlightRequests())
+
+		// act
+		server.GracefulShutdown()
+
+		<-done
+		waitGroup.Wait()
+		assert.Equal(t, int64(0), server.InFlightRequests())
+		assert.Equal(t, "done", string(body))
+	})
+
+	t.Run("ForceClosesConnectionsExceedingShutdownTimeout", func(t *testing.T) {
+
+		requestStarted := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			time.Sleep(time.Second)
+		})
+
+		server := NewHTTPServer(WithPort(5014), WithHandler(handler), WithDrainTimeout(10*time.Millisecond), WithShutdownTimeout(100*time.Millisecond))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+
+		go http.Get("http://localhost:5014/")
+		<-requestStarted
+
+		// act
+		err = server.Stop(context.Background())
+
+		assert.EqualError(t, err, "Force closed 1 connections: context deadline exceeded")
+	})
+
+	t.Run("StopsOnceContextIsDone", func(t *testing.T) {
+
+		port := freeTestPort(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		server := NewHTTPServer(WithPort(port), WithHandler(http.NotFoundHandler()))
+		err := server.Start(ctx)
+		assert.Nil(t, err)
+
+		// act
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%v", port))
+			if err != nil {
+				return true
+			}
+			conn.Close()
+			return false
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("SendsConnectionCloseWhileDraining", func(t *testing.T) {
+
+		requestStarted := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				close(requestStarted)
+				time.Sleep(200 * time.Millisecond)
+			}
+		})
+
+		server := NewHTTPServer(WithPort(5015), WithHandler(handler))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+
+		go http.Get("http://localhost:5015/slow")
+		<-requestStarted
+		go server.Stop(context.Background())
+		time.Sleep(20 * time.Millisecond)
+
+		// act
+		resp, err := http.Get("http://localhost:5015/")
+
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.True(t, resp.Close)
+		}
+	})
+}
+
+func TestWithMaxInFlightReadiness(t *testing.T) {
+	t.Run("MakesReadinessReturn503WhileMaxInFlightIsExceeded", func(t *testing.T) {
+		resetHealthChecks(t)
+		release := make(chan struct{})
+		requestStarted := make(chan struct{}, 2)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestStarted <- struct{}{}
+			<-release
+		})
+		server := NewHTTPServer(WithHandler(handler), WithMaxInFlightReadiness(1))
+
+		// act
+		for i := 0; i < 2; i++ {
+			go server.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			<-requestStarted
+		}
+
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "in-flight-requests: 2 requests in flight exceed the maximum of 1")
+		close(release)
+		assert.Eventually(t, func() bool { return server.InFlightRequests() == 0 }, time.Second, time.Millisecond)
+		assert.True(t, DefaultHealthChecker.Check(context.Background()).Healthy)
+	})
+}
@@ -0,0 +1,62 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+var (
+	applicationInfoMutex   sync.RWMutex
+	currentApplicationInfo ApplicationInfo
+)
+
+// setApplicationInfo stores the application info the logging is initialized with for use in the /info endpoint
+func setApplicationInfo(applicationInfo ApplicationInfo) {
+	applicationInfoMutex.Lock()
+	defer applicationInfoMutex.Unlock()
+
+	currentApplicationInfo = applicationInfo
+}
+
+func getApplicationInfo() ApplicationInfo {
+	applicationInfoMutex.RLock()
+	defer applicationInfoMutex.RUnlock()
+
+	return currentApplicationInfo
+}
+
+type infoResponse struct {
+	AppGroup  string       `json:"appgroup,omitempty"`
+	App       string       `json:"app,omitempty"`
+	Version   string       `json:"version,omitempty"`
+	Branch    string       `json:"branch,omitempty"`
+	Revision  string       `json:"revision,omitempty"`
+	BuildDate string       `json:"buildDate,omitempty"`
+	GoVersion string       `json:"goVersion"`
+	Os        string       `json:"os"`
+	Runtime   RuntimeInfo  `json:"runtime"`
+	Resources ResourceInfo `json:"resources"`
+}
+
+// InfoHandler returns the application info, kubernetes runtime info and resource info as json; it uses the application
+// info the logging has been initialized with
+func InfoHandler(w http.ResponseWriter, _ *http.Request) {
+	applicationInfo := getApplicationInfo()
+
+	response := infoResponse{
+		AppGroup:  applicationInfo.AppGroup,
+		App:       applicationInfo.App,
+		Version:   applicationInfo.Version,
+		Branch:    applicationInfo.Branch,
+		Revision:  applicationInfo.Revision,
+		BuildDate: applicationInfo.BuildDate,
+		GoVersion: applicationInfo.GoVersion(),
+		Os:        applicationInfo.OperatingSystem(),
+		Runtime:   applicationInfo.Runtime(),
+		Resources: GetResourceInfo(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,136 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var journaldSocketPath = "/run/systemd/journal/socket"
+
+// WithJournald writes all logs to the systemd journal as well, with every field as a structured journal field and the
+// level mapped to the journal priority; it can be enabled with envvar ESTAFETTE_LOG_JOURNALD=true as well
+func WithJournald() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Journald = true
+	}
+}
+
+// journaldWriter sends json log events to the journal using its native protocol
+type journaldWriter struct {
+	conn       net.Conn
+	identifier string
+}
+
+func newJournaldWriter(identifier string) (io.WriteCloser, error) {
+	conn, err := net.Dial("unixgram", journaldSocketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &journaldWriter{
+		conn:       conn,
+		identifier: identifier,
+	}, nil
+}
+
+func (w *journaldWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *journaldWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	var event map[string]interface{}
+	if err := json.Unmarshal(p, &event); err != nil {
+		// don't fail the other writers for an event that can't be forwarded
+		return len(p), nil
+	}
+
+	if _, err := w.conn.Write(journaldMessage(level, w.identifier, event)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *journaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// journaldMessage serializes the event in the journal's native protocol, with the message, priority and identifier
+// in the well-known fields and the other fields as upper snake cased journal fields
+func journaldMessage(level zerolog.Level, identifier string, event map[string]interface{}) []byte {
+	var buffer bytes.Buffer
+
+	message, _ := event[zerolog.MessageFieldName].(string)
+	writeJournaldField(&buffer, "MESSAGE", message)
+	writeJournaldField(&buffer, "PRIORITY", fmt.Sprint(journaldPriority(level)))
+	if identifier != "" {
+		writeJournaldField(&buffer, "SYSLOG_IDENTIFIER", identifier)
+	}
+
+	keys := make([]string, 0, len(event))
+	for key := range event {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := event[key]
+		if key == zerolog.MessageFieldName || key == zerolog.LevelFieldName || key == zerolog.TimestampFieldName {
+			continue
+		}
+
+		// journal field names can't start with an underscore, those are reserved for trusted fields
+		name := strings.TrimLeft(ToUpperSnakeCase(key), "_")
+		if name == "" {
+			continue
+		}
+
+		switch typedValue := value.(type) {
+		case string:
+			writeJournaldField(&buffer, name, typedValue)
+		default:
+			data, _ := json.Marshal(typedValue)
+			writeJournaldField(&buffer, name, string(data))
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// writeJournaldField writes KEY=value, or for values with newlines the key, the little endian length and the value
+func writeJournaldField(buffer *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buffer.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	buffer.WriteString(name + "\n")
+	_ = binary.Write(buffer, binary.LittleEndian, uint64(len(value)))
+	buffer.WriteString(value + "\n")
+}
+
+// journaldPriority maps the level to the syslog priority the journal uses
+func journaldPriority(level zerolog.Level) int {
+	switch level {
+	case zerolog.PanicLevel:
+		return 0
+	case zerolog.FatalLevel:
+		return 2
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.DebugLevel, zerolog.TraceLevel:
+		return 7
+	default:
+		return 6
+	}
+}
@@ -0,0 +1,70 @@
+package foundation
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaldMessage(t *testing.T) {
+	t.Run("MapsMessagePriorityAndFields", func(t *testing.T) {
+		event := map[string]interface{}{"level": "warn", "time": "2020-09-13T12:26:40Z", "message": "Reconnecting", "requestId": "abc", "attempt": float64(2)}
+
+		// act
+		message := journaldMessage(zerolog.WarnLevel, "myapp", event)
+
+		assert.Equal(t, "MESSAGE=Reconnecting\nPRIORITY=4\nSYSLOG_IDENTIFIER=myapp\nATTEMPT=2\nREQUEST_ID=abc\n", string(message))
+	})
+
+	t.Run("EncodesMultilineValuesWithLength", func(t *testing.T) {
+		event := map[string]interface{}{"message": "Failed\nretrying"}
+
+		// act
+		message := journaldMessage(zerolog.ErrorLevel, "", event)
+
+		assert.Equal(t, "MESSAGE\n\x0f\x00\x00\x00\x00\x00\x00\x00Failed\nretrying\nPRIORITY=3\n", string(message))
+	})
+}
+
+func TestJournaldPriority(t *testing.T) {
+	t.Run("MapsLevelsToSyslogPriorities", func(t *testing.T) {
+		assert.Equal(t, 0, journaldPriority(zerolog.PanicLevel))
+		assert.Equal(t, 2, journaldPriority(zerolog.FatalLevel))
+		assert.Equal(t, 3, journaldPriority(zerolog.ErrorLevel))
+		assert.Equal(t, 4, journaldPriority(zerolog.WarnLevel))
+		assert.Equal(t, 6, journaldPriority(zerolog.InfoLevel))
+		assert.Equal(t, 7, journaldPriority(zerolog.DebugLevel))
+		assert.Equal(t, 7, journaldPriority(zerolog.TraceLevel))
+	})
+}
+
+func TestJournaldWriter(t *testing.T) {
+	t.Run("SendsEventsWithPriorityOfLevelToJournalSocket", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "journal.socket")
+		listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+		if err != nil {
+			t.Skipf("Unix datagram sockets aren't supported: %v", err)
+		}
+		defer listener.Close()
+		originalPath := journaldSocketPath
+		journaldSocketPath = socketPath
+		defer func() { journaldSocketPath = originalPath }()
+		restoreLogger(t)
+		t.Cleanup(func() { setGlobalLoggerOutput(loggerOutput{}, "") })
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&bytes.Buffer{}), WithJournald())
+
+		// act
+		log.Error().Str("requestId", "abc").Msg("Hello")
+
+		datagram := make([]byte, 1024)
+		n, err := listener.Read(datagram)
+		assert.Nil(t, err)
+		assert.Contains(t, string(datagram[:n]), "MESSAGE=Hello\nPRIORITY=3\nSYSLOG_IDENTIFIER=myapp\n")
+		assert.Contains(t, string(datagram[:n]), "REQUEST_ID=abc\n")
+	})
+}
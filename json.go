@@ -0,0 +1,118 @@
+package foundation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxJSONBodyBytes is the maximum size of a request body read by ReadJSON
+const DefaultMaxJSONBodyBytes = 1 << 20
+
+// ReadJSON strictly decodes a json request body of at most 1MB into v; unknown fields and trailing data result in an error
+func ReadJSON(r *http.Request, v interface{}) error {
+	return ReadJSONWithLimit(r, v, DefaultMaxJSONBodyBytes)
+}
+
+// ReadJSONWithLimit strictly decodes a json request body of at most maxBytes into v; unknown fields and trailing data result in an error
+func ReadJSONWithLimit(r *http.Request, v interface{}, maxBytes int64) error {
+	if r.Body == nil {
+		return errors.New("Request body is empty")
+	}
+
+	body := &countingReader{reader: io.LimitReader(r.Body, maxBytes+1)}
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if body.count > maxBytes {
+		return fmt.Errorf("Request body is larger than %v bytes", maxBytes)
+	}
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("Request body is empty")
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("Request body contains badly-formed json")
+		case errors.As(err, &syntaxError):
+			return fmt.Errorf("Request body contains badly-formed json at position %v", syntaxError.Offset)
+		case errors.As(err, &unmarshalTypeError):
+			return fmt.Errorf("Request body contains an invalid value for field %q at position %v", unmarshalTypeError.Field, unmarshalTypeError.Offset)
+		}
+		return fmt.Errorf("Request body is invalid: %w", err)
+	}
+
+	// anything but whitespace after the json object is rejected
+	if _, err := decoder.Token(); err != io.EOF {
+		if body.count > maxBytes {
+			return fmt.Errorf("Request body is larger than %v bytes", maxBytes)
+		}
+		return errors.New("Request body must only contain a single json object")
+	}
+
+	return nil
+}
+
+// countingReader counts the bytes read from the wrapped reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count += int64(n)
+	return n, err
+}
+
+// WriteJSON writes v as json response with the specified status code
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(append(body, '\n'))
+
+	return err
+}
+
+// Problem is an error response as specified in RFC 7807
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// WriteProblem writes an RFC 7807 application/problem+json error response with the specified status code and detail message
+func WriteProblem(w http.ResponseWriter, status int, detail string) error {
+	return WriteProblemDetails(w, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// WriteProblemDetails writes the problem as an RFC 7807 application/problem+json error response
+func WriteProblemDetails(w http.ResponseWriter, problem Problem) error {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, err = w.Write(append(body, '\n'))
+
+	return err
+}
@@ -0,0 +1,106 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonTestItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestReadJSON(t *testing.T) {
+
+	t.Run("DecodesValidBody", func(t *testing.T) {
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"item","count":3}`))
+		var item jsonTestItem
+
+		// act
+		err := ReadJSON(request, &item)
+
+		assert.Nil(t, err)
+		assert.Equal(t, jsonTestItem{Name: "item", Count: 3}, item)
+	})
+
+	t.Run("ReturnsErrorForUnknownField", func(t *testing.T) {
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"item","color":"red"}`))
+		var item jsonTestItem
+
+		// act
+		err := ReadJSON(request, &item)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForTrailingData", func(t *testing.T) {
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"item"}{"name":"other"}`))
+		var item jsonTestItem
+
+		// act
+		err := ReadJSON(request, &item)
+
+		assert.EqualError(t, err, "Request body must only contain a single json object")
+	})
+
+	t.Run("ReturnsErrorForTooLargeBody", func(t *testing.T) {
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"`+strings.Repeat("a", 100)+`"}`))
+		var item jsonTestItem
+
+		// act
+		err := ReadJSONWithLimit(request, &item, 50)
+
+		assert.EqualError(t, err, "Request body is larger than 50 bytes")
+	})
+
+	t.Run("ReturnsErrorForEmptyBody", func(t *testing.T) {
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(""))
+		var item jsonTestItem
+
+		// act
+		err := ReadJSON(request, &item)
+
+		assert.EqualError(t, err, "Request body is empty")
+	})
+}
+
+func TestWriteJSON(t *testing.T) {
+
+	t.Run("WritesJSONWithStatus", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+
+		// act
+		err := WriteJSON(recorder, http.StatusCreated, jsonTestItem{Name: "item", Count: 1})
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"name\":\"item\",\"count\":1}\n", recorder.Body.String())
+	})
+}
+
+func TestWriteProblem(t *testing.T) {
+
+	t.Run("WritesProblemJSON", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+
+		// act
+		err := WriteProblem(recorder, http.StatusBadRequest, "Field name is required")
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"type\":\"about:blank\",\"title\":\"Bad Request\",\"status\":400,\"detail\":\"Field name is required\"}\n", recorder.Body.String())
+	})
+}
@@ -0,0 +1,115 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	kafkaMaxBatchSize        = 512
+	kafkaMaxBufferedMessages = 10000
+	kafkaFlushInterval       = time.Second
+	kafkaWriteTimeout        = 10 * time.Second
+)
+
+// KafkaLogConfig configures the kafka topic log events get published to
+type KafkaLogConfig struct {
+	Brokers []string
+	Topic   string
+}
+
+// WithKafka publishes all log events as json to the kafka topic as well, buffering them in memory and sending them in
+// batches; it can be enabled with envvars ESTAFETTE_LOG_KAFKA_BROKERS (comma separated) and ESTAFETTE_LOG_KAFKA_TOPIC
+// as well. HandleGracefulShutdown and FlushLogs send the buffered events
+func WithKafka(brokers []string, topic string) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Kafka = &KafkaLogConfig{
+			Brokers: brokers,
+			Topic:   topic,
+		}
+	}
+}
+
+// kafkaLogConfigFromEnv returns the kafka config if both envvars are set
+func kafkaLogConfigFromEnv() *KafkaLogConfig {
+	brokers := splitCommaSeparated(os.Getenv("ESTAFETTE_LOG_KAFKA_BROKERS"))
+	topic := os.Getenv("ESTAFETTE_LOG_KAFKA_TOPIC")
+	if len(brokers) == 0 || topic == "" {
+		return nil
+	}
+
+	return &KafkaLogConfig{
+		Brokers: brokers,
+		Topic:   topic,
+	}
+}
+
+// kafkaMessageWriter is the part of kafka.Writer used for publishing, so it can be replaced in tests
+type kafkaMessageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// kafkaLogExporter publishes the json log events it batches as messages keyed by the app name
+type kafkaLogExporter struct {
+	*logEventBatcher
+
+	topic  string
+	writer kafkaMessageWriter
+	key    []byte
+}
+
+func newKafkaLogExporter(applicationInfo ApplicationInfo, config KafkaLogConfig) *kafkaLogExporter {
+	return newKafkaLogExporterWithWriter(applicationInfo, config.Topic, &kafka.Writer{
+		Addr:         kafka.TCP(config.Brokers...),
+		Topic:        config.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    kafkaMaxBatchSize,
+		RequiredAcks: kafka.RequireOne,
+		WriteTimeout: kafkaWriteTimeout,
+	})
+}
+
+func newKafkaLogExporterWithWriter(applicationInfo ApplicationInfo, topic string, writer kafkaMessageWriter) *kafkaLogExporter {
+	exporter := &kafkaLogExporter{
+		topic:  topic,
+		writer: writer,
+		// keying by app keeps the events of an application in order within a partition
+		key: []byte(applicationInfo.App),
+	}
+	exporter.logEventBatcher = newLogEventBatcher(kafkaMaxBatchSize, kafkaMaxBufferedMessages, kafkaFlushInterval, exporter.publish)
+
+	exporter.start()
+
+	return exporter
+}
+
+// Close publishes the buffered messages and closes the connections to the brokers
+func (e *kafkaLogExporter) Close() error {
+	e.stop()
+
+	return e.writer.Close()
+}
+
+// publish sends a batch of log events to the topic; errors are written to stderr, since logging them would feed them
+// back into the exporter
+func (e *kafkaLogExporter) publish(ctx context.Context, events []batchedLogEvent) error {
+	messages := make([]kafka.Message, len(events))
+	for i, event := range events {
+		messages[i] = kafka.Message{
+			Key:   e.key,
+			Value: event.p,
+		}
+	}
+
+	if err := e.writer.WriteMessages(ctx, messages...); err != nil {
+		fmt.Fprintf(os.Stderr, "Publishing %v log events to kafka topic %v failed: %v\n", len(messages), e.topic, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeKafkaWriter struct {
+	mutex   sync.Mutex
+	batches [][]kafka.Message
+	closed  bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.batches = append(w.batches, messages)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	return nil
+}
+
+func TestKafkaLogExporter(t *testing.T) {
+	t.Run("PublishesBufferedEventsInOneBatchOnFlush", func(t *testing.T) {
+		writer := &fakeKafkaWriter{}
+		exporter := newKafkaLogExporterWithWriter(ApplicationInfo{App: "myapp"}, "logs", writer)
+		defer exporter.Close()
+		logger := zerolog.New(exporter)
+		logger.Info().Msg("Hello")
+		logger.Warn().Msg("World")
+
+		// act
+		err := exporter.flush(context.Background())
+
+		assert.Nil(t, err)
+		writer.mutex.Lock()
+		defer writer.mutex.Unlock()
+		if assert.Len(t, writer.batches, 1) && assert.Len(t, writer.batches[0], 2) {
+			assert.Equal(t, "myapp", string(writer.batches[0][0].Key))
+			assert.Equal(t, `{"level":"info","message":"Hello"}`+"\n", string(writer.batches[0][0].Value))
+			assert.Equal(t, `{"level":"warn","message":"World"}`+"\n", string(writer.batches[0][1].Value))
+		}
+	})
+
+	t.Run("PublishesBufferedEventsAndClosesWriterOnClose", func(t *testing.T) {
+		writer := &fakeKafkaWriter{}
+		exporter := newKafkaLogExporterWithWriter(ApplicationInfo{App: "myapp"}, "logs", writer)
+		logger := zerolog.New(exporter)
+		logger.Info().Msg("Hello")
+
+		// act
+		err := exporter.Close()
+
+		assert.Nil(t, err)
+		assert.True(t, writer.closed)
+		if assert.Len(t, writer.batches, 1) {
+			assert.Len(t, writer.batches[0], 1)
+		}
+	})
+}
+
+func TestKafkaLogExporterThroughLogger(t *testing.T) {
+	t.Run("PublishesFatalEventsRightAway", func(t *testing.T) {
+		restoreLogger(t)
+		writer := &fakeKafkaWriter{}
+		exporter := newKafkaLogExporterWithWriter(ApplicationInfo{App: "myapp"}, "logs", writer)
+		defer exporter.Close()
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&bytes.Buffer{}), withEventSink(exporter))
+		log.Info().Msg("Starting")
+
+		// act
+		log.WithLevel(zerolog.FatalLevel).Msg("Exiting")
+
+		writer.mutex.Lock()
+		defer writer.mutex.Unlock()
+		if assert.Len(t, writer.batches, 1) && assert.Len(t, writer.batches[0], 2) {
+			assert.Contains(t, string(writer.batches[0][1].Value), `"message":"Exiting"`)
+		}
+	})
+}
+
+func TestKafkaLogConfigFromEnv(t *testing.T) {
+	t.Run("ReturnsBrokersAndTopic", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_LOG_KAFKA_BROKERS", "kafka-0:9092, kafka-1:9092")
+		t.Setenv("ESTAFETTE_LOG_KAFKA_TOPIC", "logs")
+
+		// act
+		config := kafkaLogConfigFromEnv()
+
+		if assert.NotNil(t, config) {
+			assert.Equal(t, []string{"kafka-0:9092", "kafka-1:9092"}, config.Brokers)
+			assert.Equal(t, "logs", config.Topic)
+		}
+	})
+
+	t.Run("ReturnsNilWithoutTopic", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_LOG_KAFKA_BROKERS", "kafka-0:9092")
+		t.Setenv("ESTAFETTE_LOG_KAFKA_TOPIC", "")
+
+		// act
+		config := kafkaLogConfigFromEnv()
+
+		assert.Nil(t, config)
+	})
+}
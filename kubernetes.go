@@ -0,0 +1,36 @@
+package foundation
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// WithKubernetesMetadata adds the pod, namespace, node, container and service account to all logs, including the
+// console and plaintext formats; values missing from the downward api envvars are completed with the namespace of
+// the mounted service account and the pod name from the hostname when running in kubernetes
+func WithKubernetesMetadata() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.KubernetesMetadata = true
+	}
+}
+
+// completeKubernetesMetadata fills in the namespace and pod name if they're not set by envvars
+func completeKubernetesMetadata(runtimeInfo RuntimeInfo) RuntimeInfo {
+	if runtimeInfo.Namespace == "" {
+		if namespace, err := ioutil.ReadFile(serviceAccountNamespacePath); err == nil {
+			runtimeInfo.Namespace = strings.TrimSpace(string(namespace))
+		}
+	}
+
+	// the hostname of a pod is its name, unless overridden in the pod spec
+	if runtimeInfo.PodName == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if hostname, err := os.Hostname(); err == nil {
+			runtimeInfo.PodName = hostname
+		}
+	}
+
+	return runtimeInfo
+}
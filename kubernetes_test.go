@@ -0,0 +1,74 @@
+package foundation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func setServiceAccountNamespace(t *testing.T, namespace string) {
+	path := filepath.Join(t.TempDir(), "namespace")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(namespace), 0644))
+
+	originalPath := serviceAccountNamespacePath
+	serviceAccountNamespacePath = path
+	t.Cleanup(func() {
+		serviceAccountNamespacePath = originalPath
+	})
+}
+
+func TestCompleteKubernetesMetadata(t *testing.T) {
+	t.Run("CompletesNamespaceAndPodNameWhenRunningInKubernetes", func(t *testing.T) {
+		setServiceAccountNamespace(t, "estafette\n")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		hostname, _ := os.Hostname()
+
+		// act
+		runtimeInfo := completeKubernetesMetadata(RuntimeInfo{NodeName: "node-1"})
+
+		assert.Equal(t, RuntimeInfo{PodName: hostname, Namespace: "estafette", NodeName: "node-1"}, runtimeInfo)
+	})
+
+	t.Run("KeepsValuesFromEnvvars", func(t *testing.T) {
+		setServiceAccountNamespace(t, "estafette")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+
+		// act
+		runtimeInfo := completeKubernetesMetadata(RuntimeInfo{PodName: "myapp-abc", Namespace: "production"})
+
+		assert.Equal(t, RuntimeInfo{PodName: "myapp-abc", Namespace: "production"}, runtimeInfo)
+	})
+
+	t.Run("LeavesPodNameEmptyOutsideOfKubernetes", func(t *testing.T) {
+		setServiceAccountNamespace(t, "")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+		// act
+		runtimeInfo := completeKubernetesMetadata(RuntimeInfo{})
+
+		assert.Equal(t, RuntimeInfo{}, runtimeInfo)
+	})
+}
+
+func TestWithKubernetesMetadata(t *testing.T) {
+	t.Run("AddsMetadataToPlainTextFormat", func(t *testing.T) {
+		restoreLogger(t)
+		t.Setenv("POD_NAME", "myapp-abc")
+		t.Setenv("POD_NAMESPACE", "production")
+		t.Setenv("NODE_NAME", "")
+		t.Setenv("CONTAINER_NAME", "")
+		t.Setenv("SERVICE_ACCOUNT", "")
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatPlainText, WithLogWriter(&buffer), WithKubernetesMetadata())
+
+		// act
+		log.Info().Msg("Hello")
+
+		assert.Contains(t, buffer.String(), `kubernetes={"namespace":"production","pod":"myapp-abc"}`)
+	})
+}
@@ -0,0 +1,81 @@
+package foundation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxLabelLength is the maximum length of kubernetes label values and dns labels
+	maxLabelLength = 63
+	// hashSuffixLength is the number of hex characters of the hash appended by TruncateWithHash
+	hashSuffixLength = 8
+)
+
+var (
+	invalidLabelValueCharactersRegex = regexp.MustCompile("[^A-Za-z0-9._-]+")
+	invalidDNSLabelCharactersRegex   = regexp.MustCompile("[^a-z0-9-]+")
+	repeatedHyphensRegex             = regexp.MustCompile("-{2,}")
+)
+
+// SanitizeLabelValue turns any input, like a branch name, into a valid kubernetes label value, which is also a valid
+// prometheus label value: at most 63 characters of alphanumerics, '-', '_' or '.', starting and ending with an alphanumeric
+func SanitizeLabelValue(in string) string {
+	value := invalidLabelValueCharactersRegex.ReplaceAllString(in, "-")
+	value = trimNonAlphanumeric(value)
+	value = TruncateWithHash(value, maxLabelLength)
+
+	return trimNonAlphanumeric(value)
+}
+
+// SanitizeDNSLabel turns any input into a valid RFC 1123 dns label as used for kubernetes resource names: at most 63
+// lowercase alphanumerics or '-', starting and ending with an alphanumeric; it returns an empty string if the input
+// contains no alphanumerics at all
+func SanitizeDNSLabel(in string) string {
+	label := invalidDNSLabelCharactersRegex.ReplaceAllString(strings.ToLower(in), "-")
+	label = repeatedHyphensRegex.ReplaceAllString(label, "-")
+	label = strings.Trim(label, "-")
+	label = TruncateWithHash(label, maxLabelLength)
+
+	return strings.Trim(label, "-")
+}
+
+// TruncateWithHash returns the input if it's at most maxLen bytes long, otherwise it truncates it on a character
+// boundary and appends a hyphen and a short hash of the full input, so different long inputs stay unique after truncation
+func TruncateWithHash(in string, maxLen int) string {
+	if len(in) <= maxLen {
+		return in
+	}
+
+	hash := sha256.Sum256([]byte(in))
+	suffix := hex.EncodeToString(hash[:])[:hashSuffixLength]
+
+	if maxLen <= hashSuffixLength+1 {
+		return suffix[:maxInt(maxLen, 0)]
+	}
+
+	// cut at the start of a rune, so multi-byte characters aren't split into invalid utf-8
+	cut := maxLen - hashSuffixLength - 1
+	for cut > 0 && !utf8.RuneStart(in[cut]) {
+		cut--
+	}
+	prefix := strings.TrimRight(in[:cut], "-_.")
+
+	return prefix + "-" + suffix
+}
+
+func trimNonAlphanumeric(in string) string {
+	return strings.TrimFunc(in, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
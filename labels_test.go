@@ -0,0 +1,89 @@
+package foundation
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+
+	t.Run("ReplacesInvalidCharactersWithHyphen", func(t *testing.T) {
+
+		// act
+		value := SanitizeLabelValue("feature/ABC-123 new_thing")
+
+		assert.Equal(t, "feature-ABC-123-new_thing", value)
+	})
+
+	t.Run("TrimsNonAlphanumericCharactersFromStartAndEnd", func(t *testing.T) {
+
+		// act
+		value := SanitizeLabelValue("_refs/heads/main.")
+
+		assert.Equal(t, "refs-heads-main", value)
+	})
+
+	t.Run("TruncatesLongValuesTo63CharactersWithHash", func(t *testing.T) {
+
+		// act
+		value := SanitizeLabelValue("feature/" + strings.Repeat("a", 100))
+
+		assert.Equal(t, 63, len(value))
+		assert.True(t, strings.HasPrefix(value, "feature-aaaa"))
+	})
+}
+
+func TestSanitizeDNSLabel(t *testing.T) {
+
+	t.Run("ReturnsLowercaseAlphanumericsAndHyphens", func(t *testing.T) {
+
+		// act
+		label := SanitizeDNSLabel("Feature/ABC_123--New Thing")
+
+		assert.Equal(t, "feature-abc-123-new-thing", label)
+	})
+
+	t.Run("ReturnsEmptyStringIfNoAlphanumerics", func(t *testing.T) {
+
+		// act
+		label := SanitizeDNSLabel("/_/")
+
+		assert.Equal(t, "", label)
+	})
+}
+
+func TestTruncateWithHash(t *testing.T) {
+
+	t.Run("ReturnsShortInputAsIs", func(t *testing.T) {
+
+		// act
+		truncated := TruncateWithHash("short", 10)
+
+		assert.Equal(t, "short", truncated)
+	})
+
+	t.Run("ReturnsDifferentValuesForLongInputsWithSamePrefix", func(t *testing.T) {
+
+		// act
+		first := TruncateWithHash("my-very-long-branch-name-1", 20)
+		second := TruncateWithHash("my-very-long-branch-name-2", 20)
+
+		assert.Equal(t, 20, len(first))
+		assert.Equal(t, 20, len(second))
+		assert.True(t, strings.HasPrefix(first, "my-very-lon-"))
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("DoesNotSplitMultiByteCharacters", func(t *testing.T) {
+
+		// act
+		truncated := TruncateWithHash("prüfung-für-lange-namen", 20)
+
+		assert.True(t, utf8.ValidString(truncated))
+		assert.True(t, strings.HasPrefix(truncated, "prüfung-f-"))
+		assert.LessOrEqual(t, len(truncated), 20)
+	})
+}
@@ -4,31 +4,35 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-
-	"github.com/rs/zerolog/log"
 )
 
 // InitLiveness initializes the /liveness endpoint on port 5000
-func InitLiveness() {
-	InitLivenessWithPort(5000)
+func InitLiveness(opts ...ProbeOption) *http.Server {
+	return InitLivenessWithPort(5000, opts...)
 }
 
-// InitLivenessWithPort initializes the /liveness endpoint on specified port
-func InitLivenessWithPort(port int) {
-	// start liveness endpoint
-	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
-			Str("port", portString).
-			Msg("Serving /liveness endpoint...")
+// InitLivenessWithPort initializes the /liveness endpoint on specified port; shut down the returned server to release
+// the port
+func InitLivenessWithPort(port int, opts ...ProbeOption) *http.Server {
+	config := newProbeConfig(opts...)
+
+	serverMux := http.NewServeMux()
+	config.handleLiveness(serverMux)
 
-		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/liveness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm alive!\n")
-		})
+	return startProbeServer(port, config.LivenessPath, serverMux, config)
+}
 
-		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /liveness listener failed")
+// livenessHandler responds with 200 as long as the application is able to serve http requests and all watchdogs are
+// kicked in time
+func livenessHandler(w http.ResponseWriter, _ *http.Request) {
+	if errs := expiredWatchdogs(); len(errs) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "I'm not alive!\n")
+		for _, err := range errs {
+			fmt.Fprintf(w, "%v\n", err)
 		}
-	}()
+		return
+	}
+
+	io.WriteString(w, "I'm alive!\n")
 }
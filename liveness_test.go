@@ -13,7 +13,8 @@ func TestInitLiveness(t *testing.T) {
 	t.Run("Returns200OK", func(t *testing.T) {
 
 		// act
-		InitLivenessWithPort(5000)
+		server := InitLivenessWithPort(5000)
+		defer ShutdownHTTPServer(server)()
 
 		resp, err := pester.Get("http://localhost:5000/liveness")
 
@@ -0,0 +1,133 @@
+package foundation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// batchedLogEvent is a json log event queued by a logEventBatcher, with its level and the time it was logged
+type batchedLogEvent struct {
+	level zerolog.Level
+	p     []byte
+	time  time.Time
+}
+
+// logEventBatcher queues json log events in memory and passes them to send in batches from a background goroutine,
+// every flush interval or as soon as a batch is full, for the exporters forwarding log events to another system; fatal
+// and panic events are sent right away since the process is about to exit
+type logEventBatcher struct {
+	send          func(ctx context.Context, events []batchedLogEvent) error
+	maxBatchSize  int
+	maxBuffered   int
+	flushInterval time.Duration
+
+	mutex    sync.Mutex
+	events   []batchedLogEvent
+	flushC   chan struct{}
+	stopC    chan struct{}
+	doneC    chan struct{}
+	stopOnce sync.Once
+}
+
+func newLogEventBatcher(maxBatchSize, maxBuffered int, flushInterval time.Duration, send func(ctx context.Context, events []batchedLogEvent) error) *logEventBatcher {
+	return &logEventBatcher{
+		send:          send,
+		maxBatchSize:  maxBatchSize,
+		maxBuffered:   maxBuffered,
+		flushInterval: flushInterval,
+		flushC:        make(chan struct{}, 1),
+		stopC:         make(chan struct{}),
+		doneC:         make(chan struct{}),
+	}
+}
+
+func (b *logEventBatcher) Write(p []byte) (n int, err error) {
+	return b.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel queues the json log event; fatal and panic events are sent right away together with the queued events
+func (b *logEventBatcher) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	// zerolog reuses the buffer once write returns
+	event := batchedLogEvent{
+		level: level,
+		p:     append([]byte{}, p...),
+		time:  time.Now(),
+	}
+
+	b.mutex.Lock()
+	if len(b.events) >= b.maxBuffered {
+		// the receiving end isn't keeping up, drop the oldest events
+		b.events = b.events[1:]
+	}
+	b.events = append(b.events, event)
+	batchFull := len(b.events) >= b.maxBatchSize
+	b.mutex.Unlock()
+
+	if level == zerolog.FatalLevel || level == zerolog.PanicLevel {
+		_ = b.flush(context.Background())
+		return len(p), nil
+	}
+
+	if batchFull {
+		select {
+		case b.flushC <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// start sends the queued events every flush interval or once a batch is full, until stop is called
+func (b *logEventBatcher) start() {
+	go func() {
+		defer close(b.doneC)
+
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.stopC:
+				_ = b.flush(context.Background())
+				return
+			case <-ticker.C:
+			case <-b.flushC:
+			}
+			_ = b.flush(context.Background())
+		}
+	}()
+}
+
+// stop sends the queued events and ends the background goroutine
+func (b *logEventBatcher) stop() {
+	b.stopOnce.Do(func() {
+		close(b.stopC)
+	})
+	<-b.doneC
+}
+
+// flush sends the queued events in batches, stopping at the first batch that fails
+func (b *logEventBatcher) flush(ctx context.Context) error {
+	for {
+		b.mutex.Lock()
+		batchSize := len(b.events)
+		if batchSize > b.maxBatchSize {
+			batchSize = b.maxBatchSize
+		}
+		batch := b.events[:batchSize]
+		b.events = b.events[batchSize:]
+		b.mutex.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := b.send(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogEntry is a log event recorded by a LogRecorder
+type LogEntry struct {
+	Level   zerolog.Level
+	Message string
+	Fields  map[string]interface{}
+}
+
+// LogRecorder records the log events written to it as entries for assertions in tests
+type LogRecorder struct {
+	mutex   sync.Mutex
+	entries []LogEntry
+}
+
+// cleaner is the part of testing.TB CaptureLogs needs, so this package doesn't import testing
+type cleaner interface {
+	Cleanup(func())
+}
+
+// CaptureLogs replaces the global logger with one recording all events at any level, and restores the previous logger
+// and level when the test finishes; tests using it can't run in parallel, since the logger is global
+// logs := foundation.CaptureLogs(t)
+func CaptureLogs(t cleaner) *LogRecorder {
+	recorder := &LogRecorder{}
+
+	originalLogger := log.Logger
+	originalLevel := zerolog.GlobalLevel()
+	log.Logger = zerolog.New(recorder).With().Timestamp().Logger()
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+
+	t.Cleanup(func() {
+		log.Logger = originalLogger
+		zerolog.SetGlobalLevel(originalLevel)
+	})
+
+	return recorder
+}
+
+// Write parses the json log event into an entry; events that aren't json are recorded as message
+func (r *LogRecorder) Write(p []byte) (n int, err error) {
+	entry := LogEntry{
+		Level:  zerolog.NoLevel,
+		Fields: map[string]interface{}{},
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	if err := decoder.Decode(&entry.Fields); err != nil {
+		entry.Message = string(bytes.TrimSpace(p))
+	}
+
+	if levelString, ok := entry.Fields[zerolog.LevelFieldName].(string); ok {
+		if level, err := zerolog.ParseLevel(levelString); err == nil {
+			entry.Level = level
+		}
+		delete(entry.Fields, zerolog.LevelFieldName)
+	}
+	if message, ok := entry.Fields[zerolog.MessageFieldName].(string); ok {
+		entry.Message = message
+		delete(entry.Fields, zerolog.MessageFieldName)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, entry)
+
+	return len(p), nil
+}
+
+// Entries returns the recorded entries in the order they were logged
+func (r *LogRecorder) Entries() []LogEntry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]LogEntry{}, r.entries...)
+}
+
+// Messages returns the messages of the recorded entries, for asserting what got logged in which order
+func (r *LogRecorder) Messages() []string {
+	messages := []string{}
+	for _, entry := range r.Entries() {
+		messages = append(messages, entry.Message)
+	}
+
+	return messages
+}
+
+// Filter returns the recorded entries at level
+func (r *LogRecorder) Filter(level zerolog.Level) []LogEntry {
+	entries := []LogEntry{}
+	for _, entry := range r.Entries() {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
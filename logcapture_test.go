@@ -0,0 +1,45 @@
+package foundation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureLogs(t *testing.T) {
+	t.Run("RecordsEntriesWithLevelMessageAndFields", func(t *testing.T) {
+		logs := CaptureLogs(t)
+
+		// act
+		log.Debug().Str("orderId", "abc").Int("items", 2).Msg("Handling order")
+		log.Error().Msg("Failed")
+
+		entries := logs.Entries()
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, zerolog.DebugLevel, entries[0].Level)
+			assert.Equal(t, "Handling order", entries[0].Message)
+			assert.Equal(t, "abc", entries[0].Fields["orderId"])
+			assert.Equal(t, json.Number("2"), entries[0].Fields["items"])
+		}
+		assert.Equal(t, []string{"Handling order", "Failed"}, logs.Messages())
+		assert.Len(t, logs.Filter(zerolog.ErrorLevel), 1)
+	})
+
+	t.Run("RestoresLoggerAndLevelAfterTest", func(t *testing.T) {
+		originalLogger := log.Logger
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		defer zerolog.SetGlobalLevel(zerolog.TraceLevel)
+
+		// act
+		t.Run("Capturing", func(t *testing.T) {
+			CaptureLogs(t)
+			assert.Equal(t, zerolog.TraceLevel, zerolog.GlobalLevel())
+		})
+
+		assert.Equal(t, originalLogger, log.Logger)
+		assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	})
+}
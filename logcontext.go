@@ -0,0 +1,45 @@
+package foundation
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ContextWithFields returns a copy of the context with a logger that adds the fields to every log line, on top of the
+// fields of the logger already in the context; fields are key value pairs
+// ctx = ContextWithFields(ctx, "tenant", tenant, "orderId", orderID)
+func ContextWithFields(ctx context.Context, fields ...interface{}) context.Context {
+	logger := contextLogger(ctx)
+
+	return logger.With().Fields(fields).Logger().WithContext(ctx)
+}
+
+// LoggerFromContext returns the logger stored in the context by ContextWithFields or the RequestID middleware, or the
+// global logger if there is none; the trace and span id of the active span are added as traceId and spanId, or in the
+// fields cloud logging correlates with cloud trace for the stackdriver format
+// LoggerFromContext(ctx).Info().Msg("Handling order")
+func LoggerFromContext(ctx context.Context) *zerolog.Logger {
+	logger := contextLogger(ctx)
+
+	if traceID := traceIDFromContext(ctx); traceID != "" {
+		spanID := spanIDFromContext(ctx)
+		if getGlobalLogFormat() == LogFormatStackdriver {
+			logger = withStackdriverTrace(logger.With(), traceID, spanID).Logger()
+		} else {
+			logger = logger.With().Str("traceId", traceID).Str("spanId", spanID).Logger()
+		}
+	}
+
+	return &logger
+}
+
+// contextLogger returns a copy of the logger stored in the context or the global logger if there is none
+func contextLogger(ctx context.Context) zerolog.Logger {
+	if logger := log.Ctx(ctx); logger.GetLevel() != zerolog.Disabled {
+		return *logger
+	}
+
+	return log.Logger
+}
@@ -0,0 +1,56 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+	t.Run("ReturnsGlobalLoggerIfContextHasNone", func(t *testing.T) {
+		buffer := captureLogs(t)
+
+		// act
+		LoggerFromContext(context.Background()).Info().Msg("Handling order")
+
+		assert.Equal(t, `{"level":"info","message":"Handling order"}`+"\n", buffer.String())
+	})
+
+	t.Run("AddsFieldsFromContextOnEveryLogLine", func(t *testing.T) {
+		buffer := captureLogs(t)
+		ctx := ContextWithFields(context.Background(), "tenant", "estafette")
+		ctx = ContextWithFields(ctx, "orderId", 42)
+
+		// act
+		LoggerFromContext(ctx).Info().Msg("Handling order")
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "estafette", event["tenant"])
+			assert.Equal(t, float64(42), event["orderId"])
+		}
+	})
+
+	t.Run("AddsTraceIDOfActiveSpan", func(t *testing.T) {
+		buffer := captureLogs(t)
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+		defer closer.Close()
+		span := tracer.StartSpan("handle")
+		defer span.Finish()
+		ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+		// act
+		LoggerFromContext(ctx).Info().Msg("Handling order")
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, span.Context().(jaeger.SpanContext).TraceID().String(), event["traceId"])
+		}
+	})
+}
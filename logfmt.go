@@ -0,0 +1,140 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// logfmtWriter converts the json events written by zerolog into logfmt lines, with time, level and message first and
+// nested objects flattened into dotted keys
+type logfmtWriter struct {
+	writer io.Writer
+}
+
+func newLogfmtWriter(writer io.Writer) io.Writer {
+	return &logfmtWriter{writer: writer}
+}
+
+type logfmtField struct {
+	key   string
+	value string
+}
+
+func (lw *logfmtWriter) Write(p []byte) (n int, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	var fields []logfmtField
+	if err := decodeLogfmtObject(decoder, "", &fields); err != nil {
+		// not a json event, like output of the standard log library before logging got initialized
+		return lw.writer.Write(p)
+	}
+
+	var line strings.Builder
+	for _, key := range []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName} {
+		for _, field := range fields {
+			if field.key == key {
+				writeLogfmtField(&line, logfmtKey(key), field.value)
+			}
+		}
+	}
+	for _, field := range fields {
+		if field.key == zerolog.TimestampFieldName || field.key == zerolog.LevelFieldName || field.key == zerolog.MessageFieldName {
+			continue
+		}
+		writeLogfmtField(&line, field.key, field.value)
+	}
+	line.WriteByte('\n')
+
+	if _, err = io.WriteString(lw.writer, line.String()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// logfmtKey uses the conventional short logfmt key for the message
+func logfmtKey(key string) string {
+	if key == zerolog.MessageFieldName {
+		return "msg"
+	}
+	return key
+}
+
+// decodeLogfmtObject reads a json object from the decoder in order of its keys, flattening nested objects and
+// keeping arrays as json
+func decodeLogfmtObject(decoder *json.Decoder, prefix string, fields *[]logfmtField) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("Expected json object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		switch {
+		case len(raw) > 0 && raw[0] == '{':
+			if err := decodeLogfmtObject(json.NewDecoder(bytes.NewReader(raw)), key+".", fields); err != nil {
+				return err
+			}
+		case len(raw) > 0 && raw[0] == '"':
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			*fields = append(*fields, logfmtField{key: key, value: value})
+		case string(raw) == "null":
+			*fields = append(*fields, logfmtField{key: key})
+		default:
+			*fields = append(*fields, logfmtField{key: key, value: string(raw)})
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+func writeLogfmtField(line *strings.Builder, key, value string) {
+	if line.Len() > 0 {
+		line.WriteByte(' ')
+	}
+	line.WriteString(key)
+	line.WriteByte('=')
+
+	if needsLogfmtQuoting(value) {
+		line.WriteString(strconv.Quote(value))
+	} else {
+		line.WriteString(value)
+	}
+}
+
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
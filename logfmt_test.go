@@ -0,0 +1,61 @@
+package foundation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	t.Run("WritesTimeLevelAndMessageFirst", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		// act
+		_, err := newLogfmtWriter(&buffer).Write([]byte(`{"level":"info","count":3,"time":"2020-01-01T00:00:00Z","message":"Hello world"}`))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "time=2020-01-01T00:00:00Z level=info msg=\"Hello world\" count=3\n", buffer.String())
+	})
+
+	t.Run("FlattensNestedObjects", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		// act
+		_, err := newLogfmtWriter(&buffer).Write([]byte(`{"level":"info","kubernetes":{"podName":"app-1","namespace":"default"},"message":"hi"}`))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "level=info msg=hi kubernetes.podName=app-1 kubernetes.namespace=default\n", buffer.String())
+	})
+
+	t.Run("QuotesValuesWithSpecialCharacters", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		// act
+		_, err := newLogfmtWriter(&buffer).Write([]byte(`{"a":"","b":"x=y","c":"say \"hi\"","d":null,"e":["x","y"],"f":true}`))
+
+		assert.Nil(t, err)
+		assert.Equal(t, `a="" b="x=y" c="say \"hi\"" d="" e="[\"x\",\"y\"]" f=true`+"\n", buffer.String())
+	})
+
+	t.Run("WritesZerologEvents", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := zerolog.New(newLogfmtWriter(&buffer))
+
+		// act
+		logger.Warn().Str("path", "/api").Int("status", 404).Msg("Not found")
+
+		assert.Equal(t, "level=warn msg=\"Not found\" path=/api status=404\n", buffer.String())
+	})
+
+	t.Run("PassesThroughNonJSONInput", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		// act
+		_, err := newLogfmtWriter(&buffer).Write([]byte("plain line\n"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "plain line\n", buffer.String())
+	})
+}
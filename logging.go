@@ -1,21 +1,30 @@
 package foundation
 
 import (
+	stderrors "errors"
+	"fmt"
+	"io"
 	stdlog "log"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog/pkgerrors"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
 	// LogFormatPlainText outputs logs in plain text without colorization and with timestamp; is the default if log format isn't specified
 	LogFormatPlainText = "plaintext"
-	// LogFormatConsole outputs logs in plain text with colorization and without timestamp
+	// LogFormatConsole outputs logs in plain text, colorized on terminals and without timestamp and level unless configured with WithConsoleTheme
 	LogFormatConsole = "console"
 	// LogFormatJSON outputs logs in json including appgroup, app, appversion and other metadata
 	LogFormatJSON = "json"
@@ -23,47 +32,500 @@ const (
 	LogFormatStackdriver = "stackdriver"
 	// LogFormatV3 ouputs an internal format used at Travix in JSON format with nested payload and a specific set of required metadata
 	LogFormatV3 = "v3"
+	// LogFormatLogfmt outputs logs as key=value pairs in logfmt format, with nested fields flattened into dotted keys
+	LogFormatLogfmt = "logfmt"
+	// LogFormatCloudWatch outputs logs in json with the timestamp, level and message fields CloudWatch Logs Insights expects
+	LogFormatCloudWatch = "cloudwatch"
 )
 
+// LoggingConfig configures where logs are written to
+type LoggingConfig struct {
+	// Writer is where logs are written to, unless LogFile is set; defaults to os.Stdout
+	Writer io.Writer
+
+	LogFile *LogFileConfig
+
+	// LevelSignals enables switching the log level to debug with SIGUSR1 and back with SIGUSR2
+	LevelSignals bool
+
+	// Sampling limits the number of debug and info messages logged per period
+	Sampling *LogSamplingConfig
+
+	// AsyncBufferSize enables writing logs from a background goroutine, buffering up to this number of events
+	AsyncBufferSize int
+
+	// AdditionalWriters receive a copy of all logs in their own format
+	AdditionalWriters []AdditionalLogWriter
+
+	// Caller adds the file and line logging the message to all logs
+	Caller bool
+
+	// StackdriverSourceLocation adds the file, line and function logging the message in the field cloud logging uses,
+	// for the stackdriver format
+	StackdriverSourceLocation bool
+
+	// SplitStderr writes warnings and errors to stderr instead of stdout, when logging to stdout
+	SplitStderr bool
+
+	// Hooks run for every log event, to enrich it
+	Hooks []zerolog.Hook
+
+	// KubernetesMetadata adds the kubernetes runtime information to all formats, completing it from the service
+	// account and hostname where the downward api envvars are missing
+	KubernetesMetadata bool
+
+	// V3 configures the envelope of the v3 format
+	V3 V3Config
+
+	// ConsoleTheme configures the rendering of the console format
+	ConsoleTheme ConsoleTheme
+
+	// Kafka publishes all logs to a kafka topic as well
+	Kafka *KafkaLogConfig
+
+	// Journald writes all logs to the systemd journal as well
+	Journald bool
+
+	// WindowsEventLog writes warnings and errors to the windows event log as well, with the app name as source
+	WindowsEventLog bool
+
+	// DuplicateSuppressionWindow collapses identical messages logged in a row within this window
+	DuplicateSuppressionWindow time.Duration
+
+	// eventSinks receive the json log events regardless of the log format, for forwarding them elsewhere
+	eventSinks []io.Writer
+}
+
+// LogFileConfig configures a log file that gets rotated when reaching its maximum size
+type LogFileConfig struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// LogSamplingConfig configures how many debug and info messages get logged per period; the rest is dropped
+type LogSamplingConfig struct {
+	Burst  uint32
+	Period time.Duration
+}
+
+// AdditionalLogWriter is a destination logs are written to besides the output
+type AdditionalLogWriter struct {
+	Writer io.Writer
+	Format string
+}
+
+// LoggingOption allows to override logging config
+type LoggingOption func(*LoggingConfig)
+
+// WithLogWriter writes logs to writer instead of stdout, for example os.Stderr to keep stdout for the data a cli outputs,
+// or a buffer to capture them in tests
+func WithLogWriter(writer io.Writer) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Writer = writer
+	}
+}
+
+// WithSplitStderr writes warnings, errors and fatals to stderr while the other logs go to stdout, since log collectors
+// and kubectl treat the streams differently; it can be enabled with envvar ESTAFETTE_LOG_SPLIT_STDERR=true as well
+func WithSplitStderr() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.SplitStderr = true
+	}
+}
+
+// WithHooks adds hooks that run for every log event, so applications can enrich events while keeping the configuration
+// of the log format
+func WithHooks(hooks ...zerolog.Hook) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Hooks = append(c.Hooks, hooks...)
+	}
+}
+
+// WithLogFile writes logs to the file at path instead of stdout, rotating it once it reaches maxSizeMB and removing
+// rotated files when there's more than maxBackups or they're older than maxAgeDays; 0 keeps them all
+func WithLogFile(path string, maxSizeMB, maxBackups, maxAgeDays int) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.LogFile = &LogFileConfig{
+			Path:       path,
+			MaxSizeMB:  maxSizeMB,
+			MaxBackups: maxBackups,
+			MaxAgeDays: maxAgeDays,
+		}
+	}
+}
+
+// WithAdditionalWriter writes a copy of all logs to writer in format, for example a colorized console copy to stderr
+// with WithAdditionalWriter(os.Stderr, LogFormatConsole); the json based formats receive the events in the json layout
+// of the main log format
+func WithAdditionalWriter(writer io.Writer, format string) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.AdditionalWriters = append(c.AdditionalWriters, AdditionalLogWriter{
+			Writer: writer,
+			Format: format,
+		})
+	}
+}
+
+// WithLogSampling logs at most burst debug and info messages per period and drops the rest, so hot loops don't
+// overwhelm the output and the log pipeline; warnings and errors are never dropped
+func WithLogSampling(burst uint32, per time.Duration) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.Sampling = &LogSamplingConfig{
+			Burst:  burst,
+			Period: per,
+		}
+	}
+}
+
+func newLoggingConfig(opts ...LoggingOption) LoggingConfig {
+	// default
+	config := LoggingConfig{
+		Writer:                    os.Stdout,
+		LevelSignals:              strings.ToLower(os.Getenv("ESTAFETTE_LOG_LEVEL_SIGNALS")) == "true",
+		Caller:                    strings.ToLower(os.Getenv("ESTAFETTE_LOG_CALLER")) == "true",
+		SplitStderr:               strings.ToLower(os.Getenv("ESTAFETTE_LOG_SPLIT_STDERR")) == "true",
+		StackdriverSourceLocation: strings.ToLower(os.Getenv("ESTAFETTE_LOG_STACKDRIVER_SOURCE_LOCATION")) == "true",
+		ConsoleTheme:              consoleThemeFromEnv(),
+		Kafka:                     kafkaLogConfigFromEnv(),
+		Journald:                  strings.ToLower(os.Getenv("ESTAFETTE_LOG_JOURNALD")) == "true",
+		WindowsEventLog:           runtime.GOOS == "windows" && strings.ToLower(os.Getenv("ESTAFETTE_LOG_WINDOWS_EVENT_LOG")) == "true",
+		V3: V3Config{
+			MessageType:        defaultV3MessageType,
+			MessageTypeVersion: defaultV3MessageTypeVersion,
+		},
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// loggerOutput is the writer of a logger, with the log file and async writer opened for it
+type loggerOutput struct {
+	io.Writer
+	logFile *lumberjack.Logger
+	async   *asyncWriter
+	kafka   *kafkaLogExporter
+	sinks   []io.Closer
+}
+
+// WriteLevel passes the level of log events on to the writer, so writers like the event sinks can act on it
+func (o loggerOutput) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	return writeLevel(o.Writer, level, p)
+}
+
+// close writes the buffered log events and releases the log file
+func (o loggerOutput) close() {
+	if o.async != nil {
+		o.async.stop()
+	}
+	if o.logFile != nil {
+		o.logFile.Close()
+	}
+	for _, sink := range o.sinks {
+		sink.Close()
+	}
+}
+
+var (
+	globalLoggerOutputMutex sync.Mutex
+	globalLoggerOutput      loggerOutput
+	globalLogFormat         string
+)
+
+// setGlobalLoggerOutput keeps the output and format of the global logger and closes the output of a previous
+// initialization
+func setGlobalLoggerOutput(output loggerOutput, logFormat string) {
+	globalLoggerOutputMutex.Lock()
+	defer globalLoggerOutputMutex.Unlock()
+
+	globalLoggerOutput.close()
+	globalLoggerOutput = output
+	globalLogFormat = logFormat
+}
+
+// getGlobalLogFormat returns the format of the global logger
+func getGlobalLogFormat() string {
+	globalLoggerOutputMutex.Lock()
+	defer globalLoggerOutputMutex.Unlock()
+
+	return globalLogFormat
+}
+
+// newOutput returns the writer for a logger: the json events get formatted by format before being written to the
+// log file or writer, by their own format for the additional writers, while the event sinks receive them as json
+func (c LoggingConfig) newOutput(applicationInfo ApplicationInfo, format func(output io.Writer) io.Writer) (output loggerOutput) {
+	var writer io.Writer
+	switch {
+	case c.LogFile != nil:
+		output.logFile = &lumberjack.Logger{
+			Filename:   c.LogFile.Path,
+			MaxSize:    c.LogFile.MaxSizeMB,
+			MaxBackups: c.LogFile.MaxBackups,
+			MaxAge:     c.LogFile.MaxAgeDays,
+		}
+		writer = format(output.logFile)
+	case c.Writer != nil && c.Writer != os.Stdout:
+		writer = format(c.Writer)
+	case c.SplitStderr:
+		writer = newLevelSplitWriter(format(os.Stdout), format(os.Stderr), zerolog.WarnLevel)
+	default:
+		writer = format(os.Stdout)
+	}
+
+	sinks := append([]io.Writer{}, c.eventSinks...)
+	if c.WindowsEventLog {
+		if eventLog, err := newWindowsEventLogWriter(applicationInfo.App); err == nil {
+			output.sinks = append(output.sinks, eventLog)
+			sinks = append(sinks, eventLog)
+		} else {
+			fmt.Fprintf(os.Stderr, "Opening windows event log for source %v failed: %v\n", applicationInfo.App, err)
+		}
+	}
+
+	if c.Journald {
+		if journal, err := newJournaldWriter(applicationInfo.App); err == nil {
+			output.sinks = append(output.sinks, journal)
+			sinks = append(sinks, journal)
+		} else {
+			fmt.Fprintf(os.Stderr, "Connecting to journald failed: %v\n", err)
+		}
+	}
+
+	if c.Kafka != nil {
+		output.kafka = newKafkaLogExporter(applicationInfo, *c.Kafka)
+		output.sinks = append(output.sinks, output.kafka)
+		sinks = append(sinks, output.kafka)
+	}
+
+	if len(c.AdditionalWriters) > 0 || len(sinks) > 0 {
+		writers := []io.Writer{writer}
+		for _, additionalWriter := range c.AdditionalWriters {
+			writers = append(writers, c.formatterForLogFormat(additionalWriter.Format)(additionalWriter.Writer))
+		}
+		for _, sink := range sinks {
+			writers = append(writers, newRedactingWriter(sink))
+		}
+		writer = zerolog.MultiLevelWriter(writers...)
+	}
+
+	if c.AsyncBufferSize > 0 {
+		output.async = newAsyncWriter(writer, c.AsyncBufferSize)
+		writer = output.async
+	}
+
+	output.Writer = writer
+
+	return output
+}
+
+// levelSplitWriter writes log events from a minimum level up to another writer, like warnings and errors to stderr
+type levelSplitWriter struct {
+	writer      io.Writer
+	levelWriter io.Writer
+	minLevel    zerolog.Level
+}
+
+func newLevelSplitWriter(writer, levelWriter io.Writer, minLevel zerolog.Level) zerolog.LevelWriter {
+	return &levelSplitWriter{
+		writer:      writer,
+		levelWriter: levelWriter,
+		minLevel:    minLevel,
+	}
+}
+
+func (w *levelSplitWriter) Write(p []byte) (n int, err error) {
+	return w.writer.Write(p)
+}
+
+func (w *levelSplitWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	// events without level, like the ones from the standard log library, aren't errors
+	if level >= w.minLevel && level != zerolog.NoLevel && level != zerolog.Disabled {
+		return w.levelWriter.Write(p)
+	}
+	return w.writer.Write(p)
+}
+
+// configureLogger applies the config that isn't specific to the log format to the logger
+func (c LoggingConfig) configureLogger(logger zerolog.Logger) zerolog.Logger {
+	// count messages before duplicates get suppressed, so the error rate doesn't depend on it
+	logger = logger.Hook(logMetricsHook{})
+
+	if c.Caller {
+		logger = logger.Hook(callerHook{})
+	}
+
+	for _, hook := range c.Hooks {
+		logger = logger.Hook(hook)
+	}
+
+	if c.DuplicateSuppressionWindow > 0 {
+		logger = suppressDuplicates(logger, c.DuplicateSuppressionWindow)
+	}
+
+	if c.Sampling != nil {
+		sampler := &zerolog.BurstSampler{
+			Burst:  c.Sampling.Burst,
+			Period: c.Sampling.Period,
+		}
+		logger = logger.Sample(zerolog.LevelSampler{
+			DebugSampler: sampler,
+			InfoSampler:  sampler,
+		})
+	}
+
+	return logger
+}
+
+// withEventSink adds a writer receiving all log events as json
+func withEventSink(sink io.Writer) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.eventSinks = append(c.eventSinks, sink)
+	}
+}
+
+// WithLogLevelSignals makes SIGUSR1 switch the log level to debug and SIGUSR2 restore the configured level, for
+// debugging a running process without restarting it; it can be enabled with envvar ESTAFETTE_LOG_LEVEL_SIGNALS=true as well
+func WithLogLevelSignals() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.LevelSignals = true
+	}
+}
+
+// WithLogStackdriverSourceLocation adds the file, line and function logging the message to all logs in the stackdriver
+// format, so the gcp console links to the source; it looks up the caller for every message, so it can be enabled with
+// envvar ESTAFETTE_LOG_STACKDRIVER_SOURCE_LOCATION=true as well
+func WithLogStackdriverSourceLocation() LoggingOption {
+	return func(c *LoggingConfig) {
+		c.StackdriverSourceLocation = true
+	}
+}
+
 // InitLoggingFromEnv initalializes a logger with format specified in envvar ESTAFETTE_LOG_FORMAT and outputs a startup message
-func InitLoggingFromEnv(applicationInfo ApplicationInfo) {
-	InitLoggingByFormat(applicationInfo, os.Getenv("ESTAFETTE_LOG_FORMAT"))
+func InitLoggingFromEnv(applicationInfo ApplicationInfo, opts ...LoggingOption) {
+	InitLoggingByFormat(applicationInfo, os.Getenv("ESTAFETTE_LOG_FORMAT"), opts...)
 }
 
 // InitLoggingByFormat initalializes a logger with specified format and outputs a startup message
-func InitLoggingByFormat(applicationInfo ApplicationInfo, logFormat string) {
+func InitLoggingByFormat(applicationInfo ApplicationInfo, logFormat string, opts ...LoggingOption) {
 
 	// configure logger
-	InitLoggingByFormatSilent(applicationInfo, logFormat)
+	InitLoggingByFormatSilent(applicationInfo, logFormat, opts...)
 
 	// set global logging level
 	SetLoggingLevelFromEnv()
 
+	// size the go runtime to the container limits before they're logged
+	TuneRuntimeFromCgroup()
+
 	// output startup message
 	switch logFormat {
 	case LogFormatV3:
 		logStartupMessageV3(applicationInfo)
+	case LogFormatConsole:
+		logStartupMessageConsole(applicationInfo)
 	default:
 		logStartupMessage(applicationInfo)
 	}
+
+	// handle the signals once the startup message is out, so a warning about them isn't the first log line
+	if config := newLoggingConfig(opts...); config.LevelSignals {
+		handleLogLevelSignals(zerolog.GlobalLevel())
+	}
+
+	// warn about build information that hasn't been injected
+	if invalidFields := applicationInfo.Validate(); len(invalidFields) > 0 {
+		log.Warn().
+			Strs("fields", invalidFields).
+			Msgf("Application info fields %v are empty or contain a placeholder value", strings.Join(invalidFields, ", "))
+	}
 }
 
 // InitLoggingByFormatSilent initializes a logger with specified format without outputting a startup message
-func InitLoggingByFormatSilent(applicationInfo ApplicationInfo, logFormat string) {
+func InitLoggingByFormatSilent(applicationInfo ApplicationInfo, logFormat string, opts ...LoggingOption) {
+
+	config := newLoggingConfig(opts...)
+
+	// keep application info for the /info endpoint
+	setApplicationInfo(applicationInfo)
 
 	// configure logger
+	output := config.newOutput(applicationInfo, config.formatterForLogFormat(logFormat))
+	log.Logger = newLogger(applicationInfo, logFormat, config, output)
+
+	// release the output of a previous initialization
+	setGlobalLoggerOutput(output, logFormat)
+
+	// use zerolog for any logs sent via standard log library
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(log.Logger)
+
+	PublishLifecycleEvent(EventLoggingInitialized, map[string]string{"format": logFormat})
+}
+
+// NewLogger returns a logger with specified format without changing the global logger, for libraries and binaries that
+// need isolated loggers; zerolog's field names and time format are global though, so all loggers should use the same
+// format. FlushLogs doesn't flush loggers with the WithAsyncLogging option, log with level fatal to flush them
+func NewLogger(applicationInfo ApplicationInfo, logFormat string, opts ...LoggingOption) zerolog.Logger {
+	config := newLoggingConfig(opts...)
+
+	return newLogger(applicationInfo, logFormat, config, config.newOutput(applicationInfo, config.formatterForLogFormat(logFormat)))
+}
+
+// ComponentLogger returns a child of the global logger with the name in the standard component field, so all parts of
+// an application identify themselves the same way; the child keeps the format, output and options of the global logger,
+// so call it after initializing logging instead of in a package level var, which is initialized before
+// func NewScheduler() *Scheduler { return &Scheduler{log: foundation.ComponentLogger("scheduler")} }
+func ComponentLogger(name string) zerolog.Logger {
+	return log.Logger.With().Str("component", name).Logger()
+}
+
+// newLogger returns a logger with specified format writing to output
+func newLogger(applicationInfo ApplicationInfo, logFormat string, config LoggingConfig, output io.Writer) zerolog.Logger {
+	runtimeInfo := applicationInfo.Runtime()
+	if config.KubernetesMetadata {
+		runtimeInfo = completeKubernetesMetadata(runtimeInfo)
+	}
+
+	// convert payloads added with V3Payload with the marshaler of this logger
+	output = newV3PayloadWriter(output, config.V3.PayloadMarshaler)
+
+	var logger zerolog.Logger
 	switch logFormat {
 	case LogFormatJSON:
-		initLoggingJSON(applicationInfo)
+		logger = newLoggerJSON(applicationInfo, runtimeInfo, output)
 	case LogFormatStackdriver:
-		initLoggingStackdriver(applicationInfo)
+		logger = newLoggerStackdriver(applicationInfo, runtimeInfo, output)
+		if config.StackdriverSourceLocation {
+			logger = logger.Hook(sourceLocationHook{})
+		}
 	case LogFormatV3:
-		initLoggingV3(applicationInfo)
+		logger = newLoggerV3(applicationInfo, runtimeInfo, config.V3, output)
 	case LogFormatConsole:
-		initLoggingConsole(applicationInfo)
+		logger = newLoggerConsole(applicationInfo, output)
+		if config.ConsoleTheme.Timestamp {
+			logger = logger.With().Timestamp().Logger()
+		}
+		if config.KubernetesMetadata {
+			logger = withRuntimeInfo(logger.With(), runtimeInfo).Logger()
+		}
+	case LogFormatLogfmt:
+		logger = newLoggerLogfmt(applicationInfo, runtimeInfo, output)
+	case LogFormatCloudWatch:
+		logger = newLoggerCloudWatch(applicationInfo, runtimeInfo, output)
 	default: // LogFormatPlainText
-		initLoggingPlainText(applicationInfo)
+		logger = newLoggerPlainText(applicationInfo, output)
+		if config.KubernetesMetadata {
+			logger = withRuntimeInfo(logger.With(), runtimeInfo).Logger()
+		}
 	}
+
+	return config.configureLogger(logger)
 }
 
 // SetLoggingLevelFromEnv sets the logging level from which log messages and higher are outputted via envvar ESTAFETTE_LOG_LEVEL
@@ -90,72 +552,128 @@ func SetLoggingLevelFromEnv() {
 	}
 }
 
-// initLoggingStackdriver outputs a format similar to JSON format but with 'severity' instead of 'level' field
-func initLoggingStackdriver(applicationInfo ApplicationInfo) {
+// newLoggerStackdriver outputs a format similar to JSON format but with 'severity' instead of 'level' field
+func newLoggerStackdriver(applicationInfo ApplicationInfo, runtimeInfo RuntimeInfo, output io.Writer) zerolog.Logger {
 
 	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.999Z"
 	zerolog.TimestampFieldName = "timestamp"
 	zerolog.LevelFieldName = "severity"
+	zerolog.ErrorMarshalFunc = marshalErrorChain
 
 	// set some default fields added to all logs
-	log.Logger = zerolog.New(os.Stdout).With().
-		Timestamp().
+	return withErrorStack(withRuntimeInfo(zerolog.New(newErrorChainWriter(output)).With().
+		Timestamp(), runtimeInfo)).
 		Logger()
+}
 
-	// use zerolog for any logs sent via standard log library
-	stdlog.SetFlags(0)
-	stdlog.SetOutput(log.Logger)
+// newLoggerJSON outputs logs in json including appgroup, app, appversion and other metadata
+func newLoggerJSON(applicationInfo ApplicationInfo, runtimeInfo RuntimeInfo, output io.Writer) zerolog.Logger {
+
+	zerolog.ErrorMarshalFunc = marshalErrorChain
+
+	// set some default fields added to all logs
+	return withErrorStack(withRuntimeInfo(zerolog.New(newErrorChainWriter(output)).With().
+		Timestamp(), runtimeInfo)).
+		Logger()
 }
 
-// initLoggingJSON outputs logs in json including appgroup, app, appversion and other metadata
-func initLoggingJSON(applicationInfo ApplicationInfo) {
+// newLoggerLogfmt outputs logs as key=value pairs in logfmt format, with nested fields flattened into dotted keys
+func newLoggerLogfmt(applicationInfo ApplicationInfo, runtimeInfo RuntimeInfo, output io.Writer) zerolog.Logger {
 
 	// set some default fields added to all logs
-	log.Logger = zerolog.New(os.Stdout).With().
-		Timestamp().
+	return withRuntimeInfo(zerolog.New(output).With().
+		Timestamp(), runtimeInfo).
 		Logger()
+}
 
-	// use zerolog for any logs sent via standard log library
-	stdlog.SetFlags(0)
-	stdlog.SetOutput(log.Logger)
+// newLoggerConsole outputs logs in plain text with colorization and without timestamp
+func newLoggerConsole(applicationInfo ApplicationInfo, output io.Writer) zerolog.Logger {
+
+	return zerolog.New(output).With().Logger()
+}
+
+// newLoggerPlainText outputs logs in plain text without colorization and with timestamp; is the default if log format isn't specified
+func newLoggerPlainText(applicationInfo ApplicationInfo, output io.Writer) zerolog.Logger {
+
+	return zerolog.New(output).With().Logger()
 }
 
-// initLoggingConsole outputs logs in plain text with colorization and without timestamp
-func initLoggingConsole(applicationInfo ApplicationInfo) {
+// formatterForLogFormat returns the function converting the json log events to the log format
+func (c LoggingConfig) formatterForLogFormat(logFormat string) func(output io.Writer) io.Writer {
+	switch logFormat {
+	case LogFormatJSON, LogFormatStackdriver, LogFormatV3, LogFormatCloudWatch:
+		return formatJSON
+	case LogFormatConsole:
+		return c.ConsoleTheme.format
+	case LogFormatLogfmt:
+		return formatLogfmt
+	default: // LogFormatPlainText
+		return formatPlainText
+	}
+}
+
+// formatJSON writes the json log events as is
+func formatJSON(output io.Writer) io.Writer {
+	return newRedactingWriter(output)
+}
 
-	output := zerolog.ConsoleWriter{
-		Out:     os.Stdout,
-		NoColor: false,
+// formatLogfmt converts the json log events to logfmt
+func formatLogfmt(output io.Writer) io.Writer {
+	return newRedactingWriter(newLogfmtWriter(output))
+}
+
+// formatPlainText converts the json log events to plain text without colorization and with timestamp
+func formatPlainText(output io.Writer) io.Writer {
+	return zerolog.ConsoleWriter{
+		Out:     newRedactingWriter(output),
+		NoColor: true,
 	}
-	output.FormatTimestamp = func(i interface{}) string {
-		return ""
+}
+
+// withRuntimeInfo adds the kubernetes runtime information as a nested object to all logs, if available
+func withRuntimeInfo(context zerolog.Context, runtimeInfo RuntimeInfo) zerolog.Context {
+	if !runtimeInfo.IsAvailable() {
+		return context
 	}
-	output.FormatCaller = func(i interface{}) string {
-		return ""
+
+	dict := zerolog.Dict()
+	if runtimeInfo.PodName != "" {
+		dict.Str("pod", runtimeInfo.PodName)
 	}
-	output.FormatLevel = func(i interface{}) string {
-		return ""
+	if runtimeInfo.Namespace != "" {
+		dict.Str("namespace", runtimeInfo.Namespace)
+	}
+	if runtimeInfo.NodeName != "" {
+		dict.Str("node", runtimeInfo.NodeName)
+	}
+	if runtimeInfo.ContainerName != "" {
+		dict.Str("container", runtimeInfo.ContainerName)
+	}
+	if runtimeInfo.ServiceAccount != "" {
+		dict.Str("serviceAccount", runtimeInfo.ServiceAccount)
 	}
 
-	log.Logger = zerolog.New(output).With().Logger()
+	return context.Dict("kubernetes", dict)
+}
 
-	// use zerolog for any logs sent via standard log library
-	stdlog.SetFlags(0)
-	stdlog.SetOutput(log.Logger)
+// withErrorStack adds the stack trace of errors logged with Err() as structured stack field, if the error or one it
+// wraps has a stack trace recorded by github.com/pkg/errors
+func withErrorStack(context zerolog.Context) zerolog.Context {
+	zerolog.ErrorStackMarshaler = marshalErrorStack
+
+	return context.Stack()
 }
 
-// initLoggingPlainText outputs logs in plain text without colorization and with timestamp; is the default if log format isn't specified
-func initLoggingPlainText(applicationInfo ApplicationInfo) {
-	output := zerolog.ConsoleWriter{
-		Out:     os.Stdout,
-		NoColor: true,
+func marshalErrorStack(err error) interface{} {
+	var stackTracer interface {
+		error
+		StackTrace() errors.StackTrace
+	}
+	if !stderrors.As(err, &stackTracer) {
+		return nil
 	}
 
-	log.Logger = zerolog.New(output).With().Logger()
-
-	// use zerolog for any logs sent via standard log library
-	stdlog.SetFlags(0)
-	stdlog.SetOutput(log.Logger)
+	return pkgerrors.MarshalStack(stackTracer)
 }
 
 var (
@@ -163,7 +681,8 @@ var (
 )
 
 type v3Error struct {
-	Message string `json:"message"`
+	Message string     `json:"message"`
+	Chain   errorLinks `json:"chain,omitempty"`
 }
 
 type messageIDHook struct{}
@@ -173,8 +692,8 @@ func (h messageIDHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
 	e.Uint64("sequenceid", atomic.AddUint64(&sequenceID, 1))
 }
 
-// initLoggingV3 ouputs an internal format used at Travix in JSON format with nested payload and a specific set of required metadata
-func initLoggingV3(applicationInfo ApplicationInfo) {
+// newLoggerV3 ouputs an internal format used at Travix in JSON format with nested payload and a specific set of required metadata
+func newLoggerV3(applicationInfo ApplicationInfo, runtimeInfo RuntimeInfo, v3Config V3Config, output io.Writer) zerolog.Logger {
 
 	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.999Z"
 	zerolog.TimestampFieldName = "timestamp"
@@ -217,27 +736,28 @@ func initLoggingV3(applicationInfo ApplicationInfo) {
 		hostname,
 	}
 
-	// set some default fields added to all logs
-	log.Logger = zerolog.New(os.Stdout).Hook(messageIDHook{}).With().
-		Timestamp().
-		Str("logformat", "v3").
-		Str("messagetype", "estafette").
-		Str("messagetypeversion", "0.0.0").
-		Interface("source", source).
-		Logger()
-
-	// Have the error message under and object in "error" instead of in a raw string.
+	// Have the error message and the chain of errors it wraps under an object in "error" instead of in a raw string.
 	zerolog.ErrorMarshalFunc = func(err error) interface{} {
 		if err == nil {
 			return nil
 		}
 
-		return v3Error{err.Error()}
+		v3Err := v3Error{Message: err.Error()}
+		if chain := errorChain(err); len(chain) > 1 {
+			v3Err.Chain = chain
+		}
+
+		return v3Err
 	}
 
-	// use zerolog for any logs sent via standard log library
-	stdlog.SetFlags(0)
-	stdlog.SetOutput(log.Logger)
+	// set some default fields added to all logs
+	return withErrorStack(withRuntimeInfo(zerolog.New(output).Hook(messageIDHook{}).With().
+		Timestamp().
+		Str("logformat", "v3").
+		Str("messagetype", v3Config.MessageType).
+		Str("messagetypeversion", v3Config.MessageTypeVersion).
+		Interface("source", source), runtimeInfo)).
+		Logger()
 }
 
 // logStartupMessage logs a default startup message for any Estafette application
@@ -248,34 +768,60 @@ func logStartupMessage(applicationInfo ApplicationInfo) {
 		Str("buildDate", applicationInfo.BuildDate).
 		Str("goVersion", applicationInfo.GoVersion()).
 		Str("os", applicationInfo.OperatingSystem()).
+		Func(withResourceLimits(applicationInfo.Runtime())).
 		Msgf("Starting %v version %v...", applicationInfo.App, applicationInfo.Version)
 }
 
-// logStartupMessageConsole logs a default startup message for any Estafette application in bold
+// logStartupMessageConsole logs a multi-line startup banner for any Estafette application with the app name and version in bold
 func logStartupMessageConsole(applicationInfo ApplicationInfo) {
+	banner := applicationInfo.Banner()
+	title := fmt.Sprintf("Starting %v version %v...", applicationInfo.App, applicationInfo.Version)
+
 	log.Info().
-		Str("branch", applicationInfo.Branch).
-		Str("revision", applicationInfo.Revision).
-		Str("buildDate", applicationInfo.BuildDate).
-		Str("goVersion", applicationInfo.GoVersion()).
-		Str("os", applicationInfo.OperatingSystem()).
-		Msg(aurora.Sprintf("Starting %v version %v...", aurora.Bold(applicationInfo.App), aurora.Bold(applicationInfo.Version)))
+		Func(withResourceLimits(applicationInfo.Runtime())).
+		Msg(strings.Replace(banner, title, aurora.Sprintf("Starting %v version %v...", aurora.Bold(applicationInfo.App), aurora.Bold(applicationInfo.Version)), 1))
+}
+
+// withResourceLimits adds the container cpu and memory limits and the go runtime settings to an event
+func withResourceLimits(runtimeInfo RuntimeInfo) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		if runtimeInfo.CPULimit != "" {
+			e.Str("cpuLimit", runtimeInfo.CPULimit)
+		}
+		if runtimeInfo.MemoryLimit != "" {
+			e.Str("memoryLimit", runtimeInfo.MemoryLimit)
+		}
+
+		resourceInfo := GetResourceInfo()
+		e.Float64("cgroupCpuQuota", resourceInfo.CPUQuota).
+			Int64("cgroupMemoryLimit", resourceInfo.MemoryLimit).
+			Int("numCpu", resourceInfo.NumCPU).
+			Int("gomaxprocs", resourceInfo.GOMAXPROCS).
+			Str("gogc", resourceInfo.GOGC).
+			Str("gomemlimit", resourceInfo.GOMEMLIMIT)
+	}
 }
 
 // logStartupMessageV3 logs a v3 startup message for any Estafette application
 func logStartupMessageV3(applicationInfo ApplicationInfo) {
 	startupProps := struct {
-		Branch    string `json:"branch"`
-		Revision  string `json:"revision"`
-		BuildDate string `json:"buildDate"`
-		GoVersion string `json:"goVersion"`
-		Os        string `json:"os"`
+		Branch      string       `json:"branch"`
+		Revision    string       `json:"revision"`
+		BuildDate   string       `json:"buildDate"`
+		GoVersion   string       `json:"goVersion"`
+		Os          string       `json:"os"`
+		CPULimit    string       `json:"cpuLimit,omitempty"`
+		MemoryLimit string       `json:"memoryLimit,omitempty"`
+		Resources   ResourceInfo `json:"resources"`
 	}{
 		applicationInfo.Branch,
 		applicationInfo.Revision,
 		applicationInfo.BuildDate,
 		applicationInfo.GoVersion(),
 		applicationInfo.OperatingSystem(),
+		applicationInfo.Runtime().CPULimit,
+		applicationInfo.Runtime().MemoryLimit,
+		GetResourceInfo(),
 	}
 
 	log.Info().
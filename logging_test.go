@@ -0,0 +1,213 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	stdlog "log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func restoreLogger(t *testing.T) {
+	logger := log.Logger
+	t.Cleanup(func() {
+		log.Logger = logger
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	})
+}
+
+func TestInitLoggingByFormatSilent(t *testing.T) {
+	t.Run("WritesToLogFile", func(t *testing.T) {
+		restoreLogger(t)
+		path := filepath.Join(t.TempDir(), "app.log")
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogFile(path, 10, 3, 7))
+
+		// act
+		log.Info().Msg("written to file")
+
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), `"message":"written to file"`)
+	})
+	t.Run("WritesToLogWriter", func(t *testing.T) {
+		restoreLogger(t)
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatLogfmt, WithLogWriter(&buffer))
+
+		// act
+		log.Info().Msg("written to buffer")
+
+		assert.Contains(t, buffer.String(), `level=info msg="written to buffer"`)
+	})
+
+	t.Run("RunsHooksForEveryEvent", func(t *testing.T) {
+		restoreLogger(t)
+		var buffer bytes.Buffer
+		hook := zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, msg string) {
+			e.Str("tenant", "estafette")
+		})
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatLogfmt, WithLogWriter(&buffer), WithHooks(hook))
+
+		// act
+		log.Info().Msg("enriched")
+
+		assert.Contains(t, buffer.String(), `level=info msg=enriched`)
+		assert.Contains(t, buffer.String(), `tenant=estafette`)
+	})
+
+	t.Run("SamplesDebugAndInfoMessages", func(t *testing.T) {
+		restoreLogger(t)
+		path := filepath.Join(t.TempDir(), "app.log")
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogFile(path, 10, 3, 7), WithLogSampling(2, time.Minute))
+
+		// act
+		for i := 0; i < 5; i++ {
+			log.Info().Msg("hot loop")
+			log.Warn().Msg("warning")
+		}
+
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, strings.Count(string(content), `"message":"hot loop"`))
+		assert.Equal(t, 5, strings.Count(string(content), `"message":"warning"`))
+	})
+	t.Run("WritesCopyToAdditionalWriterInItsOwnFormat", func(t *testing.T) {
+		restoreLogger(t)
+		path := filepath.Join(t.TempDir(), "app.log")
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogFile(path, 10, 3, 7), WithAdditionalWriter(&buffer, LogFormatLogfmt))
+
+		// act
+		log.Info().Str("user", "estafette").Msg("written twice")
+
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), `"message":"written twice"`)
+		assert.Contains(t, buffer.String(), `level=info msg="written twice"`)
+		assert.Contains(t, buffer.String(), `user=estafette`)
+	})
+	t.Run("AddsCallerOutsideOfFoundationPackage", func(t *testing.T) {
+		restoreLogger(t)
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&buffer), WithCaller())
+
+		// act
+		log.Info().Msg("with caller")
+		stdlog.Print("with caller from standard log library")
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.Regexp(t, `"caller":"[^"]*logging_test.go:\d+"`, lines[0])
+			assert.Regexp(t, `"caller":"[^"]*logging_test.go:\d+"`, lines[1])
+		}
+	})
+	t.Run("AddsStackOfPkgErrorsToJSONFormats", func(t *testing.T) {
+		restoreLogger(t)
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&buffer))
+
+		// act
+		log.Error().Err(fmt.Errorf("Wrapped: %w", errors.New("failed"))).Msg("with stack")
+		log.Error().Err(fmt.Errorf("failed")).Msg("without stack")
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			var event map[string]interface{}
+			err := json.Unmarshal([]byte(lines[0]), &event)
+			if assert.Nil(t, err) && assert.IsType(t, []interface{}{}, event["stack"]) {
+				frame := event["stack"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, "logging_test.go", frame["source"])
+			}
+			assert.NotContains(t, lines[1], `"stack"`)
+		}
+	})
+
+	t.Run("PassesLevelToEventSinks", func(t *testing.T) {
+		restoreLogger(t)
+		sink := &levelRecordingWriter{}
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&bytes.Buffer{}), withEventSink(sink))
+
+		// act
+		log.Warn().Msg("warning")
+		log.Error().Msg("error")
+
+		assert.Equal(t, []zerolog.Level{zerolog.WarnLevel, zerolog.ErrorLevel}, sink.levels)
+	})
+}
+
+// levelRecordingWriter records the level of every event written with WriteLevel
+type levelRecordingWriter struct {
+	bytes.Buffer
+	levels []zerolog.Level
+}
+
+func (w *levelRecordingWriter) Write(p []byte) (n int, err error) {
+	w.levels = append(w.levels, zerolog.NoLevel)
+	return w.Buffer.Write(p)
+}
+
+func (w *levelRecordingWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	w.levels = append(w.levels, level)
+	return w.Buffer.Write(p)
+}
+
+func TestNewLogger(t *testing.T) {
+	t.Run("DoesNotChangeGlobalLogger", func(t *testing.T) {
+		restoreLogger(t)
+		globalLogger := log.Logger
+		var buffer bytes.Buffer
+
+		// act
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatLogfmt, WithLogWriter(&buffer))
+
+		logger.Info().Msg("isolated")
+		assert.Contains(t, buffer.String(), `level=info msg=isolated`)
+		assert.Equal(t, globalLogger, log.Logger)
+	})
+}
+
+func TestComponentLogger(t *testing.T) {
+	t.Run("AddsComponentFieldToGlobalLogger", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		InitLoggingByFormatSilent(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&buffer))
+
+		// act
+		logger := ComponentLogger("scheduler")
+
+		logger.Info().Msg("Scheduling")
+		assert.Contains(t, buffer.String(), `"component":"scheduler"`)
+		assert.Contains(t, buffer.String(), `"message":"Scheduling"`)
+	})
+}
+
+func TestLevelSplitWriter(t *testing.T) {
+	t.Run("WritesWarningsAndHigherToLevelWriter", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		logger := zerolog.New(newLevelSplitWriter(&stdout, &stderr, zerolog.WarnLevel))
+
+		// act
+		logger.Debug().Msg("debug")
+		logger.Info().Msg("info")
+		logger.Warn().Msg("warn")
+		logger.Error().Msg("error")
+		logger.Log().Msg("nolevel")
+
+		assert.Equal(t, `{"level":"debug","message":"debug"}
+{"level":"info","message":"info"}
+{"message":"nolevel"}
+`, stdout.String())
+		assert.Equal(t, `{"level":"warn","message":"warn"}
+{"level":"error","message":"error"}
+`, stderr.String())
+	})
+}
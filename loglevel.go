@@ -0,0 +1,32 @@
+package foundation
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogLevelHandler responds with the global log level and changes it when requested with PUT or POST and a level
+// parameter, to debug a running application without restarting it
+// curl -X PUT localhost:9101/debug/loglevel?level=debug
+func LogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		level, err := zerolog.ParseLevel(r.FormValue("level"))
+		if err != nil || r.FormValue("level") == "" {
+			http.Error(w, fmt.Sprintf("Invalid log level %q", r.FormValue("level")), http.StatusBadRequest)
+			return
+		}
+		zerolog.SetGlobalLevel(level)
+		log.Info().Msgf("Changed log level to %v", level)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fmt.Fprintf(w, "%v\n", zerolog.GlobalLevel())
+}
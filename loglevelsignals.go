@@ -0,0 +1,68 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logLevelSignalsMutex sync.Mutex
+	logLevelSignalsStop  func()
+)
+
+// handleLogLevelSignals switches the global log level to debug on SIGUSR1 and back to the configured level on SIGUSR2,
+// replacing a previously installed handler; the returned func stops handling the signals and waits for the handler to
+// finish
+func handleLogLevelSignals(configuredLevel zerolog.Level) (stop func()) {
+	logLevelSignalsMutex.Lock()
+	defer logLevelSignalsMutex.Unlock()
+
+	if logLevelSignalsStop != nil {
+		logLevelSignalsStop()
+	}
+
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer close(doneC)
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-stopC:
+				return
+			case s := <-signals:
+				switch s {
+				case syscall.SIGUSR1:
+					zerolog.SetGlobalLevel(zerolog.DebugLevel)
+					log.Info().Msgf("Received signal %v, changed log level to debug", s)
+				case syscall.SIGUSR2:
+					zerolog.SetGlobalLevel(configuredLevel)
+					log.Info().Msgf("Received signal %v, restored log level to %v", s, configuredLevel)
+				}
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	stop = func() {
+		stopOnce.Do(func() {
+			close(stopC)
+			<-doneC
+		})
+	}
+	logLevelSignalsStop = stop
+
+	return stop
+}
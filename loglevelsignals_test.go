@@ -0,0 +1,38 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleLogLevelSignals(t *testing.T) {
+	t.Run("SwitchesToDebugOnSIGUSR1AndRestoresOnSIGUSR2", func(t *testing.T) {
+		restoreLogger(t)
+		var buffer lockedBuffer
+		log.Logger = zerolog.New(&buffer)
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		stop := handleLogLevelSignals(zerolog.InfoLevel)
+		defer stop()
+
+		// act
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(buffer.String(), "changed log level to debug")
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		assert.Eventually(t, func() bool {
+			return strings.Contains(buffer.String(), "restored log level to info")
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	})
+}
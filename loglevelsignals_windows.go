@@ -0,0 +1,13 @@
+package foundation
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// handleLogLevelSignals isn't supported on windows since it has no SIGUSR1 and SIGUSR2 signals
+func handleLogLevelSignals(configuredLevel zerolog.Level) (stop func()) {
+	log.Warn().Msg("Changing the log level with SIGUSR1 and SIGUSR2 is not supported on windows")
+
+	return func() {}
+}
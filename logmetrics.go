@@ -0,0 +1,29 @@
+package foundation
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// logMessagesTotal counts log messages by level; it's exposed once InitMetrics is called, so alerts can use the error
+// log rate without parsing logs
+var logMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "log_messages_total",
+	Help: "Total number of log messages by level.",
+}, []string{"level"})
+
+// logMetricsHook increments log_messages_total for every message logged at a level
+type logMetricsHook struct{}
+
+func (h logMetricsHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level == zerolog.NoLevel || level == zerolog.Disabled {
+		return
+	}
+
+	logMessagesTotal.WithLabelValues(level.String()).Inc()
+}
+
+// registerLogMetrics exposes log_messages_total
+func registerLogMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, logMessagesTotal)
+}
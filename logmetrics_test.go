@@ -0,0 +1,26 @@
+package foundation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMetricsHook(t *testing.T) {
+	t.Run("CountsMessagesByLevel", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&buffer))
+		errorsBefore := testutil.ToFloat64(logMessagesTotal.WithLabelValues("error"))
+		warningsBefore := testutil.ToFloat64(logMessagesTotal.WithLabelValues("warn"))
+
+		// act
+		logger.Error().Msg("Failed")
+		logger.Error().Msg("Failed again")
+		logger.Warn().Msg("Retrying")
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(logMessagesTotal.WithLabelValues("error"))-errorsBefore)
+		assert.Equal(t, float64(1), testutil.ToFloat64(logMessagesTotal.WithLabelValues("warn"))-warningsBefore)
+	})
+}
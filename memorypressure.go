@@ -0,0 +1,280 @@
+package foundation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryObserverConfig configures the thresholds at which the MemoryObserver warns about memory pressure
+type MemoryObserverConfig struct {
+	Interval          time.Duration
+	WarningThreshold  float64
+	CriticalThreshold float64
+	GracefulShutdown  chan os.Signal
+}
+
+// MemoryObserverOption allows to override memory observer config
+type MemoryObserverOption func(*MemoryObserverConfig)
+
+// WithMemoryCheckInterval sets the interval at which the cgroup memory usage is checked
+// default is 10s
+func WithMemoryCheckInterval(interval time.Duration) MemoryObserverOption {
+	return func(c *MemoryObserverConfig) {
+		c.Interval = interval
+	}
+}
+
+// WithMemoryThresholds sets the ratios of the memory limit at which a warning and an error are logged
+// default is 0.8 and 0.95
+func WithMemoryThresholds(warning, critical float64) MemoryObserverOption {
+	return func(c *MemoryObserverConfig) {
+		c.WarningThreshold = warning
+		c.CriticalThreshold = critical
+	}
+}
+
+// WithShutdownOnMemoryPressure sends SIGTERM to the channel returned by InitGracefulShutdownHandling once memory usage
+// crosses the critical threshold, to shut down gracefully before the OOM killer strikes
+func WithShutdownOnMemoryPressure(gracefulShutdown chan os.Signal) MemoryObserverOption {
+	return func(c *MemoryObserverConfig) {
+		c.GracefulShutdown = gracefulShutdown
+	}
+}
+
+// MemoryUsage is the memory usage of the container as reported by the cgroup; the working set is the usage without
+// inactive page cache, which the kernel reclaims before the OOM killer strikes
+type MemoryUsage struct {
+	UsageBytes      int64
+	WorkingSetBytes int64
+	LimitBytes      int64
+	OOMKills        int64
+	PressureAvg10   float64
+	HasPressure     bool
+}
+
+// Ratio returns the working set as ratio of the limit, or 0 if there's no limit
+func (mu MemoryUsage) Ratio() float64 {
+	if mu.LimitBytes <= 0 {
+		return 0
+	}
+	return float64(mu.WorkingSetBytes) / float64(mu.LimitBytes)
+}
+
+// MemoryObserver watches the cgroup memory usage, pressure and OOM kill events, logging and exposing them as metrics
+type MemoryObserver struct {
+	config       MemoryObserverConfig
+	mutex        sync.Mutex
+	level        int
+	oomKills     int64
+	shutdownSent bool
+
+	usageRatio    prometheus.Gauge
+	oomKillsTotal prometheus.Gauge
+	pressureAvg10 prometheus.Gauge
+}
+
+const (
+	memoryLevelNormal = iota
+	memoryLevelWarning
+	memoryLevelCritical
+)
+
+// NewMemoryObserver returns a MemoryObserver; call Start to begin watching
+func NewMemoryObserver(opts ...MemoryObserverOption) *MemoryObserver {
+	// default
+	config := MemoryObserverConfig{
+		Interval:          10 * time.Second,
+		WarningThreshold:  0.8,
+		CriticalThreshold: 0.95,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// usage and limit are read on scrape
+	registerCgroupMetrics(prometheus.DefaultRegisterer)
+
+	return &MemoryObserver{
+		config: config,
+		usageRatio: registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cgroup_memory_usage_ratio",
+			Help: "Memory working set of the container, its usage without inactive page cache, as ratio of its limit.",
+		})).(prometheus.Gauge),
+		oomKillsTotal: registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cgroup_memory_oom_kills",
+			Help: "Number of processes in the container killed by the OOM killer.",
+		})).(prometheus.Gauge),
+		pressureAvg10: registerCollector(prometheus.DefaultRegisterer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cgroup_memory_pressure_some_avg10",
+			Help: "Percentage of time in the last 10 seconds some tasks were stalled on memory.",
+		})).(prometheus.Gauge),
+	}
+}
+
+// Start checks the memory usage at the configured interval until the context is cancelled
+func (o *MemoryObserver) Start(ctx context.Context) {
+	go func() {
+		ticker := currentClock().NewTicker(o.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			o.observe()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
+
+func (o *MemoryObserver) observe() {
+	usage, ok := GetMemoryUsage()
+	if !ok {
+		return
+	}
+
+	o.usageRatio.Set(usage.Ratio())
+	o.oomKillsTotal.Set(float64(usage.OOMKills))
+	if usage.HasPressure {
+		o.pressureAvg10.Set(usage.PressureAvg10)
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if usage.OOMKills > o.oomKills {
+		log.Error().
+			Int64("oomKills", usage.OOMKills).
+			Msgf("OOM killer killed %v processes in this container", usage.OOMKills-o.oomKills)
+	}
+	o.oomKills = usage.OOMKills
+
+	// only log when crossing a threshold to avoid flooding the logs
+	level := memoryLevelNormal
+	ratio := usage.Ratio()
+	switch {
+	case usage.LimitBytes <= 0:
+	case ratio >= o.config.CriticalThreshold:
+		level = memoryLevelCritical
+	case ratio >= o.config.WarningThreshold:
+		level = memoryLevelWarning
+	}
+
+	if level != o.level {
+		event := log.Info()
+		switch level {
+		case memoryLevelCritical:
+			event = log.Error()
+		case memoryLevelWarning:
+			event = log.Warn()
+		}
+		event.
+			Int64("usageBytes", usage.UsageBytes).
+			Int64("workingSetBytes", usage.WorkingSetBytes).
+			Int64("limitBytes", usage.LimitBytes).
+			Float64("usageRatio", ratio).
+			Msgf("Memory usage at %.0f%% of limit", ratio*100)
+	}
+	o.level = level
+
+	if level == memoryLevelCritical && o.config.GracefulShutdown != nil && !o.shutdownSent {
+		o.shutdownSent = true
+		log.Warn().Msg("Shutting down gracefully due to memory pressure before the OOM killer strikes")
+		select {
+		case o.config.GracefulShutdown <- syscall.SIGTERM:
+		default:
+		}
+	}
+}
+
+// GetMemoryUsage reads the memory usage, working set, limit, OOM kill count and pressure from cgroup v2 or v1; it returns false if
+// the usage can't be read
+func GetMemoryUsage() (usage MemoryUsage, ok bool) {
+	// cgroup v2
+	content, err := readCgroupFile("memory.current")
+	if err != nil {
+		// cgroup v1
+		content, err = readCgroupFile(filepath.Join("memory", "memory.usage_in_bytes"))
+		if err != nil {
+			return usage, false
+		}
+	}
+
+	usage.UsageBytes, err = strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return usage, false
+	}
+
+	// cgroup v2 reports the page cache that can be reclaimed in memory.stat as inactive_file, v1 as total_inactive_file
+	usage.WorkingSetBytes = usage.UsageBytes
+	if stat, err := readCgroupFile("memory.stat"); err == nil {
+		usage.WorkingSetBytes -= readCgroupKeyValue(stat, "inactive_file")
+	} else if stat, err := readCgroupFile(filepath.Join("memory", "memory.stat")); err == nil {
+		usage.WorkingSetBytes -= readCgroupKeyValue(stat, "total_inactive_file")
+	}
+	if usage.WorkingSetBytes < 0 {
+		usage.WorkingSetBytes = 0
+	}
+
+	if limit, ok := readCgroupMemoryLimit(); ok {
+		usage.LimitBytes = limit
+	}
+
+	// cgroup v2 reports in memory.events, v1 in memory.oom_control
+	if events, err := readCgroupFile("memory.events"); err == nil {
+		usage.OOMKills = readCgroupKeyValue(events, "oom_kill")
+	} else if oomControl, err := readCgroupFile(filepath.Join("memory", "memory.oom_control")); err == nil {
+		usage.OOMKills = readCgroupKeyValue(oomControl, "oom_kill")
+	}
+
+	// pressure stall information is only available with cgroup v2
+	if pressure, err := readCgroupFile("memory.pressure"); err == nil {
+		usage.PressureAvg10, usage.HasPressure = readPressureSomeAvg10(pressure)
+	}
+
+	return usage, true
+}
+
+// readCgroupKeyValue returns the value for the key in a flat keyed cgroup file like memory.events
+func readCgroupKeyValue(content, key string) int64 {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			value, _ := strconv.ParseInt(fields[1], 10, 64)
+			return value
+		}
+	}
+
+	return 0
+}
+
+// readPressureSomeAvg10 returns the avg10 value of the some line in a pressure stall information file
+func readPressureSomeAvg10(content string) (float64, bool) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "avg10=") {
+				avg10, err := strconv.ParseFloat(strings.TrimPrefix(field, "avg10="), 64)
+				return avg10, err == nil
+			}
+		}
+	}
+
+	return 0, false
+}
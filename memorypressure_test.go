@@ -0,0 +1,95 @@
+package foundation
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMemoryUsage(t *testing.T) {
+	t.Run("ReturnsUsageFromCgroupV2", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"memory.current":  "400000000\n",
+			"memory.max":      "1000000000\n",
+			"memory.stat":     "anon 300000000\nfile 100000000\nactive_file 40000000\ninactive_file 60000000\n",
+			"memory.events":   "low 0\nhigh 0\nmax 12\noom 2\noom_kill 1\n",
+			"memory.pressure": "some avg10=1.50 avg60=0.40 avg300=0.10 total=12345\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		})
+
+		// act
+		usage, ok := GetMemoryUsage()
+
+		assert.True(t, ok)
+		assert.Equal(t, int64(400000000), usage.UsageBytes)
+		assert.Equal(t, int64(340000000), usage.WorkingSetBytes)
+		assert.Equal(t, int64(1000000000), usage.LimitBytes)
+		assert.Equal(t, int64(1), usage.OOMKills)
+		assert.True(t, usage.HasPressure)
+		assert.Equal(t, 1.5, usage.PressureAvg10)
+		assert.Equal(t, 0.34, usage.Ratio())
+	})
+
+	t.Run("ReturnsUsageFromCgroupV1", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"memory/memory.usage_in_bytes": "400000000\n",
+			"memory/memory.limit_in_bytes": "1000000000\n",
+			"memory/memory.oom_control":    "oom_kill_disable 0\nunder_oom 0\noom_kill 3\n",
+			"memory/memory.stat":           "cache 100000000\ninactive_file 1000\ntotal_inactive_file 60000000\n",
+		})
+
+		// act
+		usage, ok := GetMemoryUsage()
+
+		assert.True(t, ok)
+		assert.Equal(t, int64(400000000), usage.UsageBytes)
+		assert.Equal(t, int64(340000000), usage.WorkingSetBytes)
+		assert.Equal(t, int64(3), usage.OOMKills)
+		assert.False(t, usage.HasPressure)
+	})
+
+	t.Run("ReturnsFalseWithoutCgroup", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{})
+
+		// act
+		_, ok := GetMemoryUsage()
+
+		assert.False(t, ok)
+	})
+}
+
+func TestMemoryObserver(t *testing.T) {
+	t.Run("LogsWarningWhenCrossingThreshold", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"memory.current": "850000000\n",
+			"memory.max":     "1000000000\n",
+		})
+		logs := captureLogs(t)
+		observer := NewMemoryObserver()
+
+		// act
+		observer.observe()
+
+		assert.Contains(t, logs.String(), `"level":"warn"`)
+		assert.Contains(t, logs.String(), "Memory usage at 85% of limit")
+	})
+
+	t.Run("SendsSignalToGracefulShutdownWhenCritical", func(t *testing.T) {
+		setCgroupFiles(t, map[string]string{
+			"memory.current": "990000000\n",
+			"memory.max":     "1000000000\n",
+		})
+		captureLogs(t)
+		gracefulShutdown := make(chan os.Signal, 1)
+		observer := NewMemoryObserver(WithShutdownOnMemoryPressure(gracefulShutdown))
+
+		// act
+		observer.observe()
+
+		select {
+		case <-gracefulShutdown:
+		default:
+			assert.Fail(t, "no signal sent")
+		}
+	})
+}
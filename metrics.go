@@ -1,31 +1,351 @@
 package foundation
 
 import (
-	"fmt"
+	"context"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 )
 
+// MetricsConfig is used to configure the prometheus endpoint
+type MetricsConfig struct {
+	Path              string
+	TLSCertFile       string
+	TLSKeyFile        string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	BearerTokens      []string
+	UnixSocket        string
+	BindAddress       string
+	Registerer        prometheus.Registerer
+	Gatherer          prometheus.Gatherer
+	ErrorHandler      func(err error)
+	ServerGroup       *ServerGroup
+}
+
+// MetricsOption allows to override metrics config
+type MetricsOption func(*MetricsConfig)
+
+// WithMetricsPath serves the metrics on path instead of /metrics, for when that path is already claimed by a sidecar
+// default is envvar ESTAFETTE_METRICS_PATH or /metrics if not set
+func WithMetricsPath(path string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.Path = path
+	}
+}
+
+// WithMetricsBindAddress binds the metrics listener to a specific address, like 127.0.0.1 or ::1, instead of all
+// interfaces
+// default is envvar ESTAFETTE_METRICS_BIND_ADDRESS or all interfaces if not set
+func WithMetricsBindAddress(address string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.BindAddress = address
+	}
+}
+
+// WithMetricsUnixSocket serves the metrics on the unix socket at path instead of a tcp port, for sidecars scraping over
+// a shared volume or hosts where opening extra ports is prohibited
+// default is envvar ESTAFETTE_METRICS_UNIX_SOCKET
+func WithMetricsUnixSocket(path string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.UnixSocket = path
+	}
+}
+
+// WithMetricsTLS serves the metrics endpoint over https with the pem encoded certificate and key, which are reloaded
+// when they change, for example when a mounted secret gets rotated
+func WithMetricsTLS(certFile, keyFile string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.TLSCertFile = certFile
+		c.TLSKeyFile = keyFile
+	}
+}
+
+// WithMetricsBasicAuth only allows scraping the metrics endpoint with basic auth credentials username and password
+// default is envvars ESTAFETTE_METRICS_BASIC_AUTH_USERNAME and ESTAFETTE_METRICS_BASIC_AUTH_PASSWORD
+func WithMetricsBasicAuth(username, password string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.BasicAuthUsername = username
+		c.BasicAuthPassword = password
+	}
+}
+
+// WithMetricsBearerToken only allows scraping the metrics endpoint with an 'Authorization: Bearer <token>' header
+// matching one of the tokens; combined with WithMetricsBasicAuth either is accepted
+// default is envvar ESTAFETTE_METRICS_BEARER_TOKEN, with multiple tokens separated by commas
+func WithMetricsBearerToken(tokens ...string) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.BearerTokens = tokens
+	}
+}
+
+// WithMetricsRegistry registers the foundation metrics with registerer and serves the metrics gathered by gatherer,
+// usually both a registry created with prometheus.NewRegistry
+// default is the prometheus default registry
+func WithMetricsRegistry(registerer prometheus.Registerer, gatherer prometheus.Gatherer) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.Registerer = registerer
+		c.Gatherer = gatherer
+	}
+}
+
+// WithMetricsErrorHandler passes errors loading the certificate or binding or serving the metrics listener to handler,
+// for example to pick another port, instead of exiting the application
+func WithMetricsErrorHandler(handler func(err error)) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithMetricsServerGroup runs the metrics server as part of group, which returns its errors from Wait instead of
+// exiting the application
+func WithMetricsServerGroup(group *ServerGroup) MetricsOption {
+	return func(c *MetricsConfig) {
+		c.ServerGroup = group
+	}
+}
+
+func newMetricsConfig(opts ...MetricsOption) MetricsConfig {
+	// default
+	config := MetricsConfig{
+		Path:              "/metrics",
+		BasicAuthUsername: os.Getenv("ESTAFETTE_METRICS_BASIC_AUTH_USERNAME"),
+		BasicAuthPassword: os.Getenv("ESTAFETTE_METRICS_BASIC_AUTH_PASSWORD"),
+		BearerTokens:      splitCommaSeparated(os.Getenv("ESTAFETTE_METRICS_BEARER_TOKEN")),
+		UnixSocket:        os.Getenv("ESTAFETTE_METRICS_UNIX_SOCKET"),
+		BindAddress:       os.Getenv("ESTAFETTE_METRICS_BIND_ADDRESS"),
+		Registerer:        prometheus.DefaultRegisterer,
+		Gatherer:          prometheus.DefaultGatherer,
+	}
+
+	if path := os.Getenv("ESTAFETTE_METRICS_PATH"); path != "" {
+		config.Path = path
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
 // InitMetrics initializes the prometheus endpoint /metrics on port 9101
-func InitMetrics() {
-	InitMetricsWithPort(9101)
+func InitMetrics(opts ...MetricsOption) *http.Server {
+	return InitMetricsWithPort(9101, opts...)
 }
 
-// InitMetricsWithPort initializes the prometheus endpoint /metrics on specified port
-func InitMetricsWithPort(port int) {
-	// start prometheus
-	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
-			Str("port", portString).
-			Msg("Serving Prometheus metrics...")
+// InitMetricsFromEnv initializes the prometheus endpoint on the port set in envvar ESTAFETTE_METRICS_PORT, or 9101 if not
+// set, unless ESTAFETTE_METRICS_ENABLED is false, in which case it returns nil; like InitMetrics it reads the path from
+// ESTAFETTE_METRICS_PATH
+func InitMetricsFromEnv(opts ...MetricsOption) *http.Server {
+	if enabled, err := strconv.ParseBool(os.Getenv("ESTAFETTE_METRICS_ENABLED")); err == nil && !enabled {
+		log.Debug().Msg("Prometheus metrics are disabled by ESTAFETTE_METRICS_ENABLED")
+		return nil
+	}
 
-		http.Handle("/metrics", promhttp.Handler())
+	port := 9101
+	if value := os.Getenv("ESTAFETTE_METRICS_PORT"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Warn().Err(err).Msgf("ESTAFETTE_METRICS_PORT %v is not a valid port, using port %v", value, port)
+		} else {
+			port = parsed
+		}
+	}
+
+	return InitMetricsWithPort(port, opts...)
+}
 
-		if err := http.ListenAndServe(portString, nil); err != nil {
+// InitMetricsWithPort initializes the prometheus endpoint /metrics on specified port; shut down the returned server to
+// release the port, for example with HandleGracefulShutdown(gracefulShutdown, waitGroup, ShutdownHTTPServer(server));
+// the port is bound before it returns, so WithMetricsErrorHandler receives a port conflict right away
+func InitMetricsWithPort(port int, opts ...MetricsOption) *http.Server {
+	config := newMetricsConfig(opts...)
+
+	// errors are reflected in the readiness endpoint, besides being passed to the error handler or group if set, or
+	// being fatal otherwise
+	setMetricsServerError(nil)
+	errorHandler := config.ErrorHandler
+	config.ErrorHandler = func(err error) {
+		setMetricsServerError(err)
+		if errorHandler != nil {
+			errorHandler(err)
+		} else if config.ServerGroup == nil {
 			log.Fatal().Err(err).Msg("Starting Prometheus listener failed")
 		}
-	}()
+	}
+
+	serverMux := http.NewServeMux()
+	InitMetricsWithMux(serverMux, opts...)
+
+	server := &http.Server{
+		Addr:              bindAddress(config.BindAddress, port),
+		Handler:           serverMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if config.TLSCertFile != "" {
+		tlsConfig, err := newServerTLSConfig(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			if config.ServerGroup != nil {
+				config.ServerGroup.fail("Prometheus", err)
+			}
+			config.ErrorHandler(err)
+			return server
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	// start prometheus
+	log.Debug().
+		Str("port", server.Addr).
+		Str("unixSocket", config.UnixSocket).
+		Str("path", config.Path).
+		Bool("tls", server.TLSConfig != nil).
+		Msg("Serving Prometheus metrics...")
+
+	if err := listenAndServeInBackground(server, config.UnixSocket, "Prometheus", config.ErrorHandler, config.ServerGroup); err == nil {
+		PublishLifecycleEvent(EventMetricsServing, map[string]string{"port": server.Addr})
+	}
+
+	return server
+}
+
+// InitMetricsWithRegistry initializes the prometheus endpoint /metrics on specified port for a registry other than the
+// default one, for example to avoid duplicate registrations in tests or to keep metrics apart
+// registry := prometheus.NewRegistry()
+// server := InitMetricsWithRegistry(9101, registry, registry)
+func InitMetricsWithRegistry(port int, registerer prometheus.Registerer, gatherer prometheus.Gatherer, opts ...MetricsOption) *http.Server {
+	return InitMetricsWithPort(port, append(opts, WithMetricsRegistry(registerer, gatherer))...)
+}
+
+var (
+	metricsServerErrorMutex sync.RWMutex
+	metricsServerError      error
+)
+
+func setMetricsServerError(err error) {
+	metricsServerErrorMutex.Lock()
+	defer metricsServerErrorMutex.Unlock()
+
+	metricsServerError = err
+}
+
+// MetricsServerError returns the error the metrics listener failed to start or serve with, or nil if it's fine; the
+// /readiness endpoint reports the application isn't ready as long as it's set, so broken scraping gets noticed
+func MetricsServerError() error {
+	metricsServerErrorMutex.RLock()
+	defer metricsServerErrorMutex.RUnlock()
+
+	return metricsServerError
+}
+
+// ShutdownHTTPServer returns a function that shuts down the server, waiting at most 5 seconds for open requests; pass
+// it to HandleGracefulShutdown as function to run on shutdown
+func ShutdownHTTPServer(server *http.Server) func() {
+	return func() {
+		// InitMetricsFromEnv returns no server if metrics are disabled
+		if server == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			log.Warn().Err(err).Str("port", server.Addr).Msg("Shutting down http server failed")
+		}
+	}
+}
+
+// InitMetricsWithMux registers the prometheus endpoint /metrics, or the path set with WithMetricsPath, on mux, for serving it from a server the application
+// already runs instead of a separate listener
+func InitMetricsWithMux(mux *http.ServeMux, opts ...MetricsOption) {
+	config := newMetricsConfig(opts...)
+
+	registerRuntimeInfoMetric(config.Registerer, NewRuntimeInfoFromEnv())
+	registerLogMetrics(config.Registerer)
+	registerCommandMetrics(config.Registerer)
+	registerRetryMetrics(config.Registerer)
+	registerCgroupMetrics(config.Registerer)
+	registerHealthMetrics(config.Registerer)
+	registerWarmupMetrics(config.Registerer)
+	registerRecoveryMetrics(config.Registerer)
+	registerAuthMetrics(config.Registerer)
+	registerRateLimitMetrics(config.Registerer)
+	registerGRPCMetrics(config.Registerer)
+	registerMirroredMetrics(config.Registerer)
+
+	handler := promhttp.InstrumentMetricHandler(config.Registerer, promhttp.HandlerFor(config.Gatherer, promhttp.HandlerOpts{}))
+	mux.Handle(config.Path, config.authenticate(handler))
+}
+
+// authenticate wraps the handler with basic and/or bearer token authentication if configured
+func (c MetricsConfig) authenticate(handler http.Handler) http.Handler {
+	var basicAuth, bearerAuth Middleware
+	if c.BasicAuthUsername != "" {
+		basicAuth = BasicAuth("metrics", map[string]string{c.BasicAuthUsername: c.BasicAuthPassword})
+	}
+	if len(c.BearerTokens) > 0 {
+		bearerAuth = BearerTokenAuth("metrics", c.BearerTokens...)
+	}
+
+	switch {
+	case basicAuth != nil && bearerAuth != nil:
+		basicHandler, bearerHandler := basicAuth(handler), bearerAuth(handler)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, _, ok := r.BasicAuth(); ok {
+				basicHandler.ServeHTTP(w, r)
+				return
+			}
+			bearerHandler.ServeHTTP(w, r)
+		})
+	case basicAuth != nil:
+		return basicAuth(handler)
+	case bearerAuth != nil:
+		return bearerAuth(handler)
+	}
+
+	return handler
+}
+
+// registerRuntimeInfoMetric exposes the kubernetes runtime information as labels on a runtime_info gauge, if available
+func registerRuntimeInfoMetric(registerer prometheus.Registerer, runtimeInfo RuntimeInfo) {
+	if !runtimeInfo.IsAvailable() {
+		return
+	}
+
+	runtimeInfoGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "runtime_info",
+		Help: "Kubernetes runtime information of the application, with value 1.",
+		ConstLabels: prometheus.Labels{
+			"pod":             runtimeInfo.PodName,
+			"namespace":       runtimeInfo.Namespace,
+			"node":            runtimeInfo.NodeName,
+			"container":       runtimeInfo.ContainerName,
+			"service_account": runtimeInfo.ServiceAccount,
+		},
+	})
+	runtimeInfoGauge.Set(1)
+
+	registerCollector(registerer, runtimeInfoGauge)
+}
+
+// registerCollector registers the collector and returns it, or the already registered collector if an equal one has been registered before
+func registerCollector(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegisteredError, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return alreadyRegisteredError.ExistingCollector
+		}
+		log.Warn().Err(err).Msg("Registering metric failed")
+	}
+
+	return collector
 }
@@ -0,0 +1,330 @@
+package foundation
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitMetricsWithMux(t *testing.T) {
+	t.Run("ServesMetricsOnEachMux", func(t *testing.T) {
+		firstMux := http.NewServeMux()
+		secondMux := http.NewServeMux()
+
+		// act
+		InitMetricsWithMux(firstMux)
+		InitMetricsWithMux(secondMux)
+
+		for _, mux := range []*http.ServeMux{firstMux, secondMux} {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "go_goroutines")
+		}
+	})
+
+	t.Run("RegistersFoundationMetricsWithRegistry", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		before := NewMetricCounter(prometheus.CounterOpts{Name: "registry_test_before_total", Help: "Before."})
+		before.Inc()
+
+		// act
+		InitMetricsWithMux(http.NewServeMux(), WithMetricsRegistry(registry, registry))
+
+		after := NewMetricCounter(prometheus.CounterOpts{Name: "registry_test_after_total", Help: "After."})
+		after.Inc()
+		metricFamilies, err := registry.Gather()
+		assert.Nil(t, err)
+		names := []string{}
+		for _, metricFamily := range metricFamilies {
+			names = append(names, metricFamily.GetName())
+		}
+		assert.Contains(t, names, "http_panics_total")
+		assert.Contains(t, names, "warmup_completed")
+		assert.Contains(t, names, "registry_test_before_total")
+		assert.Contains(t, names, "registry_test_after_total")
+	})
+
+	t.Run("RequiresBasicAuthOrBearerTokenIfConfigured", func(t *testing.T) {
+		mux := http.NewServeMux()
+		InitMetricsWithMux(mux, WithMetricsBasicAuth("prometheus", "scrape"), WithMetricsBearerToken("abc"))
+		scrape := func(authorize func(r *http.Request)) int {
+			request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			authorize(request)
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, request)
+			return recorder.Code
+		}
+
+		// act
+		withoutCredentials := scrape(func(r *http.Request) {})
+		withBasicAuth := scrape(func(r *http.Request) { r.SetBasicAuth("prometheus", "scrape") })
+		withWrongPassword := scrape(func(r *http.Request) { r.SetBasicAuth("prometheus", "guess") })
+		withBearerToken := scrape(func(r *http.Request) { r.Header.Set("Authorization", "Bearer abc") })
+
+		assert.Equal(t, http.StatusUnauthorized, withoutCredentials)
+		assert.Equal(t, http.StatusOK, withBasicAuth)
+		assert.Equal(t, http.StatusUnauthorized, withWrongPassword)
+		assert.Equal(t, http.StatusOK, withBearerToken)
+	})
+
+	t.Run("ServesMetricsOnConfiguredPath", func(t *testing.T) {
+		mux := http.NewServeMux()
+
+		// act
+		InitMetricsWithMux(mux, WithMetricsPath("/internal/metrics"))
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/internal/metrics", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("ReadsPathFromEnv", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_METRICS_PATH", "/prometheus")
+		mux := http.NewServeMux()
+
+		// act
+		InitMetricsWithMux(mux)
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/prometheus", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("ReadsBearerTokensFromEnv", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_METRICS_BEARER_TOKEN", "abc,def")
+
+		// act
+		config := newMetricsConfig()
+
+		assert.Equal(t, []string{"abc", "def"}, config.BearerTokens)
+	})
+}
+
+func TestInitMetricsWithRegistry(t *testing.T) {
+	t.Run("ServesMetricsOfRegistry", func(t *testing.T) {
+		port := freeTestPort(t)
+		registry := prometheus.NewRegistry()
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "registry_test_total", Help: "Test counter."})
+		registry.MustRegister(counter)
+		counter.Inc()
+
+		// act
+		server := InitMetricsWithRegistry(port, registry, registry)
+		defer ShutdownHTTPServer(server)()
+
+		var response *http.Response
+		assert.Eventually(t, func() bool {
+			var err error
+			response, err = http.Get(fmt.Sprintf("http://localhost:%v/metrics", port))
+			return err == nil
+		}, 2*time.Second, 10*time.Millisecond)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		assert.Contains(t, string(body), "registry_test_total 1")
+		assert.NotContains(t, string(body), "go_goroutines")
+	})
+}
+
+func TestInitMetricsFromEnv(t *testing.T) {
+	t.Run("ServesOnPortAndPathFromEnv", func(t *testing.T) {
+		port := freeTestPort(t)
+		t.Setenv("ESTAFETTE_METRICS_PORT", fmt.Sprint(port))
+		t.Setenv("ESTAFETTE_METRICS_PATH", "/internal/metrics")
+
+		// act
+		server := InitMetricsFromEnv()
+		defer ShutdownHTTPServer(server)()
+
+		response, err := http.Get(fmt.Sprintf("http://localhost:%v/internal/metrics", port))
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+
+	t.Run("ReturnsNilIfDisabled", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_METRICS_ENABLED", "false")
+
+		// act
+		server := InitMetricsFromEnv()
+
+		assert.Nil(t, server)
+	})
+}
+
+func TestInitMetricsWithPort(t *testing.T) {
+	t.Run("ReleasesPortOnShutdown", func(t *testing.T) {
+		port := freeTestPort(t)
+		server := InitMetricsWithPort(port)
+		assert.Eventually(t, func() bool {
+			response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/metrics", port))
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return response.StatusCode == http.StatusOK
+		}, time.Second, 10*time.Millisecond)
+
+		// act
+		ShutdownHTTPServer(server)()
+
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if assert.Nil(t, err) {
+			listener.Close()
+		}
+	})
+
+	t.Run("ServesHTTPSWithCertificateFiles", func(t *testing.T) {
+		caCert, caKey, caPEM := generateTestCertificate(t, "ca", nil, nil)
+		_, _, serverPEM, serverKeyPEM := generateTestLeafCertificate(t, "localhost", caCert, caKey)
+		dir := t.TempDir()
+		writeTestFile(t, filepath.Join(dir, "cert.pem"), serverPEM)
+		writeTestFile(t, filepath.Join(dir, "key.pem"), serverKeyPEM)
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(caPEM)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		port := freeTestPort(t)
+
+		// act
+		server := InitMetricsWithPort(port, WithMetricsTLS(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")))
+		defer ShutdownHTTPServer(server)()
+
+		assert.Eventually(t, func() bool {
+			response, err := client.Get(fmt.Sprintf("https://localhost:%v/metrics", port))
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return response.StatusCode == http.StatusOK
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestWithMetricsErrorHandler(t *testing.T) {
+	t.Run("ReceivesBindErrorInsteadOfExiting", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		var handledErr error
+		defer setMetricsServerError(nil)
+
+		// act
+		InitMetricsWithPort(listener.Addr().(*net.TCPAddr).Port, WithMetricsErrorHandler(func(err error) { handledErr = err }))
+
+		assert.NotNil(t, handledErr)
+	})
+
+	t.Run("ReportsNotReadyAfterBindError", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		defer setMetricsServerError(nil)
+
+		// act
+		InitMetricsWithPort(listener.Addr().(*net.TCPAddr).Port, WithMetricsErrorHandler(func(err error) {}))
+
+		assert.NotNil(t, MetricsServerError())
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "My metrics server failed")
+	})
+
+	t.Run("DoesNotPublishMetricsServingAfterBindError", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		defer setMetricsServerError(nil)
+		var events []LifecycleEvent
+		unsubscribe := SubscribeLifecycleEvents(func(event LifecycleEvent) {
+			events = append(events, event)
+		})
+		defer unsubscribe()
+
+		// act
+		InitMetricsWithPort(listener.Addr().(*net.TCPAddr).Port, WithMetricsErrorHandler(func(err error) {}))
+
+		assert.Equal(t, 0, len(events))
+	})
+
+	t.Run("ReportsNotReadyAfterBindErrorWithServerGroup", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		defer setMetricsServerError(nil)
+		group, _ := NewServerGroup(context.Background())
+
+		// act
+		InitMetricsWithPort(listener.Addr().(*net.TCPAddr).Port, WithMetricsServerGroup(group))
+
+		assert.NotNil(t, group.Wait())
+		assert.NotNil(t, MetricsServerError())
+	})
+}
+
+func TestWithMetricsUnixSocket(t *testing.T) {
+	t.Run("ServesMetricsOnUnixSocket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "metrics.sock")
+
+		// act
+		server := InitMetricsWithPort(0, WithMetricsUnixSocket(socket))
+		defer ShutdownHTTPServer(server)()
+
+		response, err := unixSocketTestClient(socket).Get("http://localhost/metrics")
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+}
+
+// unixSocketTestClient returns an http client sending all requests to the unix socket
+func unixSocketTestClient(socket string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+}
+
+// freeTestPort returns a port that was free a moment ago
+func freeTestPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestWithMetricsBindAddress(t *testing.T) {
+	t.Run("ReadsBindAddressFromEnv", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_METRICS_BIND_ADDRESS", "127.0.0.1")
+		port := freeTestPort(t)
+
+		// act
+		server := InitMetricsWithPort(port)
+		defer ShutdownHTTPServer(server)()
+
+		assert.Equal(t, fmt.Sprintf("127.0.0.1:%v", port), server.Addr)
+	})
+}
@@ -0,0 +1,140 @@
+package foundation
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps an http.Handler to execute logic before and/or after the wrapped handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps the handler with the middlewares, with the first middleware being the outermost one
+// Chain(handler, RequestLogging(), Recovery()) handles a request as RequestLogging -> Recovery -> handler
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// responseWriter records the status code and number of bytes written by the wrapped handler
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the wrapped response writer does so
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to access the wrapped response writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// clientIP returns the ip address of the client, using the first address in the X-Forwarded-For header if set; the
+// header is set by the client, so only use it for logging
+func clientIP(r *http.Request) string {
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		return strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	}
+
+	return remoteIP(r)
+}
+
+// remoteIP returns the ip address of the peer the request was received from
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// forwardedClientIP returns the ip address of the client for requests received from one of the trusted proxies, as the
+// last address in the X-Forwarded-For header that isn't a trusted proxy itself, and the peer address otherwise
+func forwardedClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteIP(r)
+	if !ipInNetworks(ip, trustedProxies) {
+		return ip
+	}
+
+	// every proxy appends the address it received the request from, so the addresses before the last untrusted one
+	// can be set by the client
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		forwardedIP := strings.TrimSpace(forwardedFor[i])
+		if forwardedIP == "" {
+			continue
+		}
+		ip = forwardedIP
+		if !ipInNetworks(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// parseNetworks parses ip addresses and cidr ranges, skipping invalid ones
+func parseNetworks(addresses []string) (networks []*net.IPNet, invalid []string) {
+	for _, address := range addresses {
+		if !strings.Contains(address, "/") {
+			if ip := net.ParseIP(address); ip != nil {
+				bits := 8 * len(ip)
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		if _, network, err := net.ParseCIDR(address); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		invalid = append(invalid, address)
+	}
+
+	return networks, invalid
+}
+
+func ipInNetworks(address string, networks []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
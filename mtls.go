@@ -0,0 +1,286 @@
+package foundation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// MTLSConfig is used to configure the tls config created by NewClientTLSConfig
+type MTLSConfig struct {
+	CertFile    string
+	KeyFile     string
+	CAFile      string
+	CertPEM     []byte
+	KeyPEM      []byte
+	CAPEM       []byte
+	ServerName  string
+	AllowedSANs []string
+	HotReload   bool
+}
+
+// MTLSOption allows to override config
+type MTLSOption func(*MTLSConfig)
+
+// WithClientCertificateFiles sets the paths of the pem encoded client certificate and key
+// default is envvars ESTAFETTE_TLS_CERT_FILE and ESTAFETTE_TLS_KEY_FILE
+func WithClientCertificateFiles(certFile, keyFile string) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.CertFile = certFile
+		c.KeyFile = keyFile
+	}
+}
+
+// WithClientCertificatePEM sets the pem encoded client certificate and key
+// default is envvars ESTAFETTE_TLS_CERT and ESTAFETTE_TLS_KEY
+func WithClientCertificatePEM(certPEM, keyPEM []byte) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.CertPEM = certPEM
+		c.KeyPEM = keyPEM
+	}
+}
+
+// WithCAFile sets the path of the pem encoded ca bundle to verify server certificates with, instead of the system roots
+// default is envvar ESTAFETTE_TLS_CA_FILE
+func WithCAFile(caFile string) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.CAFile = caFile
+	}
+}
+
+// WithCAPEM sets the pem encoded ca bundle to verify server certificates with, instead of the system roots
+// default is envvar ESTAFETTE_TLS_CA
+func WithCAPEM(caPEM []byte) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.CAPEM = caPEM
+	}
+}
+
+// WithServerName sets the name the server certificate has to be valid for, instead of the host that is connected to;
+// it's required when connecting to an ip address, which can be set as server name to verify the ip address sans
+// default is envvar ESTAFETTE_TLS_SERVER_NAME
+func WithServerName(serverName string) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.ServerName = serverName
+	}
+}
+
+// WithAllowedSANs only accepts server certificates with one of these dns or uri (for example spiffe://...) subject alternative
+// names, instead of verifying the host name
+func WithAllowedSANs(sans ...string) MTLSOption {
+	return func(c *MTLSConfig) {
+		c.AllowedSANs = sans
+	}
+}
+
+// WithHotReload reloads the certificate, key and ca bundle files when they change, for example when a mounted secret gets rotated
+func WithHotReload() MTLSOption {
+	return func(c *MTLSConfig) {
+		c.HotReload = true
+	}
+}
+
+// NewClientTLSConfig returns a tls config for outbound mutual tls connections; use it in an http.Transport as TLSClientConfig
+// or pass it to DialGRPC with WithMTLS
+func NewClientTLSConfig(opts ...MTLSOption) (*tls.Config, error) {
+
+	// default
+	config := &MTLSConfig{
+		CertFile:   os.Getenv("ESTAFETTE_TLS_CERT_FILE"),
+		KeyFile:    os.Getenv("ESTAFETTE_TLS_KEY_FILE"),
+		CAFile:     os.Getenv("ESTAFETTE_TLS_CA_FILE"),
+		CertPEM:    []byte(os.Getenv("ESTAFETTE_TLS_CERT")),
+		KeyPEM:     []byte(os.Getenv("ESTAFETTE_TLS_KEY")),
+		CAPEM:      []byte(os.Getenv("ESTAFETTE_TLS_CA")),
+		ServerName: os.Getenv("ESTAFETTE_TLS_SERVER_NAME"),
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	loader := &mtlsLoader{config: config}
+	if err := loader.load(); err != nil {
+		return nil, err
+	}
+
+	if config.HotReload {
+		for _, file := range []string{config.CertFile, config.KeyFile, config.CAFile} {
+			if file != "" {
+				WatchForFileChanges(file, loader.reload)
+			}
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: loader.getClientCertificate,
+		// the server certificate is verified in VerifyConnection against the current, possibly reloaded, ca bundle
+		InsecureSkipVerify: true,
+		VerifyConnection:   loader.verifyConnection,
+	}, nil
+}
+
+// newServerTLSConfig returns a tls config for serving https with the certificate and key files, reloading them when
+// they change
+func newServerTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	loader := &mtlsLoader{config: &MTLSConfig{
+		CertFile:  certFile,
+		KeyFile:   keyFile,
+		HotReload: true,
+	}}
+	if err := loader.load(); err != nil {
+		return nil, err
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		WatchForFileChanges(file, loader.reload)
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: loader.getCertificate,
+	}, nil
+}
+
+// mtlsLoader holds the current client certificate and ca pool and reloads them on change
+type mtlsLoader struct {
+	config      *MTLSConfig
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	roots       *x509.CertPool
+}
+
+func (l *mtlsLoader) load() error {
+	certPEM, keyPEM, caPEM := l.config.CertPEM, l.config.KeyPEM, l.config.CAPEM
+
+	var err error
+	if l.config.CertFile != "" {
+		if certPEM, err = ioutil.ReadFile(l.config.CertFile); err != nil {
+			return err
+		}
+	}
+	if l.config.KeyFile != "" {
+		if keyPEM, err = ioutil.ReadFile(l.config.KeyFile); err != nil {
+			return err
+		}
+	}
+	if l.config.CAFile != "" {
+		if caPEM, err = ioutil.ReadFile(l.config.CAFile); err != nil {
+			return err
+		}
+	}
+
+	var certificate *tls.Certificate
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		c, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("Loading client certificate failed: %w", err)
+		}
+		certificate = &c
+	}
+
+	var roots *x509.CertPool
+	if len(caPEM) > 0 {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return errors.New("Ca bundle doesn't contain any pem encoded certificate")
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.certificate = certificate
+	l.roots = roots
+
+	return nil
+}
+
+func (l *mtlsLoader) reload(event fsnotify.Event) {
+	if err := l.load(); err != nil {
+		log.Warn().Err(err).Str("file", event.Name).Msg("Reloading mtls certificates failed, keeping the previous ones")
+		return
+	}
+	log.Info().Str("file", event.Name).Msg("Reloaded mtls certificates")
+}
+
+func (l *mtlsLoader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if l.certificate == nil {
+		// sending no certificate lets the server decide whether that's acceptable
+		return &tls.Certificate{}, nil
+	}
+	return l.certificate, nil
+}
+
+func (l *mtlsLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if l.certificate == nil {
+		return nil, errors.New("No server certificate configured")
+	}
+	return l.certificate, nil
+}
+
+func (l *mtlsLoader) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("Server didn't present a certificate")
+	}
+
+	l.mutex.RLock()
+	roots := l.roots
+	l.mutex.RUnlock()
+
+	options := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, intermediate := range cs.PeerCertificates[1:] {
+		options.Intermediates.AddCert(intermediate)
+	}
+
+	if len(l.config.AllowedSANs) == 0 {
+		options.DNSName = cs.ServerName
+		if l.config.ServerName != "" {
+			options.DNSName = l.config.ServerName
+		}
+		// the server name is empty when connecting to an ip address, verifying without it accepts any certificate
+		// signed by the ca
+		if options.DNSName == "" {
+			return errors.New("No server name to verify the server certificate for, set one with WithServerName or use WithAllowedSANs")
+		}
+	}
+
+	leaf := cs.PeerCertificates[0]
+	if _, err := leaf.Verify(options); err != nil {
+		return err
+	}
+
+	if len(l.config.AllowedSANs) > 0 {
+		for _, dnsName := range leaf.DNSNames {
+			if StringArrayContains(l.config.AllowedSANs, dnsName) {
+				return nil
+			}
+		}
+		for _, uri := range leaf.URIs {
+			if StringArrayContains(l.config.AllowedSANs, uri.String()) {
+				return nil
+			}
+		}
+		return errors.New("Server certificate doesn't contain any of the allowed subject alternative names")
+	}
+
+	return nil
+}
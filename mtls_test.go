@@ -0,0 +1,189 @@
+package foundation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientTLSConfig(t *testing.T) {
+
+	caCert, caKey, caPEM := generateTestCertificate(t, "ca", nil, nil)
+	_, _, serverPEM, serverKeyPEM := generateTestLeafCertificate(t, "myservice", caCert, caKey)
+	_, _, clientPEM, clientKeyPEM := generateTestLeafCertificate(t, "myclient", caCert, caKey)
+
+	serverCertificate, err := tls.X509KeyPair(serverPEM, serverKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCertificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "ca.pem"), caPEM)
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), clientPEM)
+	writeTestFile(t, filepath.Join(dir, "key.pem"), clientKeyPEM)
+
+	t.Run("ConnectsWithClientCertificateFromFiles", func(t *testing.T) {
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificateFiles(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")), WithCAFile(filepath.Join(dir, "ca.pem")), WithServerName("myservice"))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if assert.Nil(t, err) {
+				defer resp.Body.Close()
+				body, _ := ioutil.ReadAll(resp.Body)
+				assert.Equal(t, "myclient", string(body))
+			}
+		}
+	})
+
+	t.Run("ConnectsWithAllowedSAN", func(t *testing.T) {
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificatePEM(clientPEM, clientKeyPEM), WithCAPEM(caPEM), WithAllowedSANs("myservice"))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if assert.Nil(t, err) {
+				resp.Body.Close()
+			}
+		}
+	})
+
+	t.Run("FailsForServerNameNotInCertificate", func(t *testing.T) {
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificatePEM(clientPEM, clientKeyPEM), WithCAPEM(caPEM), WithServerName("otherservice"))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			_, err = client.Get(server.URL)
+			assert.NotNil(t, err)
+		}
+	})
+
+	t.Run("FailsForIPAddressWithoutServerName", func(t *testing.T) {
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificatePEM(clientPEM, clientKeyPEM), WithCAPEM(caPEM), WithServerName(""))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			_, err = client.Get(server.URL)
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "No server name to verify the server certificate for")
+			}
+		}
+	})
+
+	t.Run("ConnectsWithIPAddressAsServerName", func(t *testing.T) {
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificatePEM(clientPEM, clientKeyPEM), WithCAPEM(caPEM), WithServerName("127.0.0.1"))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if assert.Nil(t, err) {
+				resp.Body.Close()
+			}
+		}
+	})
+
+	t.Run("FailsForUntrustedServerCertificate", func(t *testing.T) {
+
+		_, _, otherCAPEM := generateTestCertificate(t, "other-ca", nil, nil)
+
+		// act
+		tlsConfig, err := NewClientTLSConfig(WithClientCertificatePEM(clientPEM, clientKeyPEM), WithCAPEM(otherCAPEM), WithServerName("myservice"))
+
+		if assert.Nil(t, err) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			_, err = client.Get(server.URL)
+			assert.NotNil(t, err)
+		}
+	})
+}
+
+// generateTestCertificate generates a self-signed ca certificate if parent is nil or a leaf certificate signed by parent otherwise
+func generateTestCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else {
+		template.DNSNames = []string{commonName}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func generateTestLeafCertificate(t *testing.T, commonName string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	certificate, key, certPEM := generateTestCertificate(t, commonName, ca, caKey)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate, key, certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeTestFile(t *testing.T, path string, content []byte) {
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+package foundation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MultiError contains multiple errors that occurred independently, so all of them can be reported instead of just the
+// first; it unwraps like the result of errors.Join
+type MultiError []error
+
+// Error returns the message of the single error or a list of all error messages
+func (e MultiError) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = fmt.Sprintf("* %v", err)
+	}
+
+	return fmt.Sprintf("%v errors occurred:\n%v", len(e), strings.Join(messages, "\n"))
+}
+
+// Unwrap returns the contained errors for errors.Is and errors.As
+func (e MultiError) Unwrap() []error {
+	return append([]error{}, e...)
+}
+
+// Is returns true if any of the contained errors matches the target, for go versions where errors.Is doesn't use
+// Unwrap() []error
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first contained error that matches the target, for go versions where errors.As doesn't use
+// Unwrap() []error
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AppendErr appends the non-nil errors to err, flattening any MultiError; it returns nil if all errors are nil
+// err = foundation.AppendErr(err, closer.Close())
+func AppendErr(err error, errs ...error) error {
+	var multiError MultiError
+	for _, e := range append([]error{err}, errs...) {
+		if e == nil {
+			continue
+		}
+		if nested, ok := e.(MultiError); ok {
+			multiError = append(multiError, nested...)
+			continue
+		}
+		multiError = append(multiError, e)
+	}
+
+	if len(multiError) == 0 {
+		return nil
+	}
+
+	return multiError
+}
+
+// ErrorCollector collects errors from multiple goroutines
+type ErrorCollector struct {
+	mutex sync.Mutex
+	err   error
+}
+
+// Add adds the error to the collector if it's not nil
+func (c *ErrorCollector) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.err = AppendErr(c.err, err)
+}
+
+// Err returns a MultiError with all collected errors, or nil if none were collected
+func (c *ErrorCollector) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
@@ -0,0 +1,89 @@
+package foundation
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendErr(t *testing.T) {
+	t.Run("ReturnsNilIfAllErrorsAreNil", func(t *testing.T) {
+		// act
+		err := AppendErr(nil, nil, nil)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("SkipsNilErrors", func(t *testing.T) {
+		// act
+		err := AppendErr(nil, errors.New("a"), nil, errors.New("b"))
+
+		assert.Equal(t, 2, len(err.(MultiError)))
+	})
+
+	t.Run("FlattensMultiErrors", func(t *testing.T) {
+		err := AppendErr(errors.New("a"), errors.New("b"))
+
+		// act
+		err = AppendErr(err, errors.New("c"))
+
+		assert.Equal(t, 3, len(err.(MultiError)))
+	})
+
+	t.Run("ReturnsMessageOfSingleError", func(t *testing.T) {
+		// act
+		err := AppendErr(nil, errors.New("a"))
+
+		assert.Equal(t, "a", err.Error())
+	})
+
+	t.Run("ReturnsListOfMessagesForMultipleErrors", func(t *testing.T) {
+		// act
+		err := AppendErr(errors.New("a"), errors.New("b"))
+
+		assert.Equal(t, "2 errors occurred:\n* a\n* b", err.Error())
+	})
+
+	t.Run("UnwrapsForErrorsIsAndAs", func(t *testing.T) {
+		// act
+		err := AppendErr(errors.New("a"), &os.PathError{Op: "open", Path: "/tmp", Err: os.ErrNotExist})
+
+		var pathError *os.PathError
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+		assert.True(t, errors.As(err, &pathError))
+		assert.False(t, errors.Is(err, os.ErrExist))
+	})
+}
+
+func TestErrorCollector(t *testing.T) {
+	t.Run("ReturnsNilWithoutErrors", func(t *testing.T) {
+		collector := ErrorCollector{}
+		collector.Add(nil)
+
+		// act
+		err := collector.Err()
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("CollectsErrorsFromMultipleGoroutines", func(t *testing.T) {
+		collector := ErrorCollector{}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				collector.Add(errors.New("failed"))
+			}()
+		}
+		wg.Wait()
+
+		// act
+		err := collector.Err()
+
+		assert.Equal(t, 10, len(err.(MultiError)))
+	})
+}
@@ -0,0 +1,360 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	otlpMaxBatchSize       = 512
+	otlpMaxBufferedRecords = 10000
+	otlpFlushInterval      = time.Second
+)
+
+var (
+	otlpExporterMutex sync.Mutex
+	otlpExporter      *otlpLogExporter
+)
+
+// InitLoggingOTLP initializes logging like InitLoggingFromEnv and additionally forwards all log events to an
+// OpenTelemetry collector using OTLP over http with json encoding. The endpoint, headers and timeout are configured
+// with the standard OTEL_EXPORTER_OTLP_* envvars, the resource with OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES.
+func InitLoggingOTLP(applicationInfo ApplicationInfo, opts ...LoggingOption) {
+	exporter := newOTLPLogExporterFromEnv(applicationInfo)
+
+	otlpExporterMutex.Lock()
+	if otlpExporter != nil {
+		otlpExporter.stop()
+	}
+	otlpExporter = exporter
+	otlpExporterMutex.Unlock()
+
+	exporter.start()
+
+	InitLoggingFromEnv(applicationInfo, append(opts, withEventSink(exporter))...)
+}
+
+// otlpLogExporter exports the json log events it batches as otlp log records
+type otlpLogExporter struct {
+	*logEventBatcher
+
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+	resource []otlpKeyValue
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist     `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+}
+
+func newOTLPLogExporterFromEnv(applicationInfo ApplicationInfo) *otlpLogExporter {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT")
+	if endpoint == "" {
+		baseEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if baseEndpoint == "" {
+			baseEndpoint = "http://localhost:4318"
+		}
+		endpoint = strings.TrimSuffix(baseEndpoint, "/") + "/v1/logs"
+	}
+
+	headers := parseOTLPKeyValues(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for key, value := range parseOTLPKeyValues(os.Getenv("OTEL_EXPORTER_OTLP_LOGS_HEADERS")) {
+		headers[key] = value
+	}
+
+	timeout := 10 * time.Second
+	for _, envvar := range []string{"OTEL_EXPORTER_OTLP_TIMEOUT", "OTEL_EXPORTER_OTLP_LOGS_TIMEOUT"} {
+		if milliseconds, err := strconv.Atoi(os.Getenv(envvar)); err == nil && milliseconds > 0 {
+			timeout = time.Duration(milliseconds) * time.Millisecond
+		}
+	}
+
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" && protocol != "http/json" {
+		fmt.Fprintf(os.Stderr, "OTEL_EXPORTER_OTLP_PROTOCOL %v is not supported, using http/json instead\n", protocol)
+	}
+
+	resourceAttributes := map[string]string{
+		"service.name":      applicationInfo.App,
+		"service.namespace": applicationInfo.AppGroup,
+		"service.version":   applicationInfo.Version,
+	}
+	runtimeInfo := applicationInfo.Runtime()
+	for key, value := range map[string]string{
+		"k8s.pod.name":       runtimeInfo.PodName,
+		"k8s.namespace.name": runtimeInfo.Namespace,
+		"k8s.node.name":      runtimeInfo.NodeName,
+		"k8s.container.name": runtimeInfo.ContainerName,
+	} {
+		if value != "" {
+			resourceAttributes[key] = value
+		}
+	}
+	for key, value := range parseOTLPKeyValues(os.Getenv("OTEL_RESOURCE_ATTRIBUTES")) {
+		resourceAttributes[key] = value
+	}
+	if serviceName := os.Getenv("OTEL_SERVICE_NAME"); serviceName != "" {
+		resourceAttributes["service.name"] = serviceName
+	}
+
+	resource := []otlpKeyValue{}
+	for key, value := range resourceAttributes {
+		if value != "" {
+			resource = append(resource, otlpKeyValue{Key: key, Value: otlpStringValue(value)})
+		}
+	}
+	sort.Slice(resource, func(i, j int) bool { return resource[i].Key < resource[j].Key })
+
+	exporter := &otlpLogExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: timeout},
+		resource: resource,
+	}
+	exporter.logEventBatcher = newLogEventBatcher(otlpMaxBatchSize, otlpMaxBufferedRecords, otlpFlushInterval, exporter.export)
+
+	return exporter
+}
+
+// parseOTLPKeyValues parses the key1=value1,key2=value2 format of the OTEL_* envvars with url encoded values
+func parseOTLPKeyValues(value string) map[string]string {
+	keyValues := map[string]string{}
+	for _, pair := range splitCommaSeparated(value) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = unescaped
+		}
+		keyValues[strings.TrimSpace(key)] = value
+	}
+
+	return keyValues
+}
+
+// export sends a batch of log events as otlp log records to the collector; errors are written to stderr, since logging
+// them would feed them back into the exporter
+func (e *otlpLogExporter) export(ctx context.Context, events []batchedLogEvent) error {
+	records := make([]otlpLogRecord, 0, len(events))
+	for _, event := range events {
+		record, err := otlpLogRecordFromEvent(event.p, event.level, event.time)
+		if err != nil {
+			// skip events that can't be forwarded
+			continue
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := e.post(ctx, records); err != nil {
+		fmt.Fprintf(os.Stderr, "Exporting %v log records to %v failed: %v\n", len(records), e.endpoint, err)
+		return err
+	}
+
+	return nil
+}
+
+func (e *otlpLogExporter) post(ctx context.Context, records []otlpLogRecord) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": e.resource},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{
+						"scope":      map[string]string{"name": "github.com/estafette/estafette-foundation"},
+						"logRecords": records,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("Collector responded with status code %v", response.StatusCode)
+	}
+
+	return nil
+}
+
+// otlpLevels maps the level names of all log formats to their level case insensitively, since zerolog.ParseLevel only
+// accepts the names of the current LevelFieldMarshalFunc
+var otlpLevels = map[string]zerolog.Level{
+	"trace":   zerolog.TraceLevel,
+	"debug":   zerolog.DebugLevel,
+	"info":    zerolog.InfoLevel,
+	"warn":    zerolog.WarnLevel,
+	"warning": zerolog.WarnLevel,
+	"error":   zerolog.ErrorLevel,
+	"fatal":   zerolog.FatalLevel,
+	"panic":   zerolog.PanicLevel,
+}
+
+// otlpLogRecordFromEvent converts a zerolog json event logged with level and observed at the given time using the
+// currently configured field names; for events written without level it's taken from the level field
+func otlpLogRecordFromEvent(p []byte, level zerolog.Level, observed time.Time) (record otlpLogRecord, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	var event map[string]interface{}
+	if err = decoder.Decode(&event); err != nil {
+		return record, err
+	}
+
+	record.ObservedTimeUnixNano = strconv.FormatInt(observed.UnixNano(), 10)
+	record.TimeUnixNano = record.ObservedTimeUnixNano
+	if timestamp, ok := event[zerolog.TimestampFieldName].(string); ok {
+		if parsed, err := time.Parse(zerolog.TimeFieldFormat, timestamp); err == nil {
+			record.TimeUnixNano = strconv.FormatInt(parsed.UnixNano(), 10)
+		}
+	}
+	delete(event, zerolog.TimestampFieldName)
+
+	if levelString, ok := event[zerolog.LevelFieldName].(string); ok {
+		if parsed, ok := otlpLevels[strings.ToLower(levelString)]; ok && level == zerolog.NoLevel {
+			level = parsed
+		}
+		record.SeverityText = strings.ToUpper(levelString)
+		record.SeverityNumber = otlpSeverityNumber(level)
+	}
+	delete(event, zerolog.LevelFieldName)
+
+	message, _ := event[zerolog.MessageFieldName].(string)
+	record.Body = otlpStringValue(message)
+	delete(event, zerolog.MessageFieldName)
+
+	if traceID, ok := event["traceId"].(string); ok && len(traceID) <= 32 {
+		record.TraceID = strings.Repeat("0", 32-len(traceID)) + traceID
+	}
+
+	keys := make([]string, 0, len(event))
+	for key := range event {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: key, Value: otlpValue(event[key])})
+	}
+
+	return record, nil
+}
+
+// otlpSeverityNumber maps zerolog levels to the first severity number of the matching otlp severity range
+func otlpSeverityNumber(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel:
+		return 1
+	case zerolog.DebugLevel:
+		return 5
+	case zerolog.InfoLevel:
+		return 9
+	case zerolog.WarnLevel:
+		return 13
+	case zerolog.ErrorLevel:
+		return 17
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return 21
+	}
+	return 0
+}
+
+func otlpStringValue(value string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &value}
+}
+
+func otlpValue(value interface{}) otlpAnyValue {
+	switch typedValue := value.(type) {
+	case string:
+		return otlpStringValue(typedValue)
+	case bool:
+		return otlpAnyValue{BoolValue: &typedValue}
+	case float64:
+		return otlpAnyValue{DoubleValue: &typedValue}
+	case json.Number:
+		if _, err := strconv.ParseInt(typedValue.String(), 10, 64); err == nil {
+			intValue := typedValue.String()
+			return otlpAnyValue{IntValue: &intValue}
+		}
+		doubleValue, _ := typedValue.Float64()
+		return otlpAnyValue{DoubleValue: &doubleValue}
+	case []interface{}:
+		array := &otlpArrayValue{Values: []otlpAnyValue{}}
+		for _, v := range typedValue {
+			array.Values = append(array.Values, otlpValue(v))
+		}
+		return otlpAnyValue{ArrayValue: array}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		kvlist := &otlpKvlist{Values: []otlpKeyValue{}}
+		for _, key := range keys {
+			kvlist.Values = append(kvlist.Values, otlpKeyValue{Key: key, Value: otlpValue(typedValue[key])})
+		}
+		return otlpAnyValue{KvlistValue: kvlist}
+	}
+
+	return otlpStringValue("")
+}
@@ -0,0 +1,126 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type otlpTestCollector struct {
+	mutex   sync.Mutex
+	headers http.Header
+	bodies  []map[string]interface{}
+}
+
+func startOTLPTestCollector(t *testing.T) *otlpTestCollector {
+	collector := &otlpTestCollector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		_ = json.Unmarshal(data, &body)
+
+		collector.mutex.Lock()
+		defer collector.mutex.Unlock()
+		if r.URL.Path == "/v1/logs" {
+			collector.headers = r.Header
+			collector.bodies = append(collector.bodies, body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	return collector
+}
+
+func TestOTLPLogExporter(t *testing.T) {
+	t.Run("ExportsLogEventsAsOTLPJSON", func(t *testing.T) {
+		collector := startOTLPTestCollector(t)
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret%20value")
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=production")
+		exporter := newOTLPLogExporterFromEnv(ApplicationInfo{App: "myapp", Version: "1.0.0"})
+		logger := zerolog.New(exporter).With().Timestamp().Logger()
+		logger.Warn().Str("path", "/api").Int("status", 404).Msg("Not found")
+
+		// act
+		err := exporter.flush(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "secret value", collector.headers.Get("api-key"))
+		if assert.Equal(t, 1, len(collector.bodies)) {
+			resourceLogs := collector.bodies[0]["resourceLogs"].([]interface{})[0].(map[string]interface{})
+			resourceAttributes := resourceLogs["resource"].(map[string]interface{})["attributes"].([]interface{})
+			assert.Contains(t, resourceAttributes, map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "myapp"}})
+			assert.Contains(t, resourceAttributes, map[string]interface{}{"key": "deployment.environment", "value": map[string]interface{}{"stringValue": "production"}})
+
+			record := resourceLogs["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, "WARN", record["severityText"])
+			assert.Equal(t, float64(13), record["severityNumber"])
+			assert.Equal(t, map[string]interface{}{"stringValue": "Not found"}, record["body"])
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"key": "path", "value": map[string]interface{}{"stringValue": "/api"}},
+				map[string]interface{}{"key": "status", "value": map[string]interface{}{"intValue": "404"}},
+			}, record["attributes"])
+		}
+	})
+
+	t.Run("DoesNothingWithoutRecords", func(t *testing.T) {
+		collector := startOTLPTestCollector(t)
+		exporter := newOTLPLogExporterFromEnv(ApplicationInfo{App: "myapp"})
+
+		// act
+		err := exporter.flush(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(collector.bodies))
+	})
+}
+
+func TestOTLPLogRecordFromEvent(t *testing.T) {
+	t.Run("MapsSeverityOfUppercaseLevelWithoutLevel", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		zerolog.LevelFieldMarshalFunc = func(l zerolog.Level) string { return strings.ToUpper(l.String()) }
+
+		// act
+		record, err := otlpLogRecordFromEvent([]byte(`{"level":"ERROR","message":"Failed"}`), zerolog.NoLevel, time.Now())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ERROR", record.SeverityText)
+		assert.Equal(t, 17, record.SeverityNumber)
+	})
+
+	t.Run("MapsSeverityOfLevelEventWasLoggedWith", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		zerolog.LevelFieldName = "loglevel"
+
+		// act
+		record, err := otlpLogRecordFromEvent([]byte(`{"loglevel":"FATAL","message":"Exiting"}`), zerolog.FatalLevel, time.Now())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "FATAL", record.SeverityText)
+		assert.Equal(t, 21, record.SeverityNumber)
+	})
+}
+
+func TestOTLPValue(t *testing.T) {
+	t.Run("ConvertsNestedValues", func(t *testing.T) {
+		var event map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(`{"kubernetes":{"podName":"app-1"},"tags":["a"],"ratio":0.5,"ok":true}`))
+		decoder.UseNumber()
+		_ = decoder.Decode(&event)
+
+		// act
+		value := otlpValue(event)
+
+		data, _ := json.Marshal(value)
+		assert.Equal(t, `{"kvlistValue":{"values":[{"key":"kubernetes","value":{"kvlistValue":{"values":[{"key":"podName","value":{"stringValue":"app-1"}}]}}},{"key":"ok","value":{"boolValue":true}},{"key":"ratio","value":{"doubleValue":0.5}},{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"}]}}}]}}`, string(data))
+	})
+}
@@ -2,36 +2,257 @@ package foundation
 
 import (
 	"fmt"
-	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// ProbeConfig is used to configure the liveness and readiness endpoints
+type ProbeConfig struct {
+	ErrorHandler     func(err error)
+	UnixSocket       string
+	BindAddress      string
+	LivenessFunc     func() error
+	ReadinessFunc    func() error
+	LivenessPath     string
+	ReadinessPath    string
+	LivenessAliases  []string
+	ReadinessAliases []string
+	ServerGroup      *ServerGroup
+}
+
+// ProbeOption allows to override probe config
+type ProbeOption func(*ProbeConfig)
+
+// WithProbeErrorHandler passes errors binding or serving the probe listener to handler, for example to pick another
+// port, instead of exiting the application
+func WithProbeErrorHandler(handler func(err error)) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithProbeServerGroup runs the probe server as part of group, which returns its errors from Wait instead of exiting
+// the application
+func WithProbeServerGroup(group *ServerGroup) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.ServerGroup = group
+	}
+}
+
+// WithProbeUnixSocket serves the probes on the unix socket at path instead of a tcp port, for exec probes or sidecars
+// checking the application over a shared volume
+func WithProbeUnixSocket(path string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.UnixSocket = path
+	}
+}
+
+// WithProbeBindAddress binds the probe listener to a specific address, like 127.0.0.1 or ::1, instead of all
+// interfaces
+// default is envvar ESTAFETTE_PROBE_BIND_ADDRESS or all interfaces if not set
+func WithProbeBindAddress(address string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.BindAddress = address
+	}
+}
+
+// WithLivenessFunc makes the /liveness endpoint return 500 with the error when livenessFunc fails, for example when a
+// deadlock is detected, so kubernetes restarts the container
+func WithLivenessFunc(livenessFunc func() error) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.LivenessFunc = livenessFunc
+	}
+}
+
+// WithReadinessFunc makes the /readiness endpoint return 503 with the error when readinessFunc fails, for example when
+// a dependency can't be pinged, so kubernetes stops routing traffic to the container
+func WithReadinessFunc(readinessFunc func() error) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.ReadinessFunc = readinessFunc
+	}
+}
+
+// WithLivenessPath serves the liveness probe on path instead of /liveness
+// default is envvar ESTAFETTE_LIVENESS_PATH or /liveness if not set
+func WithLivenessPath(path string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.LivenessPath = path
+	}
+}
+
+// WithReadinessPath serves the readiness probe on path instead of /readiness
+// default is envvar ESTAFETTE_READINESS_PATH or /readiness if not set
+func WithReadinessPath(path string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.ReadinessPath = path
+	}
+}
+
+// WithLivenessAliases serves the liveness probe on the extra paths as well, for example to match probe path standards
+// while keeping /liveness working during a migration
+func WithLivenessAliases(paths ...string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.LivenessAliases = append(c.LivenessAliases, paths...)
+	}
+}
+
+// WithReadinessAliases serves the readiness probe on the extra paths as well
+func WithReadinessAliases(paths ...string) ProbeOption {
+	return func(c *ProbeConfig) {
+		c.ReadinessAliases = append(c.ReadinessAliases, paths...)
+	}
+}
+
+// WithKubernetesProbePaths serves the liveness probe on /livez and /healthz and the readiness probe on /readyz, the
+// paths Kubernetes components use, in addition to /liveness and /readiness
+func WithKubernetesProbePaths() ProbeOption {
+	return func(c *ProbeConfig) {
+		c.LivenessAliases = append(c.LivenessAliases, "/livez", "/healthz")
+		c.ReadinessAliases = append(c.ReadinessAliases, "/readyz")
+	}
+}
+
+func newProbeConfig(opts ...ProbeOption) ProbeConfig {
+	// default
+	config := ProbeConfig{
+		LivenessPath:  "/liveness",
+		ReadinessPath: "/readiness",
+		BindAddress:   os.Getenv("ESTAFETTE_PROBE_BIND_ADDRESS"),
+	}
+
+	if path := os.Getenv("ESTAFETTE_LIVENESS_PATH"); path != "" {
+		config.LivenessPath = path
+	}
+	if path := os.Getenv("ESTAFETTE_READINESS_PATH"); path != "" {
+		config.ReadinessPath = path
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
 // InitLivenessAndReadiness initializes the /liveness and /readiness endpoint on port 5000
-func InitLivenessAndReadiness() {
-	InitLivenessAndReadinessWithPort(5000)
-}
-
-// InitLivenessAndReadinessWithPort initializes the /liveness and /readiness endpoint on specified port
-func InitLivenessAndReadinessWithPort(port int) {
-	// start liveness endpoint
-	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
-			Str("port", portString).
-			Msg("Serving /liveness and /readiness endpoints...")
-
-		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/liveness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm alive!\n")
-		})
-		serverMux.HandleFunc("/readiness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm ready!\n")
-		})
-
-		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /liveness and /readiness listener failed")
+func InitLivenessAndReadiness(opts ...ProbeOption) *http.Server {
+	return InitLivenessAndReadinessWithPort(5000, opts...)
+}
+
+// InitLivenessAndReadinessFromEnv initializes the liveness and readiness endpoints on the port set in envvar
+// ESTAFETTE_PROBE_PORT, or 5000 if not set, and on the paths set in ESTAFETTE_LIVENESS_PATH and
+// ESTAFETTE_READINESS_PATH, so helm charts can override them without code changes
+func InitLivenessAndReadinessFromEnv(opts ...ProbeOption) *http.Server {
+	return InitLivenessAndReadinessWithPort(probePortFromEnv(), opts...)
+}
+
+// probePortFromEnv returns the port set in envvar ESTAFETTE_PROBE_PORT, or 5000 if not set
+func probePortFromEnv() int {
+	port := 5000
+	if value := os.Getenv("ESTAFETTE_PROBE_PORT"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Warn().Err(err).Msgf("ESTAFETTE_PROBE_PORT %v is not a valid port, using port %v", value, port)
+		} else {
+			port = parsed
+		}
+	}
+
+	return port
+}
+
+// InitLivenessAndReadinessWithPort initializes the /liveness and /readiness endpoint on specified port; shut down the
+// returned server to release the port, for example with HandleGracefulShutdown(gracefulShutdown, waitGroup,
+// ShutdownHTTPServer(server))
+func InitLivenessAndReadinessWithPort(port int, opts ...ProbeOption) *http.Server {
+	config := newProbeConfig(opts...)
+
+	serverMux := http.NewServeMux()
+	config.handleLiveness(serverMux)
+	config.handleReadiness(serverMux)
+
+	return startProbeServer(port, config.LivenessPath+" and "+config.ReadinessPath, serverMux, config)
+}
+
+// livenessHandler runs the liveness func if set before the default liveness handler
+func (c ProbeConfig) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	if c.LivenessFunc != nil {
+		if err := c.LivenessFunc(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "I'm not alive: %v\n", err)
+			return
+		}
+	}
+
+	livenessHandler(w, r)
+}
+
+// readinessHandler runs the readiness func if set before the default readiness handler
+func (c ProbeConfig) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if c.ReadinessFunc != nil {
+		if err := c.ReadinessFunc(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "I'm not ready: %v\n", err)
+			return
 		}
-	}()
+	}
+
+	readinessHandler(w, r)
+}
+
+// handleLiveness serves the liveness probe on its path and aliases
+func (c ProbeConfig) handleLiveness(serverMux *http.ServeMux) {
+	for _, path := range probePaths(c.LivenessPath, c.LivenessAliases) {
+		serverMux.HandleFunc(path, c.livenessHandler)
+	}
+}
+
+// handleReadiness serves the readiness probe on its path and aliases
+func (c ProbeConfig) handleReadiness(serverMux *http.ServeMux) {
+	atomic.StoreInt32(&readinessServed, 1)
+
+	for _, path := range probePaths(c.ReadinessPath, c.ReadinessAliases) {
+		serverMux.HandleFunc(path, c.readinessHandler)
+	}
+}
+
+// probePaths returns path and its aliases without duplicates, since registering a path twice on a mux panics, for
+// example when WithLivenessPath("/livez") is combined with WithKubernetesProbePaths
+func probePaths(path string, aliases []string) []string {
+	paths := []string{path}
+	for _, alias := range aliases {
+		if !StringArrayContains(paths, alias) {
+			paths = append(paths, alias)
+		}
+	}
+
+	return paths
+}
+
+// startProbeServer serves the probe endpoints on mux together with /startup, /info and /debug/env on port
+func startProbeServer(port int, endpoints string, serverMux *http.ServeMux, config ProbeConfig) *http.Server {
+	serverMux.HandleFunc("/startup", startupHandler)
+	serverMux.HandleFunc("/info", InfoHandler)
+	serverMux.HandleFunc("/debug/env", EnvHandler)
+
+	server := &http.Server{
+		Addr:              bindAddress(config.BindAddress, port),
+		Handler:           serverMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Debug().
+		Str("port", server.Addr).
+		Str("unixSocket", config.UnixSocket).
+		Msgf("Serving %v endpoints...", endpoints)
+
+	listenAndServeInBackground(server, config.UnixSocket, endpoints, config.ErrorHandler, config.ServerGroup)
+
+	return server
 }
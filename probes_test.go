@@ -1,9 +1,16 @@
 package foundation
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sethgrid/pester"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +21,8 @@ func TestInitLivenessAndReadiness(t *testing.T) {
 	t.Run("Returns200OKForLiveness", func(t *testing.T) {
 
 		// act
-		InitLivenessAndReadinessWithPort(5002)
+		server := InitLivenessAndReadinessWithPort(5002)
+		defer ShutdownHTTPServer(server)()
 
 		resp, err := pester.Get("http://localhost:5002/liveness")
 
@@ -34,9 +42,10 @@ func TestInitLivenessAndReadiness(t *testing.T) {
 	t.Run("Returns200OKForReadiness", func(t *testing.T) {
 
 		// act
-		InitLivenessAndReadinessWithPort(5003)
+		server := InitLivenessAndReadinessWithPort(5003)
+		defer ShutdownHTTPServer(server)()
 
-		resp, err := http.Get("http://localhost:5003/readiness")
+		resp, err := pester.Get("http://localhost:5003/readiness")
 
 		if assert.Nil(t, err) {
 
@@ -50,4 +59,213 @@ func TestInitLivenessAndReadiness(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("ReturnsApplicationAndResourceInfo", func(t *testing.T) {
+
+		setApplicationInfo(NewApplicationInfo("estafette", "myapp", "1.0.0", "main", "a1b2c3d", "2020-01-01T00:00:00Z"))
+
+		// act
+		server := InitLivenessAndReadinessWithPort(5004)
+		defer ShutdownHTTPServer(server)()
+
+		resp, err := pester.Get("http://localhost:5004/info")
+
+		if assert.Nil(t, err) {
+
+			assert.Equal(t, 200, resp.StatusCode)
+
+			defer resp.Body.Close()
+			var info infoResponse
+			err = json.NewDecoder(resp.Body).Decode(&info)
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, "myapp", info.App)
+				assert.Equal(t, "1.0.0", info.Version)
+				assert.True(t, info.Resources.GOMAXPROCS > 0)
+			}
+		}
+	})
+}
+
+func TestWithProbeErrorHandler(t *testing.T) {
+	t.Run("ReceivesBindErrorInsteadOfExiting", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		var handledErr error
+
+		// act
+		InitLivenessAndReadinessWithPort(listener.Addr().(*net.TCPAddr).Port, WithProbeErrorHandler(func(err error) { handledErr = err }))
+
+		assert.NotNil(t, handledErr)
+	})
+}
+
+func TestWithProbeUnixSocket(t *testing.T) {
+	t.Run("ServesProbesOnUnixSocket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "probes.sock")
+
+		// act
+		server := InitLivenessAndReadinessWithPort(0, WithProbeUnixSocket(socket))
+		defer ShutdownHTTPServer(server)()
+
+		response, err := unixSocketTestClient(socket).Get("http://localhost/liveness")
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+}
+
+func TestWithLivenessAndReadinessFunc(t *testing.T) {
+	t.Run("ReturnsErrorStatusIfFuncsFail", func(t *testing.T) {
+		config := newProbeConfig(
+			WithLivenessFunc(func() error { return errors.New("Deadlock detected") }),
+			WithReadinessFunc(func() error { return errors.New("Database unreachable") }),
+		)
+		liveness := httptest.NewRecorder()
+		readiness := httptest.NewRecorder()
+
+		// act
+		config.livenessHandler(liveness, httptest.NewRequest(http.MethodGet, "/liveness", nil))
+		config.readinessHandler(readiness, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, liveness.Code)
+		assert.Equal(t, "I'm not alive: Deadlock detected\n", liveness.Body.String())
+		assert.Equal(t, http.StatusServiceUnavailable, readiness.Code)
+		assert.Equal(t, "I'm not ready: Database unreachable\n", readiness.Body.String())
+	})
+
+	t.Run("Returns200OKIfFuncsSucceed", func(t *testing.T) {
+		config := newProbeConfig(
+			WithLivenessFunc(func() error { return nil }),
+			WithReadinessFunc(func() error { return nil }),
+		)
+		liveness := httptest.NewRecorder()
+		readiness := httptest.NewRecorder()
+
+		// act
+		config.livenessHandler(liveness, httptest.NewRequest(http.MethodGet, "/liveness", nil))
+		config.readinessHandler(readiness, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusOK, liveness.Code)
+		assert.Equal(t, http.StatusOK, readiness.Code)
+	})
+}
+
+func TestInitLivenessAndReadinessWithPort(t *testing.T) {
+	t.Run("ReleasesPortOnShutdown", func(t *testing.T) {
+		port := freeTestPort(t)
+		server := InitLivenessAndReadinessWithPort(port)
+		assert.Eventually(t, func() bool {
+			response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/liveness", port))
+			if err != nil {
+				return false
+			}
+			response.Body.Close()
+			return response.StatusCode == http.StatusOK
+		}, time.Second, 10*time.Millisecond)
+
+		// act
+		ShutdownHTTPServer(server)()
+
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if assert.Nil(t, err) {
+			listener.Close()
+		}
+	})
+}
+
+func TestInitLivenessAndReadinessFromEnv(t *testing.T) {
+	t.Run("ServesOnPortAndPathsFromEnv", func(t *testing.T) {
+		port := freeTestPort(t)
+		t.Setenv("ESTAFETTE_PROBE_PORT", fmt.Sprint(port))
+		t.Setenv("ESTAFETTE_LIVENESS_PATH", "/healthz/live")
+		t.Setenv("ESTAFETTE_READINESS_PATH", "/healthz/ready")
+
+		// act
+		server := InitLivenessAndReadinessFromEnv()
+		defer ShutdownHTTPServer(server)()
+
+		for _, path := range []string{"/healthz/live", "/healthz/ready"} {
+			response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v%v", port, path))
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.Equal(t, http.StatusOK, response.StatusCode, path)
+			}
+		}
+	})
+}
+
+func TestWithKubernetesProbePaths(t *testing.T) {
+	t.Run("ServesProbesOnConventionalPathsAsWell", func(t *testing.T) {
+		port := freeTestPort(t)
+
+		// act
+		server := InitLivenessAndReadinessWithPort(port, WithKubernetesProbePaths())
+		defer ShutdownHTTPServer(server)()
+
+		for _, path := range []string{"/liveness", "/readiness", "/livez", "/healthz", "/readyz"} {
+			response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v%v", port, path))
+			if assert.Nil(t, err) {
+				response.Body.Close()
+				assert.Equal(t, http.StatusOK, response.StatusCode, path)
+			}
+		}
+	})
+
+	t.Run("ReplacesDefaultPathsWhenCombinedWithPathOptions", func(t *testing.T) {
+		mux := http.NewServeMux()
+		config := newProbeConfig(WithLivenessPath("/livez"), WithReadinessPath("/readyz"), WithLivenessAliases("/healthz"))
+
+		// act
+		config.handleLiveness(mux)
+		config.handleReadiness(mux)
+
+		for path, expectedStatus := range map[string]int{"/livez": http.StatusOK, "/healthz": http.StatusOK, "/readyz": http.StatusOK, "/liveness": http.StatusNotFound, "/readiness": http.StatusNotFound} {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, expectedStatus, recorder.Code, path)
+		}
+	})
+
+	t.Run("ServesPathOnceWhenItEqualsAnAlias", func(t *testing.T) {
+		t.Setenv("ESTAFETTE_READINESS_PATH", "/readyz")
+		mux := http.NewServeMux()
+		config := newProbeConfig(WithLivenessPath("/livez"), WithKubernetesProbePaths(), WithLivenessAliases("/healthz"))
+
+		// act
+		config.handleLiveness(mux)
+		config.handleReadiness(mux)
+
+		for _, path := range []string{"/livez", "/healthz", "/readyz"} {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, recorder.Code, path)
+		}
+	})
+}
+
+func TestWithProbeBindAddress(t *testing.T) {
+	t.Run("BindsToAddressOnly", func(t *testing.T) {
+		port := freeTestPort(t)
+
+		// act
+		server := InitLivenessAndReadinessWithPort(port, WithProbeBindAddress("127.0.0.1"))
+		defer ShutdownHTTPServer(server)()
+
+		assert.Equal(t, fmt.Sprintf("127.0.0.1:%v", port), server.Addr)
+		response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/liveness", port))
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+	})
+
+	t.Run("AddsBracketsAroundIPv6Address", func(t *testing.T) {
+		// act
+		address := bindAddress("::1", 5000)
+
+		assert.Equal(t, "[::1]:5000", address)
+	})
 }
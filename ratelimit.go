@@ -0,0 +1,203 @@
+package foundation
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// TokenBucket is a rate limiter that refills tokens at a constant rate up to a maximum burst size
+type TokenBucket struct {
+	mutex        sync.Mutex
+	ratePerSec   float64
+	burst        int
+	tokens       float64
+	lastRefilled time.Time
+}
+
+// NewTokenBucket returns a full TokenBucket refilling ratePerSecond tokens per second up to burst tokens
+func NewTokenBucket(ratePerSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		ratePerSec:   ratePerSecond,
+		burst:        burst,
+		tokens:       float64(burst),
+		lastRefilled: currentClock().Now(),
+	}
+}
+
+// Allow takes a token if available; if not it returns false and how long to wait for the next token
+func (tb *TokenBucket) Allow() (allowed bool, retryAfter time.Duration) {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.refill(currentClock().Now())
+
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return true, 0
+	}
+
+	if tb.ratePerSec <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+
+	return false, time.Duration((1 - tb.tokens) / tb.ratePerSec * float64(time.Second))
+}
+
+// SetLimit changes the refill rate and burst size, keeping the tokens currently available up to the new burst size
+func (tb *TokenBucket) SetLimit(ratePerSecond float64, burst int) {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.refill(currentClock().Now())
+	tb.ratePerSec = ratePerSecond
+	tb.burst = burst
+	tb.tokens = math.Min(tb.tokens, float64(burst))
+}
+
+// isFull returns true if the bucket has been refilled completely, meaning it's been idle
+func (tb *TokenBucket) isFull(now time.Time) bool {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.refill(now)
+	return tb.tokens >= float64(tb.burst)
+}
+
+func (tb *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(tb.lastRefilled).Seconds()
+	if elapsed > 0 {
+		tb.tokens = math.Min(float64(tb.burst), tb.tokens+elapsed*tb.ratePerSec)
+		tb.lastRefilled = now
+	}
+}
+
+// RateLimiter keeps a TokenBucket per client key, so each client is limited independently
+type RateLimiter struct {
+	mutex       sync.Mutex
+	ratePerSec  float64
+	burst       int
+	buckets     map[string]*TokenBucket
+	lastCleanup time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing each client ratePerSecond requests per second with bursts of up to burst requests
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		ratePerSec:  ratePerSecond,
+		burst:       burst,
+		buckets:     map[string]*TokenBucket{},
+		lastCleanup: currentClock().Now(),
+	}
+}
+
+// Allow takes a token from the bucket for key; if none is available it returns false and how long to wait for the next token
+func (rl *RateLimiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	return rl.bucket(key).Allow()
+}
+
+// SetLimit changes the limits for all clients, for example after reloading configuration
+func (rl *RateLimiter) SetLimit(ratePerSecond float64, burst int) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.ratePerSec = ratePerSecond
+	rl.burst = burst
+	for _, bucket := range rl.buckets {
+		bucket.SetLimit(ratePerSecond, burst)
+	}
+}
+
+func (rl *RateLimiter) bucket(key string) *TokenBucket {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	// remove buckets of clients that have been idle long enough to be refilled completely
+	now := currentClock().Now()
+	if now.Sub(rl.lastCleanup) > time.Minute {
+		for k, bucket := range rl.buckets {
+			if bucket.isFull(now) {
+				delete(rl.buckets, k)
+			}
+		}
+		rl.lastCleanup = now
+	}
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = NewTokenBucket(rl.ratePerSec, rl.burst)
+		rl.buckets[key] = bucket
+	}
+
+	return bucket
+}
+
+// RateLimitKeyFunc returns the key identifying the client a request is limited by
+type RateLimitKeyFunc func(r *http.Request) string
+
+// ClientIPKey limits requests by the ip address of the peer they're received from; the X-Forwarded-For header is set by
+// the client, so behind a load balancer or ingress use TrustedProxyClientIPKey instead
+func ClientIPKey(r *http.Request) string {
+	return remoteIP(r)
+}
+
+// TrustedProxyClientIPKey limits requests by client ip address, taken from the X-Forwarded-For header for requests
+// received from one of the trusted proxies, given as ip addresses or cidr ranges, and the peer address otherwise
+// RateLimit(NewRateLimiter(10, 20), TrustedProxyClientIPKey("10.0.0.0/8"))
+func TrustedProxyClientIPKey(trustedProxies ...string) RateLimitKeyFunc {
+	networks, invalid := parseNetworks(trustedProxies)
+	if len(invalid) > 0 {
+		log.Warn().Strs("proxies", invalid).Msg("Ignoring trusted proxies that aren't an ip address or cidr range")
+	}
+
+	return func(r *http.Request) string {
+		return forwardedClientIP(r, networks)
+	}
+}
+
+// HeaderKey limits requests by the value of a header, like an api key, falling back to the peer ip address if the header is missing
+func HeaderKey(header string) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		if value := r.Header.Get(header); value != "" {
+			return value
+		}
+		return remoteIP(r)
+	}
+}
+
+var rateLimitedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "http_rate_limited_total",
+	Help: "Total number of http requests rejected by the rate limiting middleware.",
+})
+
+// registerRateLimitMetrics exposes http_rate_limited_total
+func registerRateLimitMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, rateLimitedTotal)
+}
+
+// RateLimit returns a middleware that responds with 429 Too Many Requests and a Retry-After header when a client exceeds its rate limit
+// RateLimit(NewRateLimiter(10, 20), ClientIPKey)
+func RateLimit(limiter *RateLimiter, keyFunc RateLimitKeyFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(keyFunc(r))
+			if allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rateLimitedTotal.Inc()
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, http.StatusText(http.StatusTooManyRequests)+"\n")
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+
+	t.Run("AllowsUpToBurstRequests", func(t *testing.T) {
+
+		tokenBucket := NewTokenBucket(1, 3)
+
+		for i := 0; i < 3; i++ {
+			allowed, _ := tokenBucket.Allow()
+			assert.True(t, allowed)
+		}
+
+		// act
+		allowed, retryAfter := tokenBucket.Allow()
+
+		assert.False(t, allowed)
+		assert.True(t, retryAfter > 0)
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+
+	t.Run("Returns429WithRetryAfterWhenClientExceedsLimit", func(t *testing.T) {
+
+		handler := RateLimit(NewRateLimiter(1, 1), ClientIPKey)(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("LimitsClientsIndependently", func(t *testing.T) {
+
+		handler := RateLimit(NewRateLimiter(1, 1), HeaderKey("X-API-Key"))(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-API-Key", "client-1")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		otherRequest := httptest.NewRequest("GET", "/", nil)
+		otherRequest.Header.Set("X-API-Key", "client-2")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, otherRequest)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("AppliesChangedLimits", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		limiter := NewRateLimiter(1, 1)
+		handler := RateLimit(limiter, ClientIPKey)(http.NotFoundHandler())
+		request := httptest.NewRequest("GET", "/", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		recorder := httptest.NewRecorder()
+
+		// act
+		limiter.SetLimit(1000, 1)
+
+		clock.Advance(time.Millisecond)
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestClientIPKey(t *testing.T) {
+	t.Run("IgnoresXForwardedForHeader", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+		// act
+		key := ClientIPKey(request)
+
+		assert.Equal(t, "10.0.0.1", key)
+	})
+}
+
+func TestTrustedProxyClientIPKey(t *testing.T) {
+	t.Run("UsesLastUntrustedAddressInXForwardedForFromTrustedProxy", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 10.0.0.2")
+
+		// act
+		key := TrustedProxyClientIPKey("10.0.0.0/8")(request)
+
+		assert.Equal(t, "2.2.2.2", key)
+	})
+
+	t.Run("IgnoresXForwardedForFromUntrustedPeer", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.RemoteAddr = "192.168.1.1:1234"
+		request.Header.Set("X-Forwarded-For", "1.1.1.1")
+
+		// act
+		key := TrustedProxyClientIPKey("10.0.0.1")(request)
+
+		assert.Equal(t, "192.168.1.1", key)
+	})
+}
@@ -1,34 +1,129 @@
 package foundation
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-
-	"github.com/rs/zerolog/log"
+	"strings"
+	"sync/atomic"
 )
 
 // InitReadiness initializes the /readiness endpoint on port 5000
-func InitReadiness() {
-	InitReadinessWithPort(5000)
+func InitReadiness(opts ...ProbeOption) *http.Server {
+	return InitReadinessWithPort(5000, opts...)
+}
+
+// InitReadinessWithPort initializes the /readiness endpoint on specified port; shut down the returned server to release
+// the port
+func InitReadinessWithPort(port int, opts ...ProbeOption) *http.Server {
+	config := newProbeConfig(opts...)
+
+	serverMux := http.NewServeMux()
+	config.handleReadiness(serverMux)
+
+	return startProbeServer(port, config.ReadinessPath, serverMux, config)
+}
+
+var (
+	// notReady is set with SetReady(false); its zero value keeps the application ready by default
+	notReady int32
+
+	// readinessServed is set once a readiness endpoint is served, so HandleGracefulShutdown knows to flip it
+	readinessServed int32
+)
+
+// SetReady flips the /readiness endpoint to 503 when passed false, for example during migrations or while draining on
+// shutdown, and back when passed true
+func SetReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&notReady, 0)
+	} else {
+		atomic.StoreInt32(&notReady, 1)
+	}
+}
+
+// IsReady returns false if the application has been marked as not ready with SetReady(false)
+func IsReady() bool {
+	return atomic.LoadInt32(&notReady) == 0
+}
+
+// readinessReport is the outcome of the readiness checks, rendered as text or as json if requested with an Accept
+// header of application/json
+type readinessReport struct {
+	Ready        bool                `json:"ready"`
+	Reason       string              `json:"reason"`
+	StartupTasks []StartupTaskStatus `json:"startupTasks,omitempty"`
+	Checks       []HealthCheckResult `json:"checks,omitempty"`
 }
 
-// InitReadinessWithPort initializes the /readiness endpoint on specified port
-func InitReadinessWithPort(port int) {
-	// start liveness endpoint
-	go func() {
-		portString := fmt.Sprintf(":%v", port)
-		log.Debug().
-			Str("port", portString).
-			Msg("Serving /readiness endpoint...")
-
-		serverMux := http.NewServeMux()
-		serverMux.HandleFunc("/readiness", func(w http.ResponseWriter, _ *http.Request) {
-			io.WriteString(w, "I'm ready!\n")
-		})
-
-		if err := http.ListenAndServe(portString, serverMux); err != nil {
-			log.Fatal().Err(err).Msg("Starting /readiness listener failed")
+// readinessHandler responds with 200 once the application is ready to receive traffic and with 503 before that, when
+// the metrics server failed, when marked as not ready with SetReady, while startup tasks haven't succeeded or when any
+// required health check fails
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := evaluateReadiness(r.Context())
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	if !report.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprintf(w, "%v\n", report.Reason)
+	for _, task := range report.StartupTasks {
+		if task.Status == "failed" {
+			fmt.Fprintf(w, "%v: %v: %v\n", task.Name, task.Status, task.Error)
+		} else if task.Status != "succeeded" {
+			fmt.Fprintf(w, "%v: %v\n", task.Name, task.Status)
+		}
+	}
+	for _, result := range report.Checks {
+		if result.Required && !result.Healthy() {
+			fmt.Fprintf(w, "%v: %v\n", result.Name, result.Error)
 		}
-	}()
+	}
+}
+
+func evaluateReadiness(ctx context.Context) readinessReport {
+	report := readinessReport{
+		StartupTasks: StartupTasksStatus(),
+	}
+
+	if !IsReady() {
+		report.Reason = "I'm not ready!"
+		return report
+	}
+
+	if err := MetricsServerError(); err != nil {
+		report.Reason = fmt.Sprintf("My metrics server failed: %v", err)
+		return report
+	}
+
+	if !IsWarmedUp() {
+		report.Reason = "I'm warming up!"
+		return report
+	}
+
+	if !startupTasksSucceeded(report.StartupTasks) {
+		report.Reason = "I'm running startup tasks!"
+		return report
+	}
+
+	healthReport := DefaultHealthChecker.Report(ctx)
+	report.Checks = healthReport.Checks
+	if !healthReport.Healthy {
+		report.Reason = "I'm not ready!"
+		return report
+	}
+
+	report.Ready = true
+	report.Reason = "I'm ready!"
+
+	return report
 }
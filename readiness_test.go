@@ -2,6 +2,11 @@ package foundation
 
 import (
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
 	"testing"
 
 	"github.com/sethgrid/pester"
@@ -13,7 +18,8 @@ func TestInitReadiness(t *testing.T) {
 	t.Run("Returns200OK", func(t *testing.T) {
 
 		// act
-		InitReadinessWithPort(5001)
+		server := InitReadinessWithPort(5001)
+		defer ShutdownHTTPServer(server)()
 
 		resp, err := pester.Get("http://localhost:5001/readiness")
 
@@ -30,3 +36,40 @@ func TestInitReadiness(t *testing.T) {
 		}
 	})
 }
+
+func TestSetReady(t *testing.T) {
+	t.Run("MakesReadinessReturn503UntilSetReadyAgain", func(t *testing.T) {
+		defer SetReady(true)
+
+		// act
+		SetReady(false)
+
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.False(t, IsReady())
+
+		SetReady(true)
+
+		recorder = httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, IsReady())
+	})
+}
+
+func TestHandleGracefulShutdownReadiness(t *testing.T) {
+	t.Run("MarksNotReadyBeforeRunningShutdownFunctions", func(t *testing.T) {
+		defer SetReady(true)
+		newProbeConfig().handleReadiness(http.NewServeMux())
+		gracefulShutdown := make(chan os.Signal, 1)
+		gracefulShutdown <- syscall.SIGTERM
+		var readyDuringShutdown bool
+
+		// act
+		HandleGracefulShutdown(gracefulShutdown, &sync.WaitGroup{}, func() { readyDuringShutdown = IsReady() })
+
+		assert.False(t, readyDuringShutdown)
+		assert.False(t, IsReady())
+	})
+}
@@ -0,0 +1,70 @@
+package foundation
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+var httpPanicsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "http_panics_total",
+	Help: "Total number of panics recovered in http handlers.",
+})
+
+// registerRecoveryMetrics exposes http_panics_total
+func registerRecoveryMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, httpPanicsTotal)
+}
+
+// Recovery returns a middleware that recovers panics in the wrapped handler, logs them with a structured stack trace,
+// counts them in the http_panics_total metric and responds with a 500 status code
+func Recovery() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := newResponseWriter(w)
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				// let net/http abort the response as intended
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				httpPanicsTotal.Inc()
+				reportError(r.Context(), fmt.Errorf("Recovered from panic: %v", recovered), false, 3, map[string]string{"method": r.Method, "path": r.URL.Path})
+
+				log.Error().
+					Str("method", r.Method).
+					Str("path", r.URL.Path).
+					Strs("stack", stackTrace(3)).
+					Msgf("Recovered from panic: %v", recovered)
+
+				if !rw.wroteHeader {
+					w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+					w.Header().Set("X-Content-Type-Options", "nosniff")
+					w.WriteHeader(http.StatusInternalServerError)
+					io.WriteString(w, http.StatusText(http.StatusInternalServerError)+"\n")
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// stackTrace returns the stack of the calling goroutine as one 'function file:line' entry per frame, so it can be logged
+// as a structured array instead of multi-line text
+func stackTrace(skip int) (stack []string) {
+	for _, frame := range stackFrames(skip + 1) {
+		stack = append(stack, fmt.Sprintf("%v %v:%v", frame.Function, frame.File, frame.Line))
+	}
+
+	return
+}
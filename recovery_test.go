@@ -0,0 +1,48 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+
+	t.Run("Returns500AndLogsStackTraceOnPanic", func(t *testing.T) {
+
+		buffer := captureLogs(t)
+		handler := Recovery()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("something went terribly wrong")
+		}))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(t, "Internal Server Error\n", recorder.Body.String())
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "Recovered from panic: something went terribly wrong", event["message"])
+			assert.NotEmpty(t, event["stack"])
+		}
+	})
+
+	t.Run("PassesThroughIfNoPanic", func(t *testing.T) {
+
+		handler := Recovery()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+	})
+}
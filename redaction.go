@@ -0,0 +1,190 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const maskedValue = "***"
+
+var (
+	secretsMutex sync.RWMutex
+	secrets      []string
+
+	// secretPatterns match secrets that haven't been registered; only the part captured by the group named secret
+	// is redacted, or the entire match if there's no such group
+	secretPatterns = DefaultRedactionPatterns()
+)
+
+// DefaultRedactionPatterns returns the patterns redacted from all log output and command output by default, matching
+// bearer and basic authorization tokens, passwords in urls and password, token, secret and api key values in key=value,
+// json and command line flag form
+func DefaultRedactionPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{16,}=*)`),
+		regexp.MustCompile(`(?i)authorization\\?"?\s*[:=]\s*\\?"?basic\s+(?P<secret>[A-Za-z0-9+/]+=*)`),
+		regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.\-]*://[^:/@\s"]+:(?P<secret>[^@/\s"]+)@`),
+		regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9])-{1,2}(?:password|passwd|secret|token|api-?key|client-secret)[= ](?P<secret>[^\s"]+)`),
+		regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api_?key|access_?key)\\?"?\s*[:=]\s*\\?"?(?P<secret>[^\s"\\&,;]+)`),
+	}
+}
+
+// RedactSecrets registers values that should never show up in log output or the output of commands run with the
+// RunCommand functions; they're replaced by ***
+func RedactSecrets(values ...string) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		// json formats like the zerolog output escape quotes, backslashes and newlines
+		escaped, _ := json.Marshal(value)
+		for _, v := range []string{value, string(escaped[1 : len(escaped)-1])} {
+			if !StringArrayContains(secrets, v) {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+
+	// replace longer secrets first in case one contains another
+	sort.SliceStable(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// RedactPatterns registers patterns in addition to the DefaultRedactionPatterns; use a group named secret to only
+// redact part of a match, like (?i)x-api-key:\s*(?P<secret>\S+)
+func RedactPatterns(patterns ...*regexp.Regexp) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secretPatterns = append(secretPatterns, patterns...)
+}
+
+// RedactString returns the input with all registered secrets and values matching the redaction patterns replaced by ***
+func RedactString(in string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+
+	for _, secret := range secrets {
+		in = strings.ReplaceAll(in, secret, maskedValue)
+	}
+
+	for _, pattern := range secretPatterns {
+		in = redactPattern(in, pattern)
+	}
+
+	return in
+}
+
+// redactPattern replaces the secret group of all matches of the pattern, or the full match if the pattern doesn't have
+// a secret group
+func redactPattern(in string, pattern *regexp.Regexp) string {
+	matches := pattern.FindAllStringSubmatchIndex(in, -1)
+	if len(matches) == 0 {
+		return in
+	}
+
+	group := pattern.SubexpIndex("secret")
+
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if group > 0 {
+			start, end = match[2*group], match[2*group+1]
+		}
+		if start < 0 || start < last || start == end {
+			continue
+		}
+		builder.WriteString(in[last:start])
+		builder.WriteString(maskedValue)
+		last = end
+	}
+	builder.WriteString(in[last:])
+
+	return builder.String()
+}
+
+// redactingWriter redacts secrets in everything written to it; a secret split over multiple writes isn't redacted,
+// which doesn't happen for log events since they're written at once, for other output use lineRedactingWriter; the
+// level of log events is passed on to writers that implement zerolog.LevelWriter
+type redactingWriter struct {
+	writer io.Writer
+}
+
+func newRedactingWriter(writer io.Writer) zerolog.LevelWriter {
+	return &redactingWriter{writer: writer}
+}
+
+func (rw *redactingWriter) Write(p []byte) (n int, err error) {
+	if _, err = io.WriteString(rw.writer, RedactString(string(p))); err != nil {
+		return 0, err
+	}
+
+	// report the unredacted length, otherwise callers consider it a short write
+	return len(p), nil
+}
+
+func (rw *redactingWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if _, err = writeLevel(rw.writer, level, []byte(RedactString(string(p)))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// maxRedactedLineLength is the length after which lineRedactingWriter writes an incomplete line, so output without
+// newlines isn't buffered indefinitely
+const maxRedactedLineLength = 64 * 1024
+
+// lineRedactingWriter redacts secrets in output that's written in arbitrary chunks, like command output read from a
+// pipe, by buffering it until a line is complete; call Flush once all output is written to write the last line
+type lineRedactingWriter struct {
+	writer io.Writer
+	buffer []byte
+}
+
+func newLineRedactingWriter(writer io.Writer) *lineRedactingWriter {
+	return &lineRedactingWriter{writer: writer}
+}
+
+func (lw *lineRedactingWriter) Write(p []byte) (n int, err error) {
+	lw.buffer = append(lw.buffer, p...)
+
+	end := bytes.LastIndexByte(lw.buffer, '\n') + 1
+	if len(lw.buffer) > maxRedactedLineLength {
+		end = len(lw.buffer)
+	}
+	if end == 0 {
+		return len(p), nil
+	}
+
+	_, err = io.WriteString(lw.writer, RedactString(string(lw.buffer[:end])))
+	lw.buffer = append(lw.buffer[:0], lw.buffer[end:]...)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the buffered incomplete line
+func (lw *lineRedactingWriter) Flush() error {
+	if len(lw.buffer) == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(lw.writer, RedactString(string(lw.buffer)))
+	lw.buffer = lw.buffer[:0]
+
+	return err
+}
@@ -0,0 +1,62 @@
+package foundation
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	reloadMutex   sync.Mutex
+	reloadFuncs   []func() error
+	reloadSignals chan os.Signal
+)
+
+// OnReload registers a callback that's run on SIGHUP, for example to re-read config, reopen log files or refresh
+// certificates without restarting; the first registration installs the SIGHUP handler, which otherwise terminates the
+// application
+// foundation.OnReload(func() error { return config.Load() })
+func OnReload(reload func() error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	reloadFuncs = append(reloadFuncs, reload)
+
+	if reloadSignals != nil {
+		return
+	}
+
+	reloadSignals = make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+
+	go func(signals chan os.Signal) {
+		for s := range signals {
+			log.Info().Msgf("Received signal %v, reloading...", s)
+			Reload()
+		}
+	}(reloadSignals)
+}
+
+// Reload runs all callbacks registered with OnReload in order of registration, as on SIGHUP; a failing callback is
+// logged and doesn't stop the others, and the errors of all failing callbacks are returned
+func Reload() (err error) {
+	reloadMutex.Lock()
+	reloads := append([]func() error{}, reloadFuncs...)
+	reloadMutex.Unlock()
+
+	for i, reload := range reloads {
+		if reloadErr := reload(); reloadErr != nil {
+			log.Warn().Err(reloadErr).Int("callback", i).Msg("Reload callback failed")
+			err = AppendErr(err, fmt.Errorf("Reload callback %v failed: %w", i, reloadErr))
+		}
+	}
+
+	PublishLifecycleEvent(EventConfigReloaded, map[string]string{"callbacks": strconv.Itoa(len(reloads)), "failed": strconv.FormatBool(err != nil)})
+
+	return err
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetReloadFuncs removes the registered reload callbacks for the duration of the test
+func resetReloadFuncs(t *testing.T) {
+	reloadMutex.Lock()
+	original := reloadFuncs
+	reloadFuncs = nil
+	reloadMutex.Unlock()
+	t.Cleanup(func() {
+		reloadMutex.Lock()
+		reloadFuncs = original
+		reloadMutex.Unlock()
+	})
+}
+
+func TestOnReload(t *testing.T) {
+	t.Run("RunsCallbacksOnSIGHUP", func(t *testing.T) {
+		resetReloadFuncs(t)
+		reloaded := make(chan struct{}, 1)
+
+		// act
+		OnReload(func() error {
+			reloaded <- struct{}{}
+			return nil
+		})
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+		select {
+		case <-reloaded:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "Reload callback wasn't run on SIGHUP")
+		}
+	})
+}
+
+func TestReload(t *testing.T) {
+	t.Run("RunsAllCallbacksAndReturnsTheirErrors", func(t *testing.T) {
+		resetReloadFuncs(t)
+		calls := 0
+		OnReload(func() error { calls++; return errors.New("Config invalid") })
+		OnReload(func() error { calls++; return nil })
+
+		// act
+		err := Reload()
+
+		assert.Equal(t, 2, calls)
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "Reload callback 0 failed: Config invalid")
+		}
+	})
+}
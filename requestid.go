@@ -0,0 +1,52 @@
+package foundation
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+)
+
+// RequestIDHeader is the header used to read and propagate the request id
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of the context containing the request id
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id stored in the context or an empty string if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDContextKey{}).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// RequestID returns a middleware that reads the X-Request-ID header or generates a new id if it's missing; the id is
+// stored in the request context, set on the response, tagged on the active span and added to the logger retrieved
+// from the context with LoggerFromContext(r.Context())
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+
+			if span := opentracing.SpanFromContext(r.Context()); span != nil {
+				span.SetTag("request.id", requestID)
+			}
+
+			ctx := ContextWithRequestID(r.Context(), requestID)
+			ctx = ContextWithFields(ctx, "requestId", requestID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package foundation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+
+	t.Run("PropagatesIncomingRequestID", func(t *testing.T) {
+
+		var requestIDInContext string
+		handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestIDInContext = RequestIDFromContext(r.Context())
+		}))
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-Request-ID", "abc-123")
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "abc-123", requestIDInContext)
+		assert.Equal(t, "abc-123", recorder.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("GeneratesRequestIDIfMissing", func(t *testing.T) {
+
+		handler := RequestID()(http.NotFoundHandler())
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Len(t, recorder.Header().Get("X-Request-ID"), 36)
+	})
+
+	t.Run("AddsRequestIDToContextLogger", func(t *testing.T) {
+
+		buffer := captureLogs(t)
+		handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Ctx(r.Context()).Info().Msg("Handling request")
+		}))
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-Request-ID", "abc-123")
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "abc-123", event["requestId"])
+		}
+	})
+}
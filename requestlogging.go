@@ -0,0 +1,113 @@
+package foundation
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestLoggingConfig is used to configure the RequestLogging middleware
+type RequestLoggingConfig struct {
+	ExcludedPaths    []string
+	ProbePaths       []string
+	ProbeSampleEvery uint32
+}
+
+// RequestLoggingOption allows to override config
+type RequestLoggingOption func(*RequestLoggingConfig)
+
+// WithExcludedPaths sets the paths for which no requests are logged
+// default is /liveness, /readiness, /metrics and /info
+func WithExcludedPaths(paths ...string) RequestLoggingOption {
+	return func(c *RequestLoggingConfig) {
+		c.ExcludedPaths = paths
+	}
+}
+
+// WithProbePaths sets the paths of probe requests sampled by WithProbeLogging
+// default is /liveness, /readiness, /startup, /livez, /readyz and /healthz
+func WithProbePaths(paths ...string) RequestLoggingOption {
+	return func(c *RequestLoggingConfig) {
+		c.ProbePaths = paths
+	}
+}
+
+// WithProbeLogging logs one in every sampleEvery probe requests at trace level, even if their paths are excluded, so the
+// probes of kubelet every few seconds don't drown out other logs
+// default is 0, which leaves probe requests to WithExcludedPaths
+func WithProbeLogging(sampleEvery uint32) RequestLoggingOption {
+	return func(c *RequestLoggingConfig) {
+		c.ProbeSampleEvery = sampleEvery
+	}
+}
+
+// RequestLogging returns a middleware that logs a single structured event per request using the configured log format
+func RequestLogging(opts ...RequestLoggingOption) Middleware {
+
+	// default
+	config := &RequestLoggingConfig{
+		ExcludedPaths: []string{"/liveness", "/readiness", "/metrics", "/info"},
+		ProbePaths:    []string{"/liveness", "/readiness", "/startup", "/livez", "/readyz", "/healthz"},
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var probeRequests uint32
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isProbe := config.ProbeSampleEvery > 0 && StringArrayContains(config.ProbePaths, r.URL.Path)
+			if isProbe && (atomic.AddUint32(&probeRequests, 1)-1)%config.ProbeSampleEvery != 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !isProbe && StringArrayContains(config.ExcludedPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rw := newResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			var event *zerolog.Event
+			switch {
+			case isProbe:
+				event = log.Trace()
+			case rw.status >= 500:
+				event = log.Error()
+			case rw.status >= 400:
+				event = log.Warn()
+			default:
+				event = log.Info()
+			}
+
+			if requestID := rw.Header().Get(RequestIDHeader); requestID != "" {
+				event.Str("requestId", requestID)
+			}
+			if correlationID := r.Header.Get("X-Correlation-ID"); correlationID != "" {
+				event.Str("correlationId", correlationID)
+			}
+			if traceID := traceIDFromRequest(r); traceID != "" {
+				event.Str("traceId", traceID)
+			}
+
+			event.
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", rw.status).
+				Int64("bytes", rw.bytes).
+				Dur("duration", time.Since(start)).
+				Str("remoteIp", clientIP(r)).
+				Msgf("%v %v %v", r.Method, r.URL.Path, rw.status)
+		})
+	}
+}
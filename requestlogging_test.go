@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogging(t *testing.T) {
+
+	t.Run("LogsSingleEventPerRequest", func(t *testing.T) {
+
+		buffer := captureLogs(t)
+		handler := RequestLogging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, "created")
+		}))
+		request := httptest.NewRequest("POST", "/api/items", nil)
+		request.Header.Set("X-Correlation-ID", "abc")
+		request.RemoteAddr = "10.0.0.1:53412"
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "info", event["level"])
+			assert.Equal(t, "POST", event["method"])
+			assert.Equal(t, "/api/items", event["path"])
+			assert.Equal(t, float64(201), event["status"])
+			assert.Equal(t, float64(7), event["bytes"])
+			assert.Equal(t, "abc", event["correlationId"])
+			assert.Equal(t, "10.0.0.1", event["remoteIp"])
+			assert.Contains(t, event, "duration")
+		}
+	})
+
+	t.Run("DoesNotLogExcludedPaths", func(t *testing.T) {
+
+		buffer := captureLogs(t)
+		handler := RequestLogging()(http.NotFoundHandler())
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/readiness", nil))
+
+		assert.Equal(t, 0, buffer.Len())
+	})
+
+	t.Run("LogsServerErrorsAtErrorLevel", func(t *testing.T) {
+
+		buffer := captureLogs(t)
+		handler := RequestLogging(WithExcludedPaths())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/readiness", nil))
+
+		var event map[string]interface{}
+		err := json.Unmarshal(buffer.Bytes(), &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "error", event["level"])
+		}
+	})
+
+	t.Run("LogsSampleOfProbeRequestsAtTraceLevel", func(t *testing.T) {
+
+		defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+		buffer := captureLogs(t)
+		handler := RequestLogging(WithProbeLogging(3))(http.NotFoundHandler())
+
+		// act
+		for i := 0; i < 6; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/liveness", nil))
+		}
+
+		lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+		assert.Equal(t, 2, len(lines))
+		var event map[string]interface{}
+		err := json.Unmarshal(lines[0], &event)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "trace", event["level"])
+			assert.Equal(t, "/liveness", event["path"])
+		}
+	})
+}
+
+// captureLogs replaces the global logger with one writing json to the returned buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+
+	originalLogger := log.Logger
+	log.Logger = zerolog.New(buffer)
+	t.Cleanup(func() {
+		log.Logger = originalLogger
+	})
+
+	return buffer
+}
@@ -0,0 +1,180 @@
+package foundation
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// cgroupRoot is the path where the cgroup filesystem is mounted
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// ResourceInfo contains the resources available to the application and the go runtime settings based on them
+type ResourceInfo struct {
+	// CPUQuota is the number of cpus the container is allowed to use as set by the cgroup, 0 if unlimited
+	CPUQuota float64 `json:"cpuQuota"`
+	// MemoryLimit is the number of bytes the container is allowed to use as set by the cgroup, 0 if unlimited
+	MemoryLimit int64 `json:"memoryLimit"`
+	// NumCPU is the number of cpus of the host
+	NumCPU int `json:"numCpu"`
+	// GOMAXPROCS is the maximum number of cpus executing go code simultaneously
+	GOMAXPROCS int `json:"gomaxprocs"`
+	// GOGC is the garbage collection target percentage
+	GOGC string `json:"gogc"`
+	// GOMEMLIMIT is the soft memory limit of the go runtime
+	GOMEMLIMIT string `json:"gomemlimit"`
+}
+
+// GetResourceInfo detects the cgroup cpu and memory limits and the go runtime settings
+func GetResourceInfo() ResourceInfo {
+	resourceInfo := ResourceInfo{
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GOGC:       os.Getenv("GOGC"),
+		GOMEMLIMIT: "off",
+	}
+
+	if resourceInfo.GOGC == "" {
+		resourceInfo.GOGC = "100"
+	}
+	// a negative input only reads the current limit
+	if memoryLimit := debug.SetMemoryLimit(-1); memoryLimit != math.MaxInt64 {
+		resourceInfo.GOMEMLIMIT = strconv.FormatInt(memoryLimit, 10)
+	}
+	if cpuQuota, ok := readCgroupCPUQuota(); ok {
+		resourceInfo.CPUQuota = cpuQuota
+	}
+	if memoryLimit, ok := readCgroupMemoryLimit(); ok {
+		resourceInfo.MemoryLimit = memoryLimit
+	}
+
+	return resourceInfo
+}
+
+// TuneRuntimeFromCgroup sets GOMAXPROCS to the cgroup cpu quota rounded down and GOMEMLIMIT to a ratio of the cgroup
+// memory limit, so the go runtime doesn't get throttled or killed in containers with small limits; GOMAXPROCS and
+// GOMEMLIMIT envvars take precedence, ESTAFETTE_GOMEMLIMIT_RATIO overrides the default ratio of 0.9 and
+// ESTAFETTE_RUNTIME_TUNING=false disables tuning altogether
+func TuneRuntimeFromCgroup() {
+	if strings.ToLower(os.Getenv("ESTAFETTE_RUNTIME_TUNING")) == "false" {
+		return
+	}
+
+	if os.Getenv("GOMAXPROCS") == "" {
+		if cpuQuota, ok := readCgroupCPUQuota(); ok {
+			maxProcs := int(math.Max(1, math.Floor(cpuQuota)))
+			if maxProcs < runtime.GOMAXPROCS(0) {
+				previous := runtime.GOMAXPROCS(maxProcs)
+				log.Info().
+					Float64("cpuQuota", cpuQuota).
+					Int("gomaxprocs", maxProcs).
+					Msgf("Changed GOMAXPROCS from %v to %v to match cpu quota %v", previous, maxProcs, cpuQuota)
+			}
+		}
+	}
+
+	if os.Getenv("GOMEMLIMIT") == "" {
+		if memoryLimit, ok := readCgroupMemoryLimit(); ok {
+			ratio := 0.9
+			if ratioString := os.Getenv("ESTAFETTE_GOMEMLIMIT_RATIO"); ratioString != "" {
+				parsedRatio, err := strconv.ParseFloat(ratioString, 64)
+				if err != nil || parsedRatio <= 0 || parsedRatio > 1 {
+					log.Warn().Str("ratio", ratioString).Msg("ESTAFETTE_GOMEMLIMIT_RATIO should be a number larger than 0 and at most 1, using 0.9 instead")
+				} else {
+					ratio = parsedRatio
+				}
+			}
+
+			goMemLimit := int64(float64(memoryLimit) * ratio)
+			debug.SetMemoryLimit(goMemLimit)
+			log.Info().
+				Int64("memoryLimit", memoryLimit).
+				Int64("gomemlimit", goMemLimit).
+				Msgf("Set GOMEMLIMIT to %v bytes, %v of memory limit %v bytes", goMemLimit, ratio, memoryLimit)
+		}
+	}
+}
+
+// readCgroupCPUQuota returns the cpu quota in number of cpus from cgroup v2 cpu.max or cgroup v1 cpu.cfs_quota_us and cpu.cfs_period_us
+func readCgroupCPUQuota() (cpus float64, ok bool) {
+	// cgroup v2
+	if content, err := readCgroupFile("cpu.max"); err == nil {
+		fields := strings.Fields(content)
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return cpuQuotaFromQuotaAndPeriod(fields[0], fields[1])
+	}
+
+	// cgroup v1
+	quota, err := readCgroupFile(filepath.Join("cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := readCgroupFile(filepath.Join("cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+
+	return cpuQuotaFromQuotaAndPeriod(quota, period)
+}
+
+func cpuQuotaFromQuotaAndPeriod(quotaString, periodString string) (cpus float64, ok bool) {
+	quota, err := strconv.ParseFloat(quotaString, 64)
+	if err != nil || quota <= 0 {
+		return 0, false
+	}
+	period, err := strconv.ParseFloat(periodString, 64)
+	if err != nil || period <= 0 {
+		return 0, false
+	}
+
+	return quota / period, true
+}
+
+// readCgroupMemoryLimit returns the memory limit in bytes from cgroup v2 memory.max or cgroup v1 memory.limit_in_bytes
+func readCgroupMemoryLimit() (bytes int64, ok bool) {
+	// cgroup v2
+	content, err := readCgroupFile("memory.max")
+	if err != nil {
+		// cgroup v1
+		content, err = readCgroupFile(filepath.Join("memory", "memory.limit_in_bytes"))
+		if err != nil {
+			return 0, false
+		}
+	}
+
+	if content == "max" {
+		return 0, false
+	}
+
+	limit, err := strconv.ParseInt(content, 10, 64)
+	if err != nil || limit <= 0 {
+		return 0, false
+	}
+
+	// cgroup v1 reports a value close to max int64 when no limit is set
+	if limit >= 1<<62 {
+		return 0, false
+	}
+
+	return limit, true
+}
+
+func readCgroupFile(name string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(cgroupRoot, name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
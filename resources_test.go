@@ -0,0 +1,183 @@
+package foundation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResourceInfo(t *testing.T) {
+
+	t.Run("ReturnsLimitsFromCgroupV2", func(t *testing.T) {
+
+		setCgroupFiles(t, map[string]string{
+			"cpu.max":    "150000 100000\n",
+			"memory.max": "536870912\n",
+		})
+
+		// act
+		resourceInfo := GetResourceInfo()
+
+		assert.Equal(t, 1.5, resourceInfo.CPUQuota)
+		assert.Equal(t, int64(536870912), resourceInfo.MemoryLimit)
+	})
+
+	t.Run("ReturnsNoLimitsFromCgroupV2IfUnlimited", func(t *testing.T) {
+
+		setCgroupFiles(t, map[string]string{
+			"cpu.max":    "max 100000\n",
+			"memory.max": "max\n",
+		})
+
+		// act
+		resourceInfo := GetResourceInfo()
+
+		assert.Equal(t, 0.0, resourceInfo.CPUQuota)
+		assert.Equal(t, int64(0), resourceInfo.MemoryLimit)
+	})
+
+	t.Run("ReturnsLimitsFromCgroupV1", func(t *testing.T) {
+
+		setCgroupFiles(t, map[string]string{
+			"cpu/cpu.cfs_quota_us":         "50000\n",
+			"cpu/cpu.cfs_period_us":        "100000\n",
+			"memory/memory.limit_in_bytes": "268435456\n",
+		})
+
+		// act
+		resourceInfo := GetResourceInfo()
+
+		assert.Equal(t, 0.5, resourceInfo.CPUQuota)
+		assert.Equal(t, int64(268435456), resourceInfo.MemoryLimit)
+	})
+
+	t.Run("ReturnsNoLimitsFromCgroupV1IfUnlimited", func(t *testing.T) {
+
+		setCgroupFiles(t, map[string]string{
+			"cpu/cpu.cfs_quota_us":         "-1\n",
+			"cpu/cpu.cfs_period_us":        "100000\n",
+			"memory/memory.limit_in_bytes": "9223372036854771712\n",
+		})
+
+		// act
+		resourceInfo := GetResourceInfo()
+
+		assert.Equal(t, 0.0, resourceInfo.CPUQuota)
+		assert.Equal(t, int64(0), resourceInfo.MemoryLimit)
+	})
+
+	t.Run("ReturnsGoRuntimeSettings", func(t *testing.T) {
+
+		setCgroupFiles(t, map[string]string{})
+		t.Setenv("GOGC", "50")
+		t.Setenv("GOMEMLIMIT", "")
+
+		// act
+		resourceInfo := GetResourceInfo()
+
+		assert.Equal(t, "50", resourceInfo.GOGC)
+		assert.Equal(t, "off", resourceInfo.GOMEMLIMIT)
+		assert.True(t, resourceInfo.GOMAXPROCS > 0)
+	})
+}
+
+// setCgroupFiles points cgroupRoot to a temporary directory containing the specified files for the duration of the test
+func setCgroupFiles(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalCgroupRoot := cgroupRoot
+	cgroupRoot = dir
+	t.Cleanup(func() {
+		cgroupRoot = originalCgroupRoot
+	})
+}
+
+func TestTuneRuntimeFromCgroup(t *testing.T) {
+
+	restoreRuntimeSettings := func(t *testing.T) {
+		maxProcs := runtime.GOMAXPROCS(0)
+		memoryLimit := debug.SetMemoryLimit(-1)
+		t.Cleanup(func() {
+			runtime.GOMAXPROCS(maxProcs)
+			debug.SetMemoryLimit(memoryLimit)
+		})
+	}
+
+	t.Run("SetsGOMAXPROCSAndGOMEMLIMITFromCgroup", func(t *testing.T) {
+
+		restoreRuntimeSettings(t)
+		t.Setenv("GOMAXPROCS", "")
+		t.Setenv("GOMEMLIMIT", "")
+		runtime.GOMAXPROCS(4)
+		setCgroupFiles(t, map[string]string{
+			"cpu.max":    "150000 100000\n",
+			"memory.max": "1000000000\n",
+		})
+
+		// act
+		TuneRuntimeFromCgroup()
+
+		assert.Equal(t, 1, runtime.GOMAXPROCS(0))
+		assert.Equal(t, int64(900000000), debug.SetMemoryLimit(-1))
+	})
+
+	t.Run("AppliesGOMEMLIMITRatioFromEnv", func(t *testing.T) {
+
+		restoreRuntimeSettings(t)
+		t.Setenv("GOMEMLIMIT", "")
+		t.Setenv("ESTAFETTE_GOMEMLIMIT_RATIO", "0.5")
+		setCgroupFiles(t, map[string]string{
+			"memory.max": "1000000000\n",
+		})
+
+		// act
+		TuneRuntimeFromCgroup()
+
+		assert.Equal(t, int64(500000000), debug.SetMemoryLimit(-1))
+	})
+
+	t.Run("RespectsGOMAXPROCSEnvvar", func(t *testing.T) {
+
+		restoreRuntimeSettings(t)
+		t.Setenv("GOMAXPROCS", "4")
+		runtime.GOMAXPROCS(4)
+		setCgroupFiles(t, map[string]string{
+			"cpu.max": "100000 100000\n",
+		})
+
+		// act
+		TuneRuntimeFromCgroup()
+
+		assert.Equal(t, 4, runtime.GOMAXPROCS(0))
+	})
+
+	t.Run("DoesNothingIfDisabled", func(t *testing.T) {
+
+		restoreRuntimeSettings(t)
+		t.Setenv("ESTAFETTE_RUNTIME_TUNING", "false")
+		t.Setenv("GOMAXPROCS", "")
+		runtime.GOMAXPROCS(4)
+		setCgroupFiles(t, map[string]string{
+			"cpu.max": "100000 100000\n",
+		})
+
+		// act
+		TuneRuntimeFromCgroup()
+
+		assert.Equal(t, 4, runtime.GOMAXPROCS(0))
+	})
+}
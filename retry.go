@@ -1,6 +1,8 @@
 package foundation
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -22,6 +24,30 @@ func (e RetryError) Error() string {
 	return fmt.Sprintf("All attempts fail:\n%s", strings.Join(logWithNumber, "\n"))
 }
 
+// Unwrap returns the errors of all failed attempts for errors.Is and errors.As
+func (e RetryError) Unwrap() []error {
+	return MultiError(e.nonNil()).Unwrap()
+}
+
+// Is returns true if the error of any failed attempt matches the target
+func (e RetryError) Is(target error) bool {
+	return MultiError(e.nonNil()).Is(target)
+}
+
+// As finds the first error of a failed attempt that matches the target
+func (e RetryError) As(target interface{}) bool {
+	return MultiError(e.nonNil()).As(target)
+}
+
+func (e RetryError) nonNil() (errs []error) {
+	for _, err := range e {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return
+}
+
 // RetryOption allows to override config
 type RetryOption func(*RetryConfig)
 
@@ -76,6 +102,32 @@ func AnyError() RetryOption {
 	}
 }
 
+// Name sets the name of the operation that is retried, used as name label of the retry metrics
+// default is empty
+func Name(name string) RetryOption {
+	return func(c *RetryConfig) {
+		c.Name = name
+	}
+}
+
+// Report sends the error of the last attempt to the error reporter set with SetErrorReporter once all attempts have
+// failed, unless the context has been cancelled or its deadline exceeded
+// default is not reporting
+func Report() RetryOption {
+	return func(c *RetryConfig) {
+		c.Report = true
+	}
+}
+
+// Context sets the context the retries belong to; once it's done no more attempts are made, also not while waiting
+// between attempts, and the trace of its span is added to the report of exhausted retries with Report
+// default is context.Background()
+func Context(ctx context.Context) RetryOption {
+	return func(c *RetryConfig) {
+		c.Context = ctx
+	}
+}
+
 // DelayTypeFunc allows to override the DelayType
 type DelayTypeFunc func(n uint, config *RetryConfig) time.Duration
 
@@ -111,10 +163,13 @@ type RetryConfig struct {
 	DelayType        DelayTypeFunc
 	LastErrorOnly    bool
 	IsRetryableError IsRetryableErrorFunc
+	Name             string
+	Context          context.Context
+	Report           bool
 }
 
 // Retry retries a function
-func Retry(retryableFunc func() error, opts ...RetryOption) error {
+func Retry(retryableFunc func() error, opts ...RetryOption) (err error) {
 	var n uint
 
 	//default
@@ -124,6 +179,7 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 		DelayType:        ExponentialJitterBackoffDelay,
 		LastErrorOnly:    false,
 		IsRetryableError: AnyErrorIsRetryable,
+		Context:          context.Background(),
 	}
 
 	// apply options to override config defaults
@@ -138,15 +194,26 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 		errorLog = make(RetryError, 1)
 	}
 
+	var attempts uint
+	var totalDelay time.Duration
+	defer func() {
+		observeRetry(config.Name, attempts, totalDelay, err)
+	}()
+
 	lastErrIndex := n
 	for n < config.Attempts {
 		err := retryableFunc()
+		attempts++
 
 		if err != nil {
 			errorLog[lastErrIndex] = unpackUnrecoverable(err)
 
 			// if this is last attempt - don't wait
 			if n == config.Attempts-1 {
+				// the caller giving up isn't worth an error report
+				if lastErr := errorLog[lastErrIndex]; config.Report && !errors.Is(lastErr, context.Canceled) && !errors.Is(lastErr, context.DeadlineExceeded) {
+					reportError(config.Context, err, false, 2, map[string]string{"retry.attempts": fmt.Sprint(config.Attempts)})
+				}
 				break
 			}
 
@@ -156,7 +223,15 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 			}
 
 			delayTime := config.DelayType(n, config)
-			time.Sleep(delayTime)
+			start := currentClock().Now()
+			select {
+			case <-currentClock().After(delayTime):
+				totalDelay += delayTime
+			case <-config.Context.Done():
+				// stop waiting for the next attempt once the caller has given up
+				totalDelay += currentClock().Since(start)
+				return retryResult(config, errorLog, lastErrIndex)
+			}
 		} else {
 			return nil
 		}
@@ -167,6 +242,11 @@ func Retry(retryableFunc func() error, opts ...RetryOption) error {
 		}
 	}
 
+	return retryResult(config, errorLog, lastErrIndex)
+}
+
+// retryResult returns the error of the last attempt if LastErrorOnly is set, or the errors of all attempts otherwise
+func retryResult(config *RetryConfig, errorLog RetryError, lastErrIndex uint) error {
 	if config.LastErrorOnly {
 		return errorLog[lastErrIndex]
 	}
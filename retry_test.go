@@ -1,10 +1,13 @@
 package foundation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -125,4 +128,62 @@ func TestRetry(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.Equal(t, 1, attempts)
 	})
+	t.Run("StopsWaitingForNextAttemptOnceContextIsDone", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1600000000, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		attempts := 0
+		retryableFunc := func() error {
+			attempts++
+			return ErrToRetry
+		}
+
+		// act
+		done := make(chan error)
+		go func() {
+			done <- Retry(retryableFunc, Attempts(5), DelayMillisecond(10), Fixed(), LastErrorOnly(true), Context(ctx))
+		}()
+		clock.BlockUntil(1)
+		cancel()
+		err := <-done
+
+		assert.Equal(t, ErrToRetry, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("RecordsMetricsLabeledWithName", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1600000000, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		attemptsBefore := testutil.ToFloat64(retryAttemptsTotal.WithLabelValues("recordsmetrics"))
+		succeededBefore := testutil.ToFloat64(retryOutcomesTotal.WithLabelValues("recordsmetrics", "succeeded"))
+		failedBefore := testutil.ToFloat64(retryOutcomesTotal.WithLabelValues("recordsmetrics", "failed"))
+
+		attempts := 0
+		retryableFunc := func() error {
+			attempts++
+			if attempts < 3 {
+				return ErrToRetry
+			}
+			return nil
+		}
+
+		// act
+		done := make(chan error)
+		go func() {
+			done <- Retry(retryableFunc, Attempts(5), DelayMillisecond(10), Fixed(), Name("recordsmetrics"))
+		}()
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(10 * time.Millisecond)
+		}
+		err := <-done
+
+		assert.Nil(t, err)
+		assert.Equal(t, float64(3), testutil.ToFloat64(retryAttemptsTotal.WithLabelValues("recordsmetrics"))-attemptsBefore)
+		assert.Equal(t, float64(1), testutil.ToFloat64(retryOutcomesTotal.WithLabelValues("recordsmetrics", "succeeded"))-succeededBefore)
+		assert.Equal(t, float64(0), testutil.ToFloat64(retryOutcomesTotal.WithLabelValues("recordsmetrics", "failed"))-failedBefore)
+	})
 }
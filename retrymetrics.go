@@ -0,0 +1,44 @@
+package foundation
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	retryAttemptsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "retry_attempts_total",
+		Help: "Total number of attempts made by Retry.",
+	}, []string{"name"})
+
+	retryOutcomesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "retry_outcomes_total",
+		Help: "Total number of Retry calls by whether the retryable function eventually succeeded or failed.",
+	}, []string{"name", "outcome"})
+
+	retryDelaySeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "retry_delay_seconds",
+		Help:    "Total time Retry waited between attempts per call.",
+		Buckets: []float64{0, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"name"})
+)
+
+// registerRetryMetrics exposes retry_attempts_total, retry_outcomes_total and retry_delay_seconds
+func registerRetryMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, retryAttemptsTotal)
+	registerCollector(registerer, retryOutcomesTotal)
+	registerCollector(registerer, retryDelaySeconds)
+}
+
+// observeRetry records the attempts, outcome and total delay of a Retry call, labeled with the name set with Name
+func observeRetry(name string, attempts uint, delay time.Duration, err error) {
+	outcome := "succeeded"
+	if err != nil {
+		outcome = "failed"
+	}
+
+	retryAttemptsTotal.WithLabelValues(name).Add(float64(attempts))
+	retryOutcomesTotal.WithLabelValues(name, outcome).Inc()
+	retryDelaySeconds.WithLabelValues(name).Observe(delay.Seconds())
+}
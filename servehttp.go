@@ -0,0 +1,133 @@
+package foundation
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ServeHTTPConfig is used to configure ServeHTTP
+type ServeHTTPConfig struct {
+	DrainTimeout    time.Duration
+	ShutdownTimeout time.Duration
+	WaitGroup       *sync.WaitGroup
+}
+
+// ServeHTTPOption allows to override config
+type ServeHTTPOption func(*ServeHTTPConfig)
+
+// WithServeHTTPDrainTimeout sets the maximum duration to wait for in-flight requests to finish while still accepting new
+// connections, to give load balancers time to stop sending traffic; this is part of the shutdown timeout
+// default is 5s
+func WithServeHTTPDrainTimeout(timeout time.Duration) ServeHTTPOption {
+	return func(c *ServeHTTPConfig) {
+		c.DrainTimeout = timeout
+	}
+}
+
+// WithServeHTTPShutdownTimeout sets the maximum duration to wait for in-flight requests to finish when shutting down,
+// after which remaining connections are closed forcefully
+// default is 20s
+func WithServeHTTPShutdownTimeout(timeout time.Duration) ServeHTTPOption {
+	return func(c *ServeHTTPConfig) {
+		c.ShutdownTimeout = timeout
+	}
+}
+
+// WithServeHTTPWaitGroup registers the server and each in-flight request with the waitgroup returned by
+// InitGracefulShutdownHandling, so HandleGracefulShutdown waits for the server to be shut down
+func WithServeHTTPWaitGroup(waitGroup *sync.WaitGroup) ServeHTTPOption {
+	return func(c *ServeHTTPConfig) {
+		c.WaitGroup = waitGroup
+	}
+}
+
+// ServeHTTP binds and serves server until ctx is done, then marks the application as not ready if a readiness endpoint
+// is served and stops the server the way HTTPServer.Stop does; it returns errors binding or serving and an error if
+// requests didn't finish within the shutdown timeout
+// go func() { errs <- foundation.ServeHTTP(ctx, server, foundation.WithServeHTTPWaitGroup(waitGroup)) }()
+// foundation.HandleGracefulShutdown(gracefulShutdown, waitGroup, cancel)
+func ServeHTTP(ctx context.Context, server *http.Server, opts ...ServeHTTPOption) error {
+	// default
+	config := ServeHTTPConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 20 * time.Second,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.WaitGroup != nil {
+		config.WaitGroup.Add(1)
+		defer config.WaitGroup.Done()
+	}
+
+	listener, err := listen(server, "")
+	if err != nil {
+		return err
+	}
+
+	s := wrapHTTPServer(server, HTTPServerConfig{
+		DrainTimeout:    config.DrainTimeout,
+		ShutdownTimeout: config.ShutdownTimeout,
+		WaitGroup:       config.WaitGroup,
+	})
+
+	log.Debug().
+		Str("port", server.Addr).
+		Msg("Serving http requests...")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(server, listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	if atomic.LoadInt32(&readinessServed) == 1 {
+		SetReady(false)
+	}
+
+	if err := s.Stop(context.Background()); err != nil {
+		return err
+	}
+
+	return <-serveErr
+}
+
+// wrapHTTPServer tracks in-flight requests and connections of server, so it can be stopped like an HTTPServer
+func wrapHTTPServer(server *http.Server, config HTTPServerConfig) *HTTPServer {
+	s := &HTTPServer{
+		Server:      server,
+		config:      config,
+		stopped:     make(chan struct{}),
+		connections: map[net.Conn]http.ConnState{},
+	}
+
+	handler := server.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	server.Handler = s.trackInFlight(handler)
+
+	connState := server.ConnState
+	server.ConnState = func(conn net.Conn, state http.ConnState) {
+		s.trackConnection(conn, state)
+		if connState != nil {
+			connState(conn, state)
+		}
+	}
+
+	return s
+}
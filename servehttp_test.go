@@ -0,0 +1,151 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeHTTP(t *testing.T) {
+	t.Run("WaitsForInFlightRequestsOnceContextIsDone", func(t *testing.T) {
+		defer SetReady(true)
+		port := freeTestPort(t)
+		requestStarted := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(w, "done")
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- ServeHTTP(ctx, &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: handler, ReadHeaderTimeout: time.Second})
+		}()
+		var body []byte
+		responded := make(chan struct{})
+		go func() {
+			defer close(responded)
+			assert.Eventually(t, func() bool {
+				response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/", port))
+				if err != nil {
+					return false
+				}
+				defer response.Body.Close()
+				body, _ = io.ReadAll(response.Body)
+				return true
+			}, time.Second, 10*time.Millisecond)
+		}()
+		<-requestStarted
+
+		// act
+		cancel()
+
+		assert.Nil(t, <-done)
+		<-responded
+		assert.Equal(t, "done", string(body))
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if assert.Nil(t, err) {
+			listener.Close()
+		}
+	})
+
+	t.Run("ReturnsBindError", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+
+		// act
+		err = ServeHTTP(context.Background(), &http.Server{Addr: listener.Addr().String(), ReadHeaderTimeout: time.Second})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfRequestsExceedShutdownTimeout", func(t *testing.T) {
+		defer SetReady(true)
+		port := freeTestPort(t)
+		requestStarted := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			<-release
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- ServeHTTP(ctx, &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: handler, ReadHeaderTimeout: time.Second}, WithServeHTTPShutdownTimeout(50*time.Millisecond))
+		}()
+		go func() {
+			// retry until the server is bound; the request itself is cut off on shutdown
+			for {
+				select {
+				case <-requestStarted:
+					return
+				default:
+				}
+				if response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/", port)); err == nil {
+					response.Body.Close()
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		<-requestStarted
+
+		// act
+		cancel()
+
+		err := <-done
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "Force closed 1 connections")
+		}
+	})
+	t.Run("KeepsAcceptingConnectionsWhileDraining", func(t *testing.T) {
+		defer SetReady(true)
+		port := freeTestPort(t)
+		requestStarted := make(chan struct{}, 1)
+		release := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				requestStarted <- struct{}{}
+				<-release
+			}
+			io.WriteString(w, "done")
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		waitGroup := &sync.WaitGroup{}
+		done := make(chan error)
+		go func() {
+			done <- ServeHTTP(ctx, &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: handler, ReadHeaderTimeout: time.Second}, WithServeHTTPWaitGroup(waitGroup))
+		}()
+		go func() {
+			for {
+				if response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/slow", port)); err == nil {
+					response.Body.Close()
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		<-requestStarted
+
+		// act
+		cancel()
+
+		time.Sleep(50 * time.Millisecond)
+		response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%v/", port))
+		if assert.Nil(t, err) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+		close(release)
+		waitGroup.Wait()
+		assert.Nil(t, <-done)
+	})
+}
@@ -0,0 +1,144 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ServerGroup runs the probe, metrics and admin servers like an errgroup: the first error binding or serving any of
+// them is returned by Wait and cancels the group's context, instead of exiting the application with log.Fatal from a
+// goroutine
+type ServerGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	waitGroup sync.WaitGroup
+	mutex     sync.Mutex
+	servers   []*http.Server
+	err       error
+}
+
+// NewServerGroup returns a ServerGroup and a context that's cancelled when any of its servers fails or ctx is done;
+// once the context is cancelled all servers in the group are shut down
+// group, ctx := foundation.NewServerGroup(ctx)
+// foundation.InitLivenessAndReadiness(foundation.WithProbeServerGroup(group))
+// foundation.InitMetrics(foundation.WithMetricsServerGroup(group))
+func NewServerGroup(ctx context.Context) (*ServerGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	group := &ServerGroup{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	go func() {
+		<-ctx.Done()
+		group.shutdown()
+	}()
+
+	return group, ctx
+}
+
+// Serve binds the address of server and serves it in the background as part of the group; a bind error is returned
+// and fails the group as well
+func (g *ServerGroup) Serve(name string, server *http.Server) error {
+	listener, err := listen(server, "")
+	if err != nil {
+		g.fail(name, err)
+		return err
+	}
+
+	g.serve(server, listener, func(err error) {
+		g.fail(name, err)
+	})
+
+	return nil
+}
+
+// Wait blocks until all servers in the group have stopped and returns the first error of any of them
+func (g *ServerGroup) Wait() error {
+	<-g.ctx.Done()
+	g.waitGroup.Wait()
+
+	return g.Err()
+}
+
+// Err returns the first error of any server in the group, or nil while none failed
+func (g *ServerGroup) Err() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.err
+}
+
+// Shutdown cancels the group's context, shutting down all its servers
+func (g *ServerGroup) Shutdown() {
+	g.cancel()
+}
+
+// ShutdownOnError sends SIGTERM to gracefulShutdown once any server in the group fails, so HandleGracefulShutdown
+// stops the application cleanly
+func (g *ServerGroup) ShutdownOnError(gracefulShutdown chan os.Signal) {
+	go func() {
+		<-g.ctx.Done()
+		if err := g.Err(); err != nil {
+			log.Error().Err(err).Msg("Server failed, shutting down gracefully...")
+			select {
+			case gracefulShutdown <- syscall.SIGTERM:
+			default:
+			}
+		}
+	}()
+}
+
+// serve serves server on listener in the background, tracked by the group; if the group has been cancelled already the
+// server is closed right away
+func (g *ServerGroup) serve(server *http.Server, listener net.Listener, onError func(err error)) {
+	g.mutex.Lock()
+	if g.ctx.Err() != nil {
+		g.mutex.Unlock()
+		listener.Close()
+		return
+	}
+	g.servers = append(g.servers, server)
+	g.waitGroup.Add(1)
+	g.mutex.Unlock()
+
+	go func() {
+		defer g.waitGroup.Done()
+		if err := serve(server, listener); err != nil {
+			onError(err)
+		}
+	}()
+}
+
+func (g *ServerGroup) fail(name string, err error) {
+	g.mutex.Lock()
+	if g.err == nil {
+		g.err = fmt.Errorf("Serving %v failed: %w", name, err)
+	}
+	g.mutex.Unlock()
+
+	g.cancel()
+}
+
+func (g *ServerGroup) shutdown() {
+	g.mutex.Lock()
+	servers := append([]*http.Server{}, g.servers...)
+	g.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, server := range servers {
+		server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,76 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerGroup(t *testing.T) {
+	t.Run("ReturnsBindErrorFromWaitAndShutsDownOtherServers", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		group, ctx := NewServerGroup(context.Background())
+		metricsPort := freeTestPort(t)
+		InitMetricsWithPort(metricsPort, WithMetricsServerGroup(group))
+
+		// act
+		InitLivenessAndReadinessWithPort(listener.Addr().(*net.TCPAddr).Port, WithProbeServerGroup(group))
+
+		err = group.Wait()
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "/liveness and /readiness failed")
+		}
+		assert.NotNil(t, ctx.Err())
+		metricsListener, err := net.Listen("tcp", fmt.Sprintf(":%v", metricsPort))
+		if assert.Nil(t, err) {
+			metricsListener.Close()
+		}
+	})
+
+	t.Run("ShutsDownServersOnceContextIsCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		group, _ := NewServerGroup(ctx)
+		port := freeTestPort(t)
+		server := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: http.NotFoundHandler(), ReadHeaderTimeout: time.Second}
+		assert.Nil(t, group.Serve("test", server))
+
+		// act
+		cancel()
+
+		assert.Nil(t, group.Wait())
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if assert.Nil(t, err) {
+			listener.Close()
+		}
+	})
+
+	t.Run("TriggersGracefulShutdownOnError", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		group, _ := NewServerGroup(context.Background())
+		gracefulShutdown := make(chan os.Signal, 1)
+		group.ShutdownOnError(gracefulShutdown)
+		server := &http.Server{Addr: listener.Addr().String(), ReadHeaderTimeout: time.Second}
+
+		// act
+		err = group.Serve("test", server)
+
+		assert.NotNil(t, err)
+		select {
+		case signal := <-gracefulShutdown:
+			assert.Equal(t, syscall.SIGTERM, signal)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Graceful shutdown wasn't triggered")
+		}
+	})
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package foundation
+
+import (
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitGracefulShutdownHandling(t *testing.T) {
+	t.Run("ListensToSignalsSetWithOption", func(t *testing.T) {
+		gracefulShutdown, _ := InitGracefulShutdownHandling(WithShutdownSignals(syscall.SIGQUIT))
+		defer signal.Stop(gracefulShutdown)
+
+		// act
+		syscall.Kill(syscall.Getpid(), syscall.SIGQUIT)
+
+		select {
+		case signalReceived := <-gracefulShutdown:
+			assert.Equal(t, syscall.SIGQUIT, signalReceived)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "SIGQUIT wasn't received")
+		}
+	})
+}
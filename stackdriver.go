@@ -0,0 +1,59 @@
+package foundation
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	stackdriverSourceLocationFieldName = "logging.googleapis.com/sourceLocation"
+	stackdriverTraceFieldName          = "logging.googleapis.com/trace"
+	stackdriverSpanIDFieldName         = "logging.googleapis.com/spanId"
+)
+
+// sourceLocationHook adds the file, line and function logging the message, so the gcp console links to the source
+type sourceLocationHook struct{}
+
+func (h sourceLocationHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if frame, ok := applicationCaller(); ok {
+		e.Dict(stackdriverSourceLocationFieldName, zerolog.Dict().
+			Str("file", frame.File).
+			Int("line", frame.Line).
+			Str("function", frame.Function))
+	}
+}
+
+// withStackdriverTrace adds the trace and span id in the fields cloud logging uses to correlate logs with cloud trace;
+// the trace is prefixed with the project from envvar GOOGLE_CLOUD_PROJECT if set, as the gcp console requires
+func withStackdriverTrace(context zerolog.Context, traceID, spanID string) zerolog.Context {
+	traceID = padCloudTraceID(traceID)
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		traceID = cloudTraceName(project, traceID)
+	}
+
+	context = context.Str(stackdriverTraceFieldName, traceID)
+	if spanID != "" {
+		// cloud trace span ids are 16 hex characters
+		if len(spanID) < 16 {
+			spanID = strings.Repeat("0", 16-len(spanID)) + spanID
+		}
+		context = context.Str(stackdriverSpanIDFieldName, spanID)
+	}
+
+	return context
+}
+
+// padCloudTraceID pads the trace id to the 32 hex characters of cloud trace ids, since jaeger leaves out leading zeros
+func padCloudTraceID(traceID string) string {
+	if len(traceID) < 32 {
+		return strings.Repeat("0", 32-len(traceID)) + traceID
+	}
+	return traceID
+}
+
+// cloudTraceName returns the resource name of the trace, which the gcp console requires to link to it
+func cloudTraceName(project, traceID string) string {
+	return "projects/" + project + "/traces/" + padCloudTraceID(traceID)
+}
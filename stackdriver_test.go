@@ -0,0 +1,80 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLoggerStackdriver(t *testing.T) {
+	t.Run("AddsSourceLocationWithStackdriverSourceLocation", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		logger := newLogger(ApplicationInfo{App: "myapp"}, LogFormatStackdriver, newLoggingConfig(WithLogStackdriverSourceLocation()), &buffer)
+
+		// act
+		logger.Info().Msg("Hello")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "info", event["severity"])
+			sourceLocation := event["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+			assert.Regexp(t, `stackdriver_test.go$`, sourceLocation["file"])
+			assert.Equal(t, "github.com/estafette/estafette-foundation.TestNewLoggerStackdriver.func1", sourceLocation["function"])
+		}
+	})
+
+	t.Run("DoesNotAddSourceLocationByDefault", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		t.Setenv("ESTAFETTE_LOG_STACKDRIVER_SOURCE_LOCATION", "")
+		var buffer bytes.Buffer
+		logger := newLogger(ApplicationInfo{App: "myapp"}, LogFormatStackdriver, newLoggingConfig(), &buffer)
+
+		// act
+		logger.Info().Msg("Hello")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "info", event["severity"])
+			assert.NotContains(t, event, "logging.googleapis.com/sourceLocation")
+		}
+	})
+}
+
+func TestWithStackdriverTrace(t *testing.T) {
+	t.Run("AddsPaddedTraceWithProjectAndSpanID", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+		var buffer bytes.Buffer
+
+		// act
+		logger := withStackdriverTrace(zerolog.New(&buffer).With(), "1a2b3c", "00000000000004d2").Logger()
+
+		logger.Info().Msg("Hello")
+		assert.Equal(t, `{"level":"info","logging.googleapis.com/trace":"projects/my-project/traces/000000000000000000000000001a2b3c","logging.googleapis.com/spanId":"00000000000004d2","message":"Hello"}`+"\n", buffer.String())
+	})
+
+	t.Run("AddsTraceIDWithoutProjectIfNotSet", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+		var buffer bytes.Buffer
+
+		// act
+		logger := withStackdriverTrace(zerolog.New(&buffer).With(), "4bf92f3577b34da6a3ce929d0e0e4736", "").Logger()
+
+		logger.Info().Msg("Hello")
+		assert.Equal(t, `{"level":"info","logging.googleapis.com/trace":"4bf92f3577b34da6a3ce929d0e0e4736","message":"Hello"}`+"\n", buffer.String())
+	})
+
+	t.Run("PadsSpanIDWithoutLeadingZeros", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+		var buffer bytes.Buffer
+
+		// act
+		logger := withStackdriverTrace(zerolog.New(&buffer).With(), "4bf92f3577b34da6a3ce929d0e0e4736", "4d2").Logger()
+
+		logger.Info().Msg("Hello")
+		assert.Equal(t, `{"level":"info","logging.googleapis.com/trace":"4bf92f3577b34da6a3ce929d0e0e4736","logging.googleapis.com/spanId":"00000000000004d2","message":"Hello"}`+"\n", buffer.String())
+	})
+}
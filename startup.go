@@ -0,0 +1,33 @@
+package foundation
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+var started int32
+
+// MarkStarted makes the /startup endpoint return 200; until then it returns 503, so a kubernetes startup probe with a
+// long window can cover slow initialization without weakening the liveness probe
+func MarkStarted() {
+	if atomic.CompareAndSwapInt32(&started, 0, 1) {
+		PublishLifecycleEvent(EventStarted, nil)
+	}
+}
+
+// IsStarted returns true once MarkStarted has been called
+func IsStarted() bool {
+	return atomic.LoadInt32(&started) == 1
+}
+
+// startupHandler responds with 200 once the application has been marked as started and with 503 before that
+func startupHandler(w http.ResponseWriter, _ *http.Request) {
+	if !IsStarted() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "I'm starting up!\n")
+		return
+	}
+
+	io.WriteString(w, "I'm started!\n")
+}
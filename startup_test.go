@@ -0,0 +1,31 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkStarted(t *testing.T) {
+	t.Run("MakesStartupReturn200", func(t *testing.T) {
+		atomic.StoreInt32(&started, 0)
+		defer atomic.StoreInt32(&started, 0)
+
+		before := httptest.NewRecorder()
+		startupHandler(before, httptest.NewRequest(http.MethodGet, "/startup", nil))
+
+		// act
+		MarkStarted()
+
+		after := httptest.NewRecorder()
+		startupHandler(after, httptest.NewRequest(http.MethodGet, "/startup", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, before.Code)
+		assert.Equal(t, "I'm starting up!\n", before.Body.String())
+		assert.Equal(t, http.StatusOK, after.Code)
+		assert.Equal(t, "I'm started!\n", after.Body.String())
+		assert.True(t, IsStarted())
+	})
+}
@@ -0,0 +1,119 @@
+package foundation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StartupTaskFunc initializes a part of the application, like running schema migrations, that has to succeed before it
+// receives traffic
+type StartupTaskFunc func(ctx context.Context) error
+
+// StartupTaskStatus is the progress of a startup task, which is pending, running, succeeded or failed
+type StartupTaskStatus struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+type startupTask struct {
+	task   StartupTaskFunc
+	status StartupTaskStatus
+}
+
+var (
+	startupTasksMutex sync.RWMutex
+	startupTasks      []*startupTask
+)
+
+// RegisterStartupTask registers a task to be run by RunStartupTasks; the /readiness endpoint returns 503 until all
+// registered tasks have succeeded
+// foundation.RegisterStartupTask("migrations", func(ctx context.Context) error { return migrate(ctx, db) })
+func RegisterStartupTask(name string, task StartupTaskFunc) {
+	startupTasksMutex.Lock()
+	defer startupTasksMutex.Unlock()
+
+	startupTasks = append(startupTasks, &startupTask{
+		task:   task,
+		status: StartupTaskStatus{Name: name, Status: "pending"},
+	})
+}
+
+// RunStartupTasks runs the registered tasks that haven't succeeded yet in order of registration; it stops at the first
+// failing task and returns its error, so later tasks can depend on earlier ones and the caller can retry or exit
+func RunStartupTasks(ctx context.Context) error {
+	startupTasksMutex.RLock()
+	tasks := append([]*startupTask{}, startupTasks...)
+	startupTasksMutex.RUnlock()
+
+	clock := currentClock()
+	for _, task := range tasks {
+		if task.statusCopy().Status == "succeeded" {
+			continue
+		}
+		task.setStatus("running", nil, 0)
+
+		start := clock.Now()
+		err := task.task(ctx)
+		duration := clock.Since(start)
+
+		if err != nil {
+			task.setStatus("failed", err, duration)
+			log.Warn().Err(err).Str("task", task.status.Name).Dur("duration", duration).Msgf("Startup task %v failed", task.status.Name)
+			return fmt.Errorf("Startup task %v failed: %w", task.status.Name, err)
+		}
+
+		task.setStatus("succeeded", nil, duration)
+		log.Info().Str("task", task.status.Name).Dur("duration", duration).Msgf("Startup task %v succeeded", task.status.Name)
+	}
+
+	return nil
+}
+
+// StartupTasksStatus returns the progress of all registered startup tasks in order of registration
+func StartupTasksStatus() []StartupTaskStatus {
+	startupTasksMutex.RLock()
+	defer startupTasksMutex.RUnlock()
+
+	statuses := make([]StartupTaskStatus, len(startupTasks))
+	for i, task := range startupTasks {
+		statuses[i] = task.status
+	}
+
+	return statuses
+}
+
+// startupTasksSucceeded returns true if all registered startup tasks have succeeded
+func startupTasksSucceeded(statuses []StartupTaskStatus) bool {
+	for _, status := range statuses {
+		if status.Status != "succeeded" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *startupTask) statusCopy() StartupTaskStatus {
+	startupTasksMutex.RLock()
+	defer startupTasksMutex.RUnlock()
+
+	return t.status
+}
+
+func (t *startupTask) setStatus(status string, err error, duration time.Duration) {
+	startupTasksMutex.Lock()
+	defer startupTasksMutex.Unlock()
+
+	t.status.Status = status
+	t.status.Error = ""
+	if err != nil {
+		t.status.Error = err.Error()
+	}
+	t.status.Duration = duration
+}
@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetStartupTasks removes all registered startup tasks for the duration of the test
+func resetStartupTasks(t *testing.T) {
+	startupTasksMutex.Lock()
+	original := startupTasks
+	startupTasks = nil
+	startupTasksMutex.Unlock()
+	t.Cleanup(func() {
+		startupTasksMutex.Lock()
+		startupTasks = original
+		startupTasksMutex.Unlock()
+	})
+}
+
+func TestRegisterStartupTask(t *testing.T) {
+	t.Run("MakesReadinessReturn503UntilTasksSucceed", func(t *testing.T) {
+		resetStartupTasks(t)
+		RegisterStartupTask("migrations", func(ctx context.Context) error { return nil })
+
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "I'm running startup tasks!\nmigrations: pending\n", recorder.Body.String())
+
+		// act
+		err := RunStartupTasks(context.Background())
+
+		assert.Nil(t, err)
+		recorder = httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("KeepsReadinessAt503WhileTaskFails", func(t *testing.T) {
+		resetStartupTasks(t)
+		RegisterStartupTask("migrations", func(ctx context.Context) error { return errors.New("Database unavailable") })
+
+		// act
+		err := RunStartupTasks(context.Background())
+
+		assert.NotNil(t, err)
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "I'm running startup tasks!\nmigrations: failed: Database unavailable\n", recorder.Body.String())
+	})
+
+	t.Run("StopsAtFailingTaskAndRetriesItOnNextRun", func(t *testing.T) {
+		resetStartupTasks(t)
+		attempts := 0
+		RegisterStartupTask("migrations", func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("Database unavailable")
+			}
+			return nil
+		})
+		cacheRuns := 0
+		RegisterStartupTask("cache", func(ctx context.Context) error { cacheRuns++; return nil })
+
+		// act
+		firstErr := RunStartupTasks(context.Background())
+		statuses := StartupTasksStatus()
+		secondErr := RunStartupTasks(context.Background())
+
+		assert.NotNil(t, firstErr)
+		assert.Equal(t, "failed", statuses[0].Status)
+		assert.Equal(t, "Database unavailable", statuses[0].Error)
+		assert.Equal(t, "pending", statuses[1].Status)
+		assert.Nil(t, secondErr)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, 1, cacheRuns)
+	})
+
+	t.Run("ShowsProgressInJSONBody", func(t *testing.T) {
+		resetStartupTasks(t)
+		RegisterStartupTask("migrations", func(ctx context.Context) error { return nil })
+		RegisterStartupTask("cache", func(ctx context.Context) error { return errors.New("Cache unavailable") })
+		RunStartupTasks(context.Background())
+		request := httptest.NewRequest(http.MethodGet, "/readiness", nil)
+		request.Header.Set("Accept", "application/json")
+
+		// act
+		recorder := httptest.NewRecorder()
+		readinessHandler(recorder, request)
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		var report readinessReport
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		assert.False(t, report.Ready)
+		assert.Equal(t, "succeeded", report.StartupTasks[0].Status)
+		assert.Equal(t, "failed", report.StartupTasks[1].Status)
+		assert.Equal(t, "Cache unavailable", report.StartupTasks[1].Error)
+	})
+}
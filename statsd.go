@@ -0,0 +1,251 @@
+package foundation
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsDConfig configures the agent metrics are mirrored to
+type StatsDConfig struct {
+	Prefix    string
+	DogStatsD bool
+	Tags      map[string]string
+}
+
+// StatsDOption allows to override statsd config
+type StatsDOption func(*StatsDConfig)
+
+// WithStatsDPrefix prefixes all metric names with prefix and a dot
+func WithStatsDPrefix(prefix string) StatsDOption {
+	return func(c *StatsDConfig) {
+		c.Prefix = prefix
+	}
+}
+
+// WithDogStatsD sends labels as DogStatsD tags, with the tags passed here added to every metric; plain StatsD has no
+// tags, so without this option label values are appended to the metric name
+func WithDogStatsD(tags map[string]string) StatsDOption {
+	return func(c *StatsDConfig) {
+		c.DogStatsD = true
+		c.Tags = tags
+	}
+}
+
+type statsDClient struct {
+	conn   net.Conn
+	config StatsDConfig
+}
+
+var (
+	statsDClientMutex sync.RWMutex
+	globalStatsD      *statsDClient
+)
+
+// InitStatsD mirrors the metrics created with NewMetricCounter, NewMetricGauge and NewMetricHistogram to the StatsD or
+// DogStatsD agent listening on udp address, like localhost:8125, besides exposing them to Prometheus
+func InitStatsD(address string, opts ...StatsDOption) error {
+	// default
+	config := StatsDConfig{}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return err
+	}
+
+	statsDClientMutex.Lock()
+	defer statsDClientMutex.Unlock()
+
+	if globalStatsD != nil {
+		globalStatsD.conn.Close()
+	}
+	globalStatsD = &statsDClient{
+		conn:   conn,
+		config: config,
+	}
+
+	return nil
+}
+
+// sendStatsD sends the value to the statsd agent if InitStatsD has been called; errors are ignored, like udp does
+func sendStatsD(name, metricType string, value float64, labelNames, labelValues []string) {
+	statsDClientMutex.RLock()
+	client := globalStatsD
+	statsDClientMutex.RUnlock()
+
+	if client == nil {
+		return
+	}
+
+	_, _ = client.conn.Write([]byte(client.format(name, metricType, value, labelNames, labelValues)))
+}
+
+// format returns the statsd line for the value, with the labels as tags for DogStatsD or in the name for StatsD
+func (c *statsDClient) format(name, metricType string, value float64, labelNames, labelValues []string) string {
+	if c.config.Prefix != "" {
+		name = c.config.Prefix + "." + name
+	}
+
+	if !c.config.DogStatsD {
+		// plain statsd has timers in milliseconds instead of histograms, which observe seconds by prometheus convention
+		if metricType == "h" {
+			metricType = "ms"
+			value *= 1000
+		}
+		for _, labelValue := range labelValues {
+			name += "." + sanitizeStatsDName(labelValue)
+		}
+		return fmt.Sprintf("%v:%v|%v", name, strconv.FormatFloat(value, 'f', -1, 64), metricType)
+	}
+
+	tags := []string{}
+	for i, labelName := range labelNames {
+		if i < len(labelValues) {
+			tags = append(tags, sanitizeDogStatsDTag(labelName)+":"+sanitizeDogStatsDTag(labelValues[i]))
+		}
+	}
+	keys := make([]string, 0, len(c.config.Tags))
+	for key := range c.config.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tags = append(tags, sanitizeDogStatsDTag(key)+":"+sanitizeDogStatsDTag(c.config.Tags[key]))
+	}
+
+	line := fmt.Sprintf("%v:%v|%v", name, strconv.FormatFloat(value, 'f', -1, 64), metricType)
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+
+	return line
+}
+
+// sanitizeStatsDName replaces the characters with a special meaning in the statsd protocol or graphite paths
+func sanitizeStatsDName(value string) string {
+	return strings.NewReplacer(".", "_", ":", "_", "|", "_", "@", "_", " ", "_").Replace(value)
+}
+
+// sanitizeDogStatsDTag replaces the characters separating tags, tag keys and values and the parts of a dogstatsd line
+func sanitizeDogStatsDTag(value string) string {
+	return strings.NewReplacer(",", "_", "|", "_", ":", "_").Replace(value)
+}
+
+var (
+	mirroredMetricsMutex       sync.Mutex
+	mirroredMetrics            []prometheus.Collector
+	mirroredMetricsRegisterers []prometheus.Registerer
+)
+
+// registerMirroredMetric registers a metric created with NewMetricCounter, NewMetricGauge or NewMetricHistogram with the
+// registerers InitMetrics has been called with so far, and keeps it for the ones it gets called with later
+func registerMirroredMetric(collector prometheus.Collector) prometheus.Collector {
+	mirroredMetricsMutex.Lock()
+	defer mirroredMetricsMutex.Unlock()
+
+	for _, registerer := range mirroredMetricsRegisterers {
+		registerCollector(registerer, collector)
+	}
+	mirroredMetrics = append(mirroredMetrics, collector)
+
+	return collector
+}
+
+// registerMirroredMetrics exposes the metrics created with NewMetricCounter, NewMetricGauge and NewMetricHistogram,
+// including the ones created later, since they're usually package level variables created before InitMetrics is called
+func registerMirroredMetrics(registerer prometheus.Registerer) {
+	mirroredMetricsMutex.Lock()
+	defer mirroredMetricsMutex.Unlock()
+
+	for _, r := range mirroredMetricsRegisterers {
+		if r == registerer {
+			return
+		}
+	}
+	mirroredMetricsRegisterers = append(mirroredMetricsRegisterers, registerer)
+
+	for _, collector := range mirroredMetrics {
+		registerCollector(registerer, collector)
+	}
+}
+
+// MetricCounter is a Prometheus counter that is mirrored to StatsD if InitStatsD has been called
+type MetricCounter struct {
+	name       string
+	labelNames []string
+	vec        *prometheus.CounterVec
+}
+
+// NewMetricCounter registers a counter with the Prometheus registry of InitMetrics and mirrors it to StatsD
+func NewMetricCounter(opts prometheus.CounterOpts, labelNames ...string) *MetricCounter {
+	return &MetricCounter{
+		name:       prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		labelNames: labelNames,
+		vec:        registerMirroredMetric(prometheus.NewCounterVec(opts, labelNames)).(*prometheus.CounterVec),
+	}
+}
+
+// Inc increments the counter for the label values by 1
+func (c *MetricCounter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the label values by value
+func (c *MetricCounter) Add(value float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(value)
+	sendStatsD(c.name, "c", value, c.labelNames, labelValues)
+}
+
+// MetricGauge is a Prometheus gauge that is mirrored to StatsD if InitStatsD has been called
+type MetricGauge struct {
+	name       string
+	labelNames []string
+	vec        *prometheus.GaugeVec
+}
+
+// NewMetricGauge registers a gauge with the Prometheus registry of InitMetrics and mirrors it to StatsD
+func NewMetricGauge(opts prometheus.GaugeOpts, labelNames ...string) *MetricGauge {
+	return &MetricGauge{
+		name:       prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		labelNames: labelNames,
+		vec:        registerMirroredMetric(prometheus.NewGaugeVec(opts, labelNames)).(*prometheus.GaugeVec),
+	}
+}
+
+// Set sets the gauge for the label values to value
+func (g *MetricGauge) Set(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(value)
+	sendStatsD(g.name, "g", value, g.labelNames, labelValues)
+}
+
+// MetricHistogram is a Prometheus histogram that is mirrored to StatsD if InitStatsD has been called
+type MetricHistogram struct {
+	name       string
+	labelNames []string
+	vec        *prometheus.HistogramVec
+}
+
+// NewMetricHistogram registers a histogram with the Prometheus registry of InitMetrics and mirrors it to StatsD
+func NewMetricHistogram(opts prometheus.HistogramOpts, labelNames ...string) *MetricHistogram {
+	return &MetricHistogram{
+		name:       prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		labelNames: labelNames,
+		vec:        registerMirroredMetric(prometheus.NewHistogramVec(opts, labelNames)).(*prometheus.HistogramVec),
+	}
+}
+
+// Observe adds the value to the histogram for the label values
+func (h *MetricHistogram) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+	sendStatsD(h.name, "h", value, h.labelNames, labelValues)
+}
@@ -0,0 +1,68 @@
+package foundation
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDClientFormat(t *testing.T) {
+	t.Run("AppendsLabelValuesToNameForStatsD", func(t *testing.T) {
+		client := &statsDClient{config: StatsDConfig{Prefix: "myapp"}}
+
+		// act
+		line := client.format("request_duration_seconds", "h", 0.25, []string{"route"}, []string{"/api/orders"})
+
+		assert.Equal(t, "myapp.request_duration_seconds./api/orders:250|ms", line)
+	})
+
+	t.Run("SendsLabelsAndTagsAsDogStatsDTags", func(t *testing.T) {
+		client := &statsDClient{config: StatsDConfig{DogStatsD: true, Tags: map[string]string{"env": "prod", "app": "myapp"}}}
+
+		// act
+		line := client.format("orders_total", "c", 1, []string{"status"}, []string{"paid"})
+
+		assert.Equal(t, "orders_total:1|c|#status:paid,app:myapp,env:prod", line)
+	})
+
+	t.Run("ReplacesSeparatorsInDogStatsDTags", func(t *testing.T) {
+		client := &statsDClient{config: StatsDConfig{DogStatsD: true, Tags: map[string]string{"version": "1.0|beta"}}}
+
+		// act
+		line := client.format("orders_total", "c", 1, []string{"route"}, []string{"/api/orders:search,all"})
+
+		assert.Equal(t, "orders_total:1|c|#route:/api/orders_search_all,version:1.0_beta", line)
+	})
+}
+
+func TestMetricCounter(t *testing.T) {
+	t.Run("IncrementsPrometheusCounterAndMirrorsToStatsD", func(t *testing.T) {
+		agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer agent.Close()
+		assert.Nil(t, InitStatsD(agent.LocalAddr().String(), WithDogStatsD(nil)))
+		defer func() {
+			statsDClientMutex.Lock()
+			globalStatsD.conn.Close()
+			globalStatsD = nil
+			statsDClientMutex.Unlock()
+		}()
+		counter := NewMetricCounter(prometheus.CounterOpts{Name: "statsd_test_orders_total", Help: "Orders."}, "status")
+
+		// act
+		counter.Inc("paid")
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(counter.vec.WithLabelValues("paid")))
+		packet := make([]byte, 1024)
+		agent.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := agent.ReadFrom(packet)
+		assert.Nil(t, err)
+		assert.Equal(t, "statsd_test_orders_total:1|c|#status:paid", string(packet[:n]))
+	})
+}
@@ -0,0 +1,177 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeoutConfig is used to configure the Timeout middleware
+type TimeoutConfig struct {
+	Timeout       time.Duration
+	RouteTimeouts map[string]time.Duration
+	StatusCode    int
+}
+
+// TimeoutOption allows to override config
+type TimeoutOption func(*TimeoutConfig)
+
+// WithRouteTimeout sets a different timeout for requests with a path starting with pathPrefix; the longest matching prefix wins
+func WithRouteTimeout(pathPrefix string, timeout time.Duration) TimeoutOption {
+	return func(c *TimeoutConfig) {
+		c.RouteTimeouts[pathPrefix] = timeout
+	}
+}
+
+// WithTimeoutStatusCode sets the status code returned when a request times out
+// default is 503
+func WithTimeoutStatusCode(statusCode int) TimeoutOption {
+	return func(c *TimeoutConfig) {
+		c.StatusCode = statusCode
+	}
+}
+
+// Timeout returns a middleware that sets a deadline on the request context and responds with a 503 status code if the
+// wrapped handler doesn't finish in time; the response of the handler is buffered, so it doesn't suit streaming responses
+func Timeout(timeout time.Duration, opts ...TimeoutOption) Middleware {
+
+	// default
+	config := &TimeoutConfig{
+		Timeout:       timeout,
+		RouteTimeouts: map[string]time.Duration{},
+		StatusCode:    http.StatusServiceUnavailable,
+	}
+
+	// apply options to override config defaults
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), config.timeoutForPath(r.URL.Path))
+			defer cancel()
+
+			// keep the request in-flight until the handler returns, so shutdown draining waits for it after a timeout as well
+			tracker := inFlightTrackerFromContext(r.Context())
+			if tracker != nil {
+				tracker.begin()
+			}
+
+			tw := &timeoutWriter{header: http.Header{}, status: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+
+			go func() {
+				defer func() {
+					if tracker != nil {
+						tracker.end()
+					}
+				}()
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+
+			case <-done:
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+
+				for key, values := range tw.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+
+			case <-ctx.Done():
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+
+				tw.timedOut = true
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.WriteHeader(config.StatusCode)
+				io.WriteString(w, http.StatusText(config.StatusCode)+"\n")
+			}
+		})
+	}
+}
+
+func (c *TimeoutConfig) timeoutForPath(path string) time.Duration {
+	timeout := c.Timeout
+	longestPrefix := -1
+	for prefix, routeTimeout := range c.RouteTimeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longestPrefix {
+			timeout = routeTimeout
+			longestPrefix = len(prefix)
+		}
+	}
+	return timeout
+}
+
+// timeoutWriter buffers the response until the handler is done; writes after the timeout return http.ErrHandlerTimeout
+type timeoutWriter struct {
+	mutex       sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = status
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+// inFlightTracker allows middlewares to extend the time a request is considered in-flight
+type inFlightTracker interface {
+	begin()
+	end()
+}
+
+type inFlightTrackerContextKey struct{}
+
+func contextWithInFlightTracker(ctx context.Context, tracker inFlightTracker) context.Context {
+	return context.WithValue(ctx, inFlightTrackerContextKey{}, tracker)
+}
+
+func inFlightTrackerFromContext(ctx context.Context) inFlightTracker {
+	if tracker, ok := ctx.Value(inFlightTrackerContextKey{}).(inFlightTracker); ok {
+		return tracker
+	}
+	return nil
+}
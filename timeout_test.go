@@ -0,0 +1,88 @@
+package foundation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+
+	t.Run("Returns503IfHandlerExceedsTimeout", func(t *testing.T) {
+
+		handler := Timeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			io.WriteString(w, "too late")
+		}))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "Service Unavailable\n", recorder.Body.String())
+	})
+
+	t.Run("ReturnsResponseIfHandlerFinishesInTime", func(t *testing.T) {
+
+		handler := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Test", "value")
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, "in time")
+		}))
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "value", recorder.Header().Get("X-Test"))
+		assert.Equal(t, "in time", recorder.Body.String())
+	})
+
+	t.Run("UsesRouteTimeoutAndStatusCode", func(t *testing.T) {
+
+		var deadline time.Time
+		handler := Timeout(time.Minute, WithRouteTimeout("/api/slow", time.Hour), WithTimeoutStatusCode(http.StatusGatewayTimeout))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, _ = r.Context().Deadline()
+		}))
+
+		// act
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/slow/items", nil))
+
+		assert.True(t, time.Until(deadline) > 59*time.Minute)
+	})
+
+	t.Run("KeepsRequestInFlightUntilHandlerReturns", func(t *testing.T) {
+
+		handlerDone := make(chan struct{})
+		server := NewHTTPServer(WithPort(5013), WithMiddleware(Timeout(20*time.Millisecond)), WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(handlerDone)
+			time.Sleep(200 * time.Millisecond)
+		})))
+		err := server.Start(context.Background())
+		assert.Nil(t, err)
+
+		resp, err := http.Get("http://localhost:5013/")
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		}
+		assert.Equal(t, int64(1), server.InFlightRequests())
+
+		// act
+		err = server.Stop(context.Background())
+
+		assert.Nil(t, err)
+		select {
+		case <-handlerDone:
+		default:
+			t.Error("Stop returned before the timed out handler finished")
+		}
+	})
+}
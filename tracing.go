@@ -1,8 +1,11 @@
 package foundation
 
 import (
+	"context"
 	"io"
+	"net/http"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/rs/zerolog/log"
 	"github.com/uber/jaeger-client-go"
 	jaegercfg "github.com/uber/jaeger-client-go/config"
@@ -17,6 +20,9 @@ func InitTracingFromEnv(app string) io.Closer {
 		log.Fatal().Err(err).Msg("Generating Jaeger config from environment variables failed")
 	}
 
+	// add kubernetes runtime information as tracer tags
+	cfg.Tags = append(cfg.Tags, runtimeInfoTracerTags(NewRuntimeInfoFromEnv())...)
+
 	closer, err := cfg.InitGlobalTracer(app, jaegercfg.Logger(jaeger.StdLogger))
 	if err != nil {
 		log.Fatal().Err(err).Msg("Generating Jaeger tracer failed")
@@ -24,3 +30,64 @@ func InitTracingFromEnv(app string) io.Closer {
 
 	return closer
 }
+
+// runtimeInfoTracerTags returns the non-empty kubernetes runtime information as tags following the opentelemetry naming conventions
+func runtimeInfoTracerTags(runtimeInfo RuntimeInfo) (tags []opentracing.Tag) {
+	for _, tag := range []opentracing.Tag{
+		{Key: "k8s.pod.name", Value: runtimeInfo.PodName},
+		{Key: "k8s.namespace.name", Value: runtimeInfo.Namespace},
+		{Key: "k8s.node.name", Value: runtimeInfo.NodeName},
+		{Key: "k8s.container.name", Value: runtimeInfo.ContainerName},
+	} {
+		if tag.Value != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return
+}
+
+// traceIDFromRequest returns the jaeger trace id of the active span in the request context or else the one propagated in the request headers
+func traceIDFromRequest(r *http.Request) string {
+	if traceID := traceIDFromContext(r.Context()); traceID != "" {
+		return traceID
+	}
+
+	spanContext, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+	if err != nil {
+		return ""
+	}
+
+	return traceIDFromSpanContext(spanContext)
+}
+
+// traceIDFromContext returns the jaeger trace id of the active span in the context
+func traceIDFromContext(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+
+	return traceIDFromSpanContext(span.Context())
+}
+
+func traceIDFromSpanContext(spanContext opentracing.SpanContext) string {
+	if jaegerSpanContext, ok := spanContext.(jaeger.SpanContext); ok && jaegerSpanContext.IsValid() {
+		return jaegerSpanContext.TraceID().String()
+	}
+
+	return ""
+}
+
+// spanIDFromContext returns the jaeger span id of the active span in the context
+func spanIDFromContext(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+
+	if jaegerSpanContext, ok := span.Context().(jaeger.SpanContext); ok && jaegerSpanContext.IsValid() {
+		return jaegerSpanContext.SpanID().String()
+	}
+
+	return ""
+}
@@ -0,0 +1,150 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultV3MessageType        = "estafette"
+	defaultV3MessageTypeVersion = "0.0.0"
+)
+
+// V3Config configures the envelope of the v3 log format
+type V3Config struct {
+	MessageType        string
+	MessageTypeVersion string
+
+	// PayloadMarshaler converts the payload passed to V3Payload to json
+	PayloadMarshaler func(payload interface{}) ([]byte, error)
+}
+
+// WithV3MessageType sets the messagetype and messagetypeversion fields of the v3 log format, so a logger created with
+// NewLogger can emit domain events in the v3 envelope instead of generic log lines
+func WithV3MessageType(messageType, messageTypeVersion string) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.V3.MessageType = messageType
+		c.V3.MessageTypeVersion = messageTypeVersion
+	}
+}
+
+// WithV3PayloadMarshaler sets the function V3Payload uses to convert payloads to json, for payloads that need a
+// specific schema like protobuf messages; by default payloads are marshaled with encoding/json
+func WithV3PayloadMarshaler(marshaler func(payload interface{}) ([]byte, error)) LoggingOption {
+	return func(c *LoggingConfig) {
+		c.V3.PayloadMarshaler = marshaler
+	}
+}
+
+// v3PayloadPlaceholderKey marks the payload added by V3Payload, which the v3PayloadWriter of the logger replaces with
+// the payload converted by its marshaler
+const v3PayloadPlaceholderKey = "$v3payload"
+
+var (
+	v3PayloadsMutex sync.Mutex
+	v3Payloads      = map[uint64]interface{}{}
+	v3PayloadID     uint64
+)
+
+// V3Payload adds the payload to the event as the nested payload of the v3 log format, converted with the marshaler
+// set with WithV3PayloadMarshaler for the logger the event is written by; use it with loggers created by this package
+// foundation.V3Payload(logger.Info(), orderCreated).Msg("Order created")
+func V3Payload(e *zerolog.Event, payload interface{}) *zerolog.Event {
+	if e == nil {
+		return e
+	}
+
+	id := atomic.AddUint64(&v3PayloadID, 1)
+
+	v3PayloadsMutex.Lock()
+	v3Payloads[id] = payload
+	v3PayloadsMutex.Unlock()
+
+	return e.RawJSON("payload", []byte(fmt.Sprintf(`{"%v":%v}`, v3PayloadPlaceholderKey, id)))
+}
+
+// takeV3Payload returns and forgets the payload added by V3Payload with id
+func takeV3Payload(id uint64) (payload interface{}, ok bool) {
+	v3PayloadsMutex.Lock()
+	defer v3PayloadsMutex.Unlock()
+
+	payload, ok = v3Payloads[id]
+	delete(v3Payloads, id)
+
+	return payload, ok
+}
+
+// v3PayloadWriter converts the payloads added by V3Payload with the marshaler of the logger it's the output of, so
+// loggers with different marshalers don't affect each other
+type v3PayloadWriter struct {
+	writer    io.Writer
+	marshaler func(payload interface{}) ([]byte, error)
+}
+
+func newV3PayloadWriter(writer io.Writer, marshaler func(payload interface{}) ([]byte, error)) zerolog.LevelWriter {
+	if marshaler == nil {
+		marshaler = json.Marshal
+	}
+
+	return &v3PayloadWriter{writer: writer, marshaler: marshaler}
+}
+
+func (w *v3PayloadWriter) Write(p []byte) (n int, err error) {
+	if _, err = w.writer.Write(w.marshalPayload(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *v3PayloadWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if _, err = writeLevel(w.writer, level, w.marshalPayload(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// marshalPayload replaces the payload placeholder in a json log event with the converted payload, or with a
+// payloadError field if converting it fails
+func (w *v3PayloadWriter) marshalPayload(p []byte) []byte {
+	placeholder := []byte(`"payload":{"` + v3PayloadPlaceholderKey + `":`)
+	start := bytes.Index(p, placeholder)
+	if start < 0 {
+		return p
+	}
+	length := bytes.IndexByte(p[start+len(placeholder):], '}')
+	if length < 0 {
+		return p
+	}
+	end := start + len(placeholder) + length + 1
+
+	id, err := strconv.ParseUint(string(p[start+len(placeholder):end-1]), 10, 64)
+	if err != nil {
+		return p
+	}
+	payload, ok := takeV3Payload(id)
+	if !ok {
+		return p
+	}
+
+	var field []byte
+	if data, err := w.marshaler(payload); err != nil {
+		field = append([]byte(`"payloadError":`), strconv.Quote(err.Error())...)
+	} else {
+		field = append([]byte(`"payload":`), data...)
+	}
+
+	replaced := make([]byte, 0, len(p)+len(field))
+	replaced = append(replaced, p[:start]...)
+	replaced = append(replaced, field...)
+
+	return append(replaced, p[end:]...)
+}
@@ -0,0 +1,91 @@
+package foundation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLoggerV3(t *testing.T) {
+	t.Run("WritesDefaultMessageType", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatV3, WithLogWriter(&buffer))
+
+		// act
+		logger.Info().Msg("Hello")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "estafette", event["messagetype"])
+			assert.Equal(t, "0.0.0", event["messagetypeversion"])
+		}
+	})
+
+	t.Run("WritesCustomMessageTypeAndMarshaledPayload", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer bytes.Buffer
+		marshaler := func(payload interface{}) ([]byte, error) {
+			return []byte(`{"orderId":"` + strings.ToUpper(payload.(string)) + `"}`), nil
+		}
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatV3, WithLogWriter(&buffer), WithV3MessageType("order-created", "1.2.0"), WithV3PayloadMarshaler(marshaler))
+
+		// act
+		V3Payload(logger.Info(), "abc").Msg("Order created")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, "order-created", event["messagetype"])
+			assert.Equal(t, "1.2.0", event["messagetypeversion"])
+			assert.Equal(t, map[string]interface{}{"orderId": "ABC"}, event["payload"])
+		}
+	})
+}
+
+func TestV3Payload(t *testing.T) {
+	t.Run("MarshalsPayloadAsJSONByDefault", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatLogfmt, WithLogWriter(&buffer))
+
+		// act
+		V3Payload(logger.Info(), map[string]int{"items": 2}).Msg("Order created")
+
+		assert.Contains(t, buffer.String(), "payload.items=2")
+	})
+	t.Run("UsesMarshalerOfLoggerTheEventIsWrittenBy", func(t *testing.T) {
+		restoreZerologGlobals(t)
+		var buffer, otherBuffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatV3, WithLogWriter(&buffer))
+		NewLogger(ApplicationInfo{App: "myapp"}, LogFormatV3, WithLogWriter(&otherBuffer), WithV3PayloadMarshaler(func(payload interface{}) ([]byte, error) {
+			return []byte(`"other"`), nil
+		}))
+
+		// act
+		V3Payload(logger.Info(), map[string]int{"items": 2}).Msg("Order created")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Equal(t, map[string]interface{}{"items": float64(2)}, event["payload"])
+		}
+	})
+
+	t.Run("LogsPayloadErrorIfMarshalingFails", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := NewLogger(ApplicationInfo{App: "myapp"}, LogFormatJSON, WithLogWriter(&buffer), WithV3PayloadMarshaler(func(payload interface{}) ([]byte, error) {
+			return nil, errors.New("Unsupported payload")
+		}))
+
+		// act
+		V3Payload(logger.Info(), "abc").Msg("Order created")
+
+		var event map[string]interface{}
+		if assert.Nil(t, json.Unmarshal(buffer.Bytes(), &event)) {
+			assert.Nil(t, event["payload"])
+			assert.Equal(t, "Unsupported payload", event["payloadError"])
+		}
+	})
+}
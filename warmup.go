@@ -0,0 +1,106 @@
+package foundation
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// WarmupFunc primes a part of the application, like a cache, before it receives traffic
+type WarmupFunc func(ctx context.Context) error
+
+type warmupStep struct {
+	name     string
+	warmup   WarmupFunc
+	finished bool
+}
+
+var (
+	warmupMutex sync.RWMutex
+	warmupSteps []*warmupStep
+
+	warmupStepDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "warmup_step_duration_seconds",
+		Help: "Duration of each warmup step in seconds.",
+	}, []string{"step", "status"})
+	warmupCompleted = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "warmup_completed",
+		Help: "Whether all registered warmup steps have finished, 1 if so, 0 otherwise.",
+	})
+)
+
+// registerWarmupMetrics exposes warmup_step_duration_seconds and warmup_completed
+func registerWarmupMetrics(registerer prometheus.Registerer) {
+	registerCollector(registerer, warmupStepDurationSeconds)
+	registerCollector(registerer, warmupCompleted)
+}
+
+// RegisterWarmup registers a warmup step to be run by RunWarmup; as long as any registered step hasn't finished the
+// /readiness endpoint reports the application isn't ready
+func RegisterWarmup(name string, warmup WarmupFunc) {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+
+	warmupSteps = append(warmupSteps, &warmupStep{name: name, warmup: warmup})
+}
+
+// RunWarmup runs all registered warmup steps that haven't run yet in order of registration, logging and exposing the
+// duration of each step as metric; a failing step is logged but doesn't prevent the application from becoming ready
+func RunWarmup(ctx context.Context) {
+	warmupMutex.RLock()
+	steps := append([]*warmupStep{}, warmupSteps...)
+	warmupMutex.RUnlock()
+
+	clock := currentClock()
+	start := clock.Now()
+	for _, step := range steps {
+		if isWarmupStepFinished(step) {
+			continue
+		}
+
+		stepStart := clock.Now()
+		err := step.warmup(ctx)
+		duration := clock.Since(stepStart)
+
+		if err != nil {
+			warmupStepDurationSeconds.WithLabelValues(step.name, "failed").Set(duration.Seconds())
+			log.Warn().Err(err).Str("step", step.name).Dur("duration", duration).Msgf("Warmup step %v failed", step.name)
+		} else {
+			warmupStepDurationSeconds.WithLabelValues(step.name, "succeeded").Set(duration.Seconds())
+			log.Info().Str("step", step.name).Dur("duration", duration).Msgf("Warmup step %v finished", step.name)
+		}
+
+		warmupMutex.Lock()
+		step.finished = true
+		warmupMutex.Unlock()
+	}
+
+	if IsWarmedUp() {
+		warmupCompleted.Set(1)
+	}
+	log.Info().Int("steps", len(steps)).Dur("duration", clock.Since(start)).Msg("Warmup finished")
+	PublishLifecycleEvent(EventWarmupFinished, map[string]string{"steps": strconv.Itoa(len(steps))})
+}
+
+// IsWarmedUp returns true if all registered warmup steps have finished
+func IsWarmedUp() bool {
+	warmupMutex.RLock()
+	defer warmupMutex.RUnlock()
+
+	for _, step := range warmupSteps {
+		if !step.finished {
+			return false
+		}
+	}
+	return true
+}
+
+func isWarmupStepFinished(step *warmupStep) bool {
+	warmupMutex.RLock()
+	defer warmupMutex.RUnlock()
+
+	return step.finished
+}
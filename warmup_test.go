@@ -0,0 +1,118 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetWarmup(t *testing.T) {
+	warmupMutex.Lock()
+	warmupSteps = nil
+	warmupMutex.Unlock()
+
+	t.Cleanup(func() {
+		warmupMutex.Lock()
+		warmupSteps = nil
+		warmupMutex.Unlock()
+	})
+}
+
+func TestRunWarmup(t *testing.T) {
+	t.Run("RunsRegisteredStepsInOrder", func(t *testing.T) {
+		resetWarmup(t)
+		order := []string{}
+		RegisterWarmup("cache", func(ctx context.Context) error {
+			order = append(order, "cache")
+			return nil
+		})
+		RegisterWarmup("routes", func(ctx context.Context) error {
+			order = append(order, "routes")
+			return nil
+		})
+
+		// act
+		RunWarmup(context.Background())
+
+		assert.Equal(t, []string{"cache", "routes"}, order)
+		assert.True(t, IsWarmedUp())
+	})
+
+	t.Run("RunsEachStepOnlyOnce", func(t *testing.T) {
+		resetWarmup(t)
+		count := 0
+		RegisterWarmup("cache", func(ctx context.Context) error {
+			count++
+			return nil
+		})
+		RunWarmup(context.Background())
+
+		// act
+		RunWarmup(context.Background())
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("FinishesWhenStepFails", func(t *testing.T) {
+		resetWarmup(t)
+		RegisterWarmup("cache", func(ctx context.Context) error {
+			return errors.New("cache unavailable")
+		})
+
+		// act
+		RunWarmup(context.Background())
+
+		assert.True(t, IsWarmedUp())
+	})
+}
+
+func TestIsWarmedUp(t *testing.T) {
+	t.Run("ReturnsTrueWithoutRegisteredSteps", func(t *testing.T) {
+		resetWarmup(t)
+
+		// act
+		warmedUp := IsWarmedUp()
+
+		assert.True(t, warmedUp)
+	})
+
+	t.Run("ReturnsFalseBeforeRunWarmup", func(t *testing.T) {
+		resetWarmup(t)
+		RegisterWarmup("cache", func(ctx context.Context) error { return nil })
+
+		// act
+		warmedUp := IsWarmedUp()
+
+		assert.False(t, warmedUp)
+	})
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Run("Returns503WhileWarmingUp", func(t *testing.T) {
+		resetWarmup(t)
+		RegisterWarmup("cache", func(ctx context.Context) error { return nil })
+		recorder := httptest.NewRecorder()
+
+		// act
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+
+	t.Run("Returns200AfterWarmup", func(t *testing.T) {
+		resetWarmup(t)
+		RegisterWarmup("cache", func(ctx context.Context) error { return nil })
+		RunWarmup(context.Background())
+		recorder := httptest.NewRecorder()
+
+		// act
+		readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "I'm ready!\n", recorder.Body.String())
+	})
+}
@@ -0,0 +1,86 @@
+package foundation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	watchdogsMutex sync.RWMutex
+	watchdogs      []*Watchdog
+)
+
+// Watchdog detects a worker loop that stopped making progress, for example because it deadlocked; as long as any
+// watchdog isn't kicked within its deadline the /liveness endpoint returns 503, so Kubernetes restarts the pod
+type Watchdog struct {
+	name     string
+	deadline time.Duration
+
+	mutex    sync.Mutex
+	lastKick time.Time
+}
+
+// NewWatchdog registers a watchdog that has to be kicked at least once every deadline, starting now; call Stop once the
+// loop finishes on purpose
+// watchdog := foundation.NewWatchdog("queue-consumer", time.Minute)
+func NewWatchdog(name string, deadline time.Duration) *Watchdog {
+	watchdog := &Watchdog{
+		name:     name,
+		deadline: deadline,
+		lastKick: currentClock().Now(),
+	}
+
+	watchdogsMutex.Lock()
+	defer watchdogsMutex.Unlock()
+	watchdogs = append(watchdogs, watchdog)
+
+	return watchdog
+}
+
+// Kick signals the loop is still making progress
+func (w *Watchdog) Kick() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.lastKick = currentClock().Now()
+}
+
+// Stop unregisters the watchdog, for a loop that finished on purpose
+func (w *Watchdog) Stop() {
+	watchdogsMutex.Lock()
+	defer watchdogsMutex.Unlock()
+
+	for i, watchdog := range watchdogs {
+		if watchdog == w {
+			watchdogs = append(watchdogs[:i], watchdogs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Err returns an error if the watchdog hasn't been kicked within its deadline
+func (w *Watchdog) Err() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if sinceKick := currentClock().Since(w.lastKick); sinceKick > w.deadline {
+		return fmt.Errorf("Watchdog %v hasn't been kicked for %v", w.name, sinceKick.Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// expiredWatchdogs returns the errors of all registered watchdogs that haven't been kicked within their deadline
+func expiredWatchdogs() (errs []error) {
+	watchdogsMutex.RLock()
+	defer watchdogsMutex.RUnlock()
+
+	for _, watchdog := range watchdogs {
+		if err := watchdog.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return
+}
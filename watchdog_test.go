@@ -0,0 +1,58 @@
+package foundation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Run("MakesLivenessReturn503OnceDeadlineIsMissed", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		watchdog := NewWatchdog("consumer", time.Minute)
+		defer watchdog.Stop()
+
+		// act
+		clock.Advance(2 * time.Minute)
+
+		recorder := httptest.NewRecorder()
+		livenessHandler(recorder, httptest.NewRequest(http.MethodGet, "/liveness", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "Watchdog consumer hasn't been kicked for 2m0s")
+	})
+
+	t.Run("KeepsLivenessHealthyWhileKicked", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		watchdog := NewWatchdog("consumer", time.Minute)
+		defer watchdog.Stop()
+
+		// act
+		clock.Advance(50 * time.Second)
+		watchdog.Kick()
+		clock.Advance(50 * time.Second)
+
+		recorder := httptest.NewRecorder()
+		livenessHandler(recorder, httptest.NewRequest(http.MethodGet, "/liveness", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("IsIgnoredOnceStopped", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		SetClock(clock)
+		defer SetClock(nil)
+		watchdog := NewWatchdog("consumer", time.Minute)
+
+		// act
+		watchdog.Stop()
+
+		clock.Advance(2 * time.Minute)
+		assert.Empty(t, expiredWatchdogs())
+	})
+}